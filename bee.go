@@ -83,6 +83,9 @@ var commands = []*Command{
 	cmdBale,
 	cmdVersion,
 	cmdGenerate,
+	cmdMock,
+	cmdContractTest,
+	cmdDocs,
 	//cmdRundocs,
 	cmdMigrate,
 	cmdFix,
@@ -18,7 +18,9 @@ import (
 	"encoding/json"
 	"io/ioutil"
 	"os"
+	"reflect"
 
+	"github.com/zalora/bee/docgen"
 	"gopkg.in/yaml.v3"
 )
 
@@ -42,6 +44,17 @@ var defaultConf = `{
 	"envs": [],
 	"database": {
 		"driver": "mysql"
+	},
+	"docs": {
+		"openapi3": false
+	},
+	"postman": {
+		"prefix": "DOR",
+		"headers": [
+			{"key": "Accept", "value": "application/json"},
+			{"key": "Content-Language", "value": "{{CONTENT_LANGUAGE}}"},
+			{"key": "User-Agent", "value": "{{USER_AGENT}}"}
+		]
 	}
 }
 `
@@ -72,6 +85,37 @@ var conf struct {
 		Driver string
 		Conn   string
 	}
+	Docs struct {
+		// Also write swagger/openapi.json and swagger/openapi.yml.
+		OpenAPI3 bool `json:"openapi3" yaml:"openapi3"`
+		// Also write swagger/swagger-public.json without the operations
+		// marked with `@Extension x-internal true`.
+		Public bool `json:"public" yaml:"public"`
+		// Also write swagger/api.ts with the TypeScript types and fetch
+		// wrappers of the operations.
+		TypeScript bool `json:"typescript" yaml:"typescript"`
+		// Implementations of interface definitions, keyed by the
+		// definition name (e.g. models.Widget).
+		OneOf map[string]docgen.OneOf `json:"one_of" yaml:"one_of"`
+		// Add cURL, Go and JavaScript snippets of the operations as
+		// x-codeSamples, calling the first Postman environment.
+		CodeSamples bool `json:"code_samples" yaml:"code_samples"`
+	}
+	// Collection written by bee generate postman.
+	Postman postmanOptions
+	Run     struct {
+		// Signal asking the app to exit before a restart: SIGTERM (the
+		// default) or SIGINT.
+		ShutdownSignal string `json:"shutdown_signal" yaml:"shutdown_signal"`
+		// Time the app has to exit before its process group is killed,
+		// e.g. 10s. Defaults to 5s.
+		GracePeriod string `json:"grace_period" yaml:"grace_period"`
+		// Readiness check run after each restart.
+		Health healthCheck
+	}
+	// Processes run by bee run instead of the app, like those of a
+	// Procfile.
+	Processes []processConfig
 }
 
 // loadConfig loads customized configuration.
@@ -104,6 +148,15 @@ func loadConfig() error {
 			return err
 		}
 	}
+	// Without a postman section, keep the variables and headers of the
+	// collections generated before it was configurable.
+	if reflect.DeepEqual(conf.Postman, postmanOptions{}) {
+		var defaults struct{ Postman postmanOptions }
+		if err := json.Unmarshal([]byte(defaultConf), &defaults); err != nil {
+			return err
+		}
+		conf.Postman = defaults.Postman
+	}
 	// Check format version.
 	if conf.Version != ConfVer {
 		ColorLog("[WARN] Your bee.json is out-of-date, please update!\n")
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadConfigPostmanDefaults(t *testing.T) {
+	saved := conf
+	defer func() { conf = saved }()
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	defer os.Chdir(wd)
+
+	tests := []struct {
+		desc           string
+		beefile        string
+		expectedPrefix string
+		expectedKeys   []string
+	}{
+		{
+			desc:           "no config",
+			expectedPrefix: "DOR",
+			expectedKeys:   []string{"Accept", "Content-Language", "User-Agent"},
+		},
+		{
+			desc:           "no postman section",
+			beefile:        "version: 0\n",
+			expectedPrefix: "DOR",
+			expectedKeys:   []string{"Accept", "Content-Language", "User-Agent"},
+		},
+		{
+			desc:           "postman section",
+			beefile:        "postman:\n  prefix: API\n  headers:\n    - key: Accept\n      value: application/json\n",
+			expectedPrefix: "API",
+			expectedKeys:   []string{"Accept"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			dir := t.TempDir()
+			require.NoError(t, os.Chdir(dir))
+			if tt.beefile != "" {
+				require.NoError(t, os.WriteFile(filepath.Join(dir, "Beefile"), []byte(tt.beefile), 0644))
+			}
+			conf.Postman = postmanOptions{}
+			require.NoError(t, loadConfig())
+
+			assert.Equal(t, tt.expectedPrefix, conf.Postman.Prefix)
+			var keys []string
+			for _, h := range conf.Postman.Headers {
+				keys = append(keys, h.Key)
+			}
+			assert.Equal(t, tt.expectedKeys, keys)
+		})
+	}
+}
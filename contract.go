@@ -0,0 +1,497 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/zalora/bee/docgen"
+	"gopkg.in/yaml.v3"
+)
+
+var cmdContractTest = &Command{
+	UsageLine: "contract-test [-base=http://localhost:8080] [-spec=swagger/swagger.json] [-fixtures=contract.yml] [-report=contract-report.xml] [-unsafe]",
+	Short:     "check that a running app returns what its spec documents",
+	Long: `
+Contract-test calls every operation of the spec on a running app and checks
+that the response status is documented, that the content type is one the
+operation produces and that JSON bodies match the response schema.
+
+Requests are built from the examples, defaults and enums of the parameters
+and body definitions. Fixtures, keyed by operationId or "METHOD /path",
+supply the values that must exist in the app, such as path parameters:
+
+    OrderController.Get:
+      path:
+        id: 1001
+      query:
+        expand: lines
+      header:
+        Authorization: Bearer dev
+    OrderController.Delete:
+      skip: true
+
+Only the GET, HEAD and OPTIONS operations are called by default, so that the
+app can be a shared one. The operations changing data are called when they
+have a fixture, or all of them with -unsafe.
+
+The results are written as a JUnit XML report.
+`,
+}
+
+var (
+	contractBase     docValue
+	contractSpec     docValue
+	contractFixtures docValue
+	contractReport   docValue
+	contractUnsafe   bool
+)
+
+func init() {
+	cmdContractTest.Run = runContractTest
+	cmdContractTest.Flag.Var(&contractBase, "base", "base URL of the running app, default is http://localhost:8080")
+	cmdContractTest.Flag.Var(&contractSpec, "spec", "spec file, default is swagger/swagger.json")
+	cmdContractTest.Flag.Var(&contractFixtures, "fixtures", "fixtures file (yaml or json)")
+	cmdContractTest.Flag.Var(&contractReport, "report", "JUnit XML report, default is contract-report.xml")
+	cmdContractTest.Flag.BoolVar(&contractUnsafe, "unsafe", false, "also call the operations changing data without a fixture")
+}
+
+func runContractTest(cmd *Command, args []string) int {
+	ShowShortVersionBanner()
+
+	if contractBase == "" {
+		contractBase = "http://localhost:8080"
+	}
+	if contractSpec == "" {
+		contractSpec = docValue(filepath.Join("swagger", "swagger.json"))
+	}
+	if contractReport == "" {
+		contractReport = "contract-report.xml"
+	}
+
+	spec, err := docgen.LoadSpec(string(contractSpec))
+	if err != nil {
+		ColorLog("[ERRO] %s\n", err)
+		return 2
+	}
+
+	fixtures := map[string]contractFixture{}
+	if contractFixtures != "" {
+		fixtures, err = loadContractFixtures(string(contractFixtures))
+		if err != nil {
+			ColorLog("[ERRO] Could not load the fixtures: %s\n", err)
+			return 2
+		}
+	}
+
+	runner := &contractRunner{
+		base:     strings.TrimSuffix(string(contractBase), "/"),
+		spec:     spec,
+		fixtures: fixtures,
+		unsafe:   contractUnsafe,
+		client:   &http.Client{Timeout: 30 * time.Second},
+	}
+	results := runner.run()
+
+	failures := 0
+	for _, r := range results {
+		switch {
+		case r.skipped != "":
+			ColorLog("[WARN] %s skipped: %s\n", r.name(), r.skipped)
+		case len(r.failures) > 0:
+			failures++
+			ColorLog("[ERRO] %s\n", r.name())
+			for _, f := range r.failures {
+				ColorLog("[ERRO]   %s\n", f)
+			}
+		default:
+			ColorLog("[SUCC] %s: %d\n", r.name(), r.status)
+		}
+	}
+
+	if err := writeJUnitReport(string(contractReport), results); err != nil {
+		ColorLog("[ERRO] Could not write the report: %s\n", err)
+		return 2
+	}
+	ColorLog("[INFO] Report written to %s\n", contractReport)
+
+	if failures > 0 {
+		ColorLog("[ERRO] %d of %d operations do not match the spec\n", failures, len(results))
+		return 1
+	}
+	ColorLog("[SUCC] All %d operations match the spec\n", len(results))
+	return 0
+}
+
+// contractFixture holds the request values of an operation.
+type contractFixture struct {
+	Path   map[string]interface{} `json:"path" yaml:"path"`
+	Query  map[string]interface{} `json:"query" yaml:"query"`
+	Header map[string]string      `json:"header" yaml:"header"`
+	Body   interface{}            `json:"body" yaml:"body"`
+	Skip   bool                   `json:"skip" yaml:"skip"`
+}
+
+func loadContractFixtures(filename string) (map[string]contractFixture, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	fixtures := make(map[string]contractFixture)
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".yml", ".yaml":
+		err = yaml.Unmarshal(data, &fixtures)
+	default:
+		err = json.Unmarshal(data, &fixtures)
+	}
+	return fixtures, err
+}
+
+// contractResult is the outcome of an operation.
+type contractResult struct {
+	op       docgen.Operation
+	status   int
+	duration time.Duration
+	skipped  string
+	failures []string
+}
+
+func (r contractResult) name() string {
+	return r.op.Method + " " + r.op.Path
+}
+
+type contractRunner struct {
+	base     string
+	spec     *docgen.Spec
+	fixtures map[string]contractFixture
+	// Call the operations of unsafe methods without a fixture.
+	unsafe bool
+	client *http.Client
+}
+
+func (c *contractRunner) run() []contractResult {
+	var results []contractResult
+	for _, op := range c.spec.Operations() {
+		results = append(results, c.check(op))
+	}
+	return results
+}
+
+// fixture returns the fixture of op, by operationId or method and path,
+// and whether there is one.
+func (c *contractRunner) fixture(op docgen.Operation) (contractFixture, bool) {
+	if f, ok := c.fixtures[op.ID()]; ok {
+		return f, true
+	}
+	f, ok := c.fixtures[op.Method+" "+op.Path]
+	return f, ok
+}
+
+// isSafeMethod reports whether requests of method don't change data.
+func isSafeMethod(method string) bool {
+	switch strings.ToUpper(method) {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	}
+	return false
+}
+
+func (c *contractRunner) check(op docgen.Operation) contractResult {
+	result := contractResult{op: op}
+	fixture, ok := c.fixture(op)
+	if fixture.Skip {
+		result.skipped = "skipped by fixture"
+		return result
+	}
+	if !ok && !c.unsafe && !isSafeMethod(op.Method) {
+		result.skipped = "changes data, give it a fixture or run with -unsafe"
+		return result
+	}
+
+	req, err := c.request(op, fixture)
+	if err != nil {
+		result.skipped = err.Error()
+		return result
+	}
+
+	start := time.Now()
+	resp, err := c.client.Do(req)
+	result.duration = time.Since(start)
+	if err != nil {
+		result.failures = append(result.failures, err.Error())
+		return result
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		result.failures = append(result.failures, err.Error())
+		return result
+	}
+
+	result.status = resp.StatusCode
+	result.failures = c.checkResponse(op, resp, body)
+	return result
+}
+
+// request builds the request of op out of the fixture and the examples of
+// the spec.
+func (c *contractRunner) request(op docgen.Operation, fixture contractFixture) (*http.Request, error) {
+	rt := c.spec.BasePath() + op.Path
+	query := url.Values{}
+	header := http.Header{}
+	form := url.Values{}
+	var body []byte
+
+	for _, param := range op.Parameters() {
+		name, _ := param["name"].(string)
+		required, _ := param["required"].(bool)
+		switch param["in"] {
+		case "path":
+			v, ok := fixture.Path[name]
+			if !ok {
+				v = c.spec.Example(param)
+			}
+			rt = strings.Replace(rt, "{"+name+"}", url.PathEscape(paramString(v)), 1)
+		case "query":
+			if v, ok := fixture.Query[name]; ok {
+				query.Set(name, paramString(v))
+			} else if required || param["default"] != nil {
+				query.Set(name, paramString(c.spec.Example(param)))
+			}
+		case "header":
+			if _, ok := fixture.Header[name]; !ok && required {
+				header.Set(name, paramString(c.spec.Example(param)))
+			}
+		case "formData":
+			if param["type"] == "file" {
+				if required {
+					return nil, fmt.Errorf("file parameter %q needs a manual test", name)
+				}
+				continue
+			}
+			if required || param["default"] != nil {
+				form.Set(name, paramString(c.spec.Example(param)))
+			}
+		case "body":
+			v := fixture.Body
+			if v == nil {
+				schema, _ := param["schema"].(map[string]interface{})
+				v = c.spec.Example(schema)
+			}
+			var err error
+			if body, err = json.Marshal(v); err != nil {
+				return nil, err
+			}
+		}
+	}
+	for k, v := range fixture.Query {
+		if query.Get(k) == "" {
+			query.Set(k, paramString(v))
+		}
+	}
+	for k, v := range fixture.Header {
+		header.Set(k, v)
+	}
+
+	if len(form) > 0 {
+		body = []byte(form.Encode())
+		header.Set("Content-Type", "application/x-www-form-urlencoded")
+	} else if body != nil {
+		header.Set("Content-Type", contractContentType(c.spec.Consumes(op)))
+	}
+
+	u := c.base + rt
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	req, err := http.NewRequest(op.Method, u, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	if req.Header.Get("Accept") == "" {
+		if produces := c.spec.Produces(op); len(produces) > 0 {
+			req.Header.Set("Accept", strings.Join(produces, ", "))
+		}
+	}
+	return req, nil
+}
+
+// checkResponse compares a response with the documented ones.
+func (c *contractRunner) checkResponse(op docgen.Operation, resp *http.Response, body []byte) []string {
+	var failures []string
+
+	responses := op.Responses()
+	documented, ok := responses[strconv.Itoa(resp.StatusCode)]
+	if !ok {
+		documented, ok = responses["default"]
+	}
+	if !ok {
+		codes := make([]string, 0, len(responses))
+		for code := range responses {
+			codes = append(codes, code)
+		}
+		sort.Strings(codes)
+		return append(failures, fmt.Sprintf("status %d is not documented, expected one of %v", resp.StatusCode, codes))
+	}
+
+	if len(bytes.TrimSpace(body)) == 0 {
+		if documented["schema"] != nil && resp.Request.Method != http.MethodHead {
+			failures = append(failures, fmt.Sprintf("status %d should have a body", resp.StatusCode))
+		}
+		return failures
+	}
+
+	contentType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	produces := c.spec.Produces(op)
+	if len(produces) > 0 && !containsString(produces, contentType) && resp.StatusCode < 400 {
+		failures = append(failures, fmt.Sprintf("content type %q is not one of %v", contentType, produces))
+	}
+
+	schema, ok := documented["schema"].(map[string]interface{})
+	if !ok || !strings.Contains(contentType, "json") {
+		return failures
+	}
+	var v interface{}
+	if err := json.Unmarshal(body, &v); err != nil {
+		return append(failures, "invalid JSON body: "+err.Error())
+	}
+	for _, err := range c.spec.Validate("body", schema, v) {
+		failures = append(failures, err.Error())
+	}
+	return failures
+}
+
+// contractContentType prefers JSON among the accepted content types.
+func contractContentType(consumes []string) string {
+	for _, ct := range consumes {
+		if strings.Contains(ct, "json") {
+			return ct
+		}
+	}
+	return "application/json"
+}
+
+// paramString formats a parameter value, arrays as CSV.
+func paramString(v interface{}) string {
+	switch t := v.(type) {
+	case nil:
+		return ""
+	case []interface{}:
+		values := make([]string, len(t))
+		for i, item := range t {
+			values[i] = paramString(item)
+		}
+		return strings.Join(values, ",")
+	}
+	return fmt.Sprint(v)
+}
+
+// JUnit XML report, as understood by Jenkins, GitLab and the like.
+type junitTestSuites struct {
+	XMLName xml.Name         `xml:"testsuites"`
+	Suites  []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Skipped  int             `xml:"skipped,attr"`
+	Time     string          `xml:"time,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Skipped   *junitSkipped `xml:"skipped,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+type junitSkipped struct {
+	Message string `xml:"message,attr"`
+}
+
+// junitReport groups the results in one suite per tag.
+func junitReport(results []contractResult) junitTestSuites {
+	suites := make(map[string]*junitTestSuite)
+	var names []string
+	for _, r := range results {
+		tag := "default"
+		if tags := r.op.Tags(); len(tags) > 0 {
+			tag = tags[0]
+		}
+		suite, ok := suites[tag]
+		if !ok {
+			suite = &junitTestSuite{Name: tag}
+			suites[tag] = suite
+			names = append(names, tag)
+		}
+
+		tc := junitTestCase{
+			Name:      r.name(),
+			ClassName: r.op.ID(),
+			Time:      fmt.Sprintf("%.3f", r.duration.Seconds()),
+		}
+		if r.status != 0 {
+			tc.SystemOut = fmt.Sprintf("status %d", r.status)
+		}
+		switch {
+		case r.skipped != "":
+			tc.Skipped = &junitSkipped{Message: r.skipped}
+			suite.Skipped++
+		case len(r.failures) > 0:
+			tc.Failure = &junitFailure{
+				Message: r.failures[0],
+				Text:    strings.Join(r.failures, "\n"),
+			}
+			suite.Failures++
+		}
+		suite.Tests++
+		suite.Cases = append(suite.Cases, tc)
+	}
+
+	sort.Strings(names)
+	var report junitTestSuites
+	for _, name := range names {
+		suite := suites[name]
+		var total float64
+		for _, tc := range suite.Cases {
+			t, _ := strconv.ParseFloat(tc.Time, 64)
+			total += t
+		}
+		suite.Time = fmt.Sprintf("%.3f", total)
+		report.Suites = append(report.Suites, *suite)
+	}
+	return report
+}
+
+func writeJUnitReport(filename string, results []contractResult) error {
+	data, err := xml.MarshalIndent(junitReport(results), "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filename, append([]byte(xml.Header), data...), 0644)
+}
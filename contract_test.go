@@ -0,0 +1,139 @@
+package main
+
+import (
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContractRunnerAgainstMock(t *testing.T) {
+	mock := newTestMockServer(t)
+	app := httptest.NewServer(mock)
+	defer app.Close()
+
+	runner := &contractRunner{
+		base: app.URL,
+		spec: mock.spec,
+		fixtures: map[string]contractFixture{
+			"OrderController.Get": {Path: map[string]interface{}{"id": 7}},
+			"POST /orders":        {Skip: true},
+		},
+		client: app.Client(),
+	}
+
+	results := runner.run()
+	assert.Len(t, results, 3)
+	for _, r := range results {
+		assert.Empty(t, r.failures, r.name())
+	}
+	assert.Equal(t, "skipped by fixture", results[0].skipped)
+	assert.Equal(t, http.StatusNoContent, results[1].status)
+	assert.Equal(t, http.StatusOK, results[2].status)
+}
+
+func TestContractRunnerUnsafeMethods(t *testing.T) {
+	mock := newTestMockServer(t)
+	app := httptest.NewServer(mock)
+	defer app.Close()
+
+	runner := &contractRunner{base: app.URL, spec: mock.spec, client: app.Client()}
+	results := runner.run()
+	assert.Equal(t, "POST /orders", results[0].name())
+	assert.Equal(t, "changes data, give it a fixture or run with -unsafe", results[0].skipped)
+	assert.Empty(t, results[1].skipped)
+	assert.Empty(t, results[2].skipped)
+
+	runner.fixtures = map[string]contractFixture{"OrderController.Post": {}}
+	assert.Empty(t, runner.check(mock.spec.Operations()[0]).skipped)
+
+	runner.fixtures, runner.unsafe = nil, true
+	assert.Empty(t, runner.check(mock.spec.Operations()[0]).skipped)
+}
+
+func TestContractRunnerFailures(t *testing.T) {
+	mock := newTestMockServer(t)
+
+	tests := []struct {
+		desc     string
+		handler  http.HandlerFunc
+		expected []string
+	}{
+		{
+			desc: "undocumented status",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusInternalServerError)
+			},
+			expected: []string{"status 500 is not documented, expected one of [200 404]"},
+		},
+		{
+			desc: "wrong content type",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "text/html")
+				w.Write([]byte("<html></html>"))
+			},
+			expected: []string{`content type "text/html" is not one of [application/json]`},
+		},
+		{
+			desc: "body does not match the schema",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "/v1/orders/0", r.URL.Path)
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte(`{"id": "1", "status": "lost"}`))
+			},
+			expected: []string{
+				"body.id: must be a number",
+				"body.status: must be one of [open, closed]",
+			},
+		},
+		{
+			desc: "missing body",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			},
+			expected: []string{"status 200 should have a body"},
+		},
+	}
+
+	mock.spec.Document["produces"] = []interface{}{"application/json"}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			app := httptest.NewServer(tt.handler)
+			defer app.Close()
+
+			runner := &contractRunner{base: app.URL, spec: mock.spec, client: app.Client()}
+			result := runner.check(mock.spec.Operations()[2])
+			assert.Equal(t, "GET /orders/{id}", result.name())
+			assert.Equal(t, tt.expected, result.failures)
+		})
+	}
+}
+
+func TestJUnitReport(t *testing.T) {
+	mock := newTestMockServer(t)
+	ops := mock.spec.Operations()
+	results := []contractResult{
+		{op: ops[0], skipped: "skipped by fixture"},
+		{op: ops[1], status: 204},
+		{op: ops[2], status: 500, failures: []string{"status 500 is not documented"}},
+	}
+
+	data, err := xml.MarshalIndent(junitReport(results), "", "  ")
+	assert.NoError(t, err)
+	assert.Equal(t, `<testsuites>
+  <testsuite name="default" tests="3" failures="1" skipped="1" time="0.000">
+    <testcase name="POST /orders" classname="OrderController.Post" time="0.000">
+      <skipped message="skipped by fixture"></skipped>
+    </testcase>
+    <testcase name="GET /orders/files" classname="OrderController.Files" time="0.000">
+      <system-out>status 204</system-out>
+    </testcase>
+    <testcase name="GET /orders/{id}" classname="OrderController.Get" time="0.000">
+      <failure message="status 500 is not documented">status 500 is not documented</failure>
+      <system-out>status 500</system-out>
+    </testcase>
+  </testsuite>
+</testsuites>`, string(data))
+}
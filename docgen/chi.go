@@ -1,4 +1,4 @@
-package main
+package docgen
 
 import (
 	"errors"
@@ -6,21 +6,17 @@ import (
 	"go/parser"
 	"go/token"
 	path "path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/astaxie/beego/swagger"
 )
 
-const (
-	chiPath          = "pkg/router/routes.go"
-	handlerPrefixCHI = "github.com/zalora/doraemon/handlers/"
-)
-
-func generateChiDocs(curpath string) error {
+func (g *Generator) generateChiDocs() error {
 	fset := token.NewFileSet()
 	f, err := parser.ParseFile(
 		fset,
-		path.Join(curpath, chiPath),
+		path.Join(g.dir, g.opts.ChiRoutes),
 		nil,
 		parser.ParseComments)
 	if err != nil {
@@ -33,15 +29,19 @@ func generateChiDocs(curpath string) error {
 			localName = im.Name.Name
 		}
 
-		analisyscontrollerPkg(localName, im.Path.Value)
+		g.analisyscontrollerPkg(localName, im.Path.Value)
 	}
 
-	for rt, item := range chiAPIs {
+	tagPackages := make(map[string]string)
+	for rt, item := range g.chiAPIs {
 		baseURLSplit := strings.Split(rt, "/")
 		if len(baseURLSplit) <= 1 {
 			continue
 		}
 		tag := baseURLSplit[1]
+		if _, ok := tagPackages[tag]; !ok {
+			tagPackages[tag] = g.chiAPIPackages[rt]
+		}
 
 		appendTag(item.Get, tag)
 		appendTag(item.Post, tag)
@@ -51,19 +51,61 @@ func generateChiDocs(curpath string) error {
 		appendTag(item.Delete, tag)
 		appendTag(item.Options, tag)
 
-		if len(rootapi.Paths) == 0 {
-			rootapi.Paths = make(map[string]*swagger.Item)
+		if len(g.rootapi.Paths) == 0 {
+			g.rootapi.Paths = make(map[string]*swagger.Item)
 		}
 
-		rt = urlReplace(rt)
-		rootapi.Paths[rt] = item
+		rt, params := translateRoute(rt)
+		g.addRouteParameters(rt, item, params)
+		g.rootapi.Paths[rt] = item
+	}
+
+	tags := generateChiTags(f, fset)
+	g.rootapi.Tags = append(g.rootapi.Tags, tags...)
+	g.rootapi.Tags = append(g.rootapi.Tags, g.generateChiPackageTags(tags, tagPackages)...)
+	for tag, pkgpath := range tagPackages {
+		g.applyControllerExtensions(tag, pkgpath)
 	}
 
-	rootapi.Tags = append(rootapi.Tags, generateChiTags(f, fset)...)
+	for _, cg := range f.Comments {
+		g.collectTagOverrides(cg)
+	}
 
 	return nil
 }
 
+// generateChiPackageTags describes the tags that have no comment in the
+// routes file with the package doc of their handlers.
+func (g *Generator) generateChiPackageTags(tags []swagger.Tag, tagPackages map[string]string) []swagger.Tag {
+	described := make(map[string]bool)
+	for _, t := range tags {
+		described[t.Name] = true
+	}
+
+	names := make([]string, 0, len(tagPackages))
+	for name := range tagPackages {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var pkgTags []swagger.Tag
+	for _, name := range names {
+		if described[name] {
+			continue
+		}
+		doc := g.packageDocs[tagPackages[name]]
+		if doc == "" {
+			continue
+		}
+		pkgTags = append(pkgTags, swagger.Tag{
+			Name:        name,
+			Description: doc + "\n",
+		})
+	}
+
+	return pkgTags
+}
+
 func appendTag(op *swagger.Operation, tag string) {
 	if op == nil {
 		return
@@ -72,8 +114,8 @@ func appendTag(op *swagger.Operation, tag string) {
 	op.Tags = append(op.Tags, tag)
 }
 
-func isCHI(pkgpath string) bool {
-	return strings.HasPrefix(pkgpath, handlerPrefixCHI)
+func (g *Generator) isCHI(pkgpath string) bool {
+	return strings.HasPrefix(pkgpath, g.opts.ChiHandlerPrefix)
 }
 
 func generateChiTags(node *ast.File, fset *token.FileSet) []swagger.Tag {
@@ -0,0 +1,552 @@
+package docgen
+
+import (
+	"context"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/astaxie/beego/swagger"
+	"github.com/zalora/bee/docgen/schema"
+)
+
+// handlerDoc is a function annotated with @router.
+type handlerDoc struct {
+	pkgpath    string
+	controller string // empty for chi handlers
+	function   string
+	method     string
+	path       string // relative to the NSInclude prefix for controllers
+	chi        bool
+	op         swagger.Operation // as annotated, before the route parameters are added
+}
+
+// Check is the outcome of a coverage check of a route.
+type Check int
+
+const (
+	CheckMissing Check = iota
+	CheckPassed
+	CheckNotApplicable
+)
+
+func (c Check) String() string {
+	switch c {
+	case CheckPassed:
+		return "yes"
+	case CheckNotApplicable:
+		return "-"
+	}
+	return "no"
+}
+
+// RouteCoverage is the documentation coverage of a route.
+type RouteCoverage struct {
+	Method string
+	Path   string // path template including the namespace prefixes
+	// Handler is the documented function, e.g. OrderController.Get or
+	// orders.GetOrder.
+	Handler string
+	// Group is the controller, or the package of chi handlers.
+	Group      string
+	Registered bool // found by the router walkers
+	Documented bool // has a @router annotation
+
+	Summary Check // @Summary or @Description
+	Params  Check // @Param for the path parameters, or the request body
+	Success Check // a 2xx or default response
+	Failure Check // a 4xx or 5xx response
+}
+
+// Score returns the passed and the applicable checks of the route.
+func (r RouteCoverage) Score() (passed, total int) {
+	for _, c := range []Check{r.Summary, r.Params, r.Success, r.Failure} {
+		if c == CheckNotApplicable {
+			continue
+		}
+		total++
+		if c == CheckPassed {
+			passed++
+		}
+	}
+	return passed, total
+}
+
+// Coverage is the documentation coverage of the routes of a project.
+type Coverage struct {
+	Routes []RouteCoverage
+}
+
+// Groups returns the groups of the registered routes in sorted order.
+func (c *Coverage) Groups() []string {
+	seen := make(map[string]bool)
+	var groups []string
+	for _, r := range c.Routes {
+		if r.Registered && !seen[r.Group] {
+			seen[r.Group] = true
+			groups = append(groups, r.Group)
+		}
+	}
+	sort.Strings(groups)
+	return groups
+}
+
+// Percent returns the percentage of passed checks of the registered routes
+// of group, or of all registered routes when group is empty.
+func (c *Coverage) Percent(group string) float64 {
+	passed, total := 0, 0
+	for _, r := range c.Routes {
+		if !r.Registered || (group != "" && r.Group != group) {
+			continue
+		}
+		p, t := r.Score()
+		passed += p
+		total += t
+	}
+	if total == 0 {
+		return 100
+	}
+	return 100 * float64(passed) / float64(total)
+}
+
+// Unregistered returns the documented routes no router registers.
+func (c *Coverage) Unregistered() []RouteCoverage {
+	var routes []RouteCoverage
+	for _, r := range c.Routes {
+		if !r.Registered {
+			routes = append(routes, r)
+		}
+	}
+	return routes
+}
+
+// registration is a route found in the router files.
+type registration struct {
+	method  string
+	path    string
+	group   string
+	handler string      // function name when there is no handlerDoc
+	doc     *handlerDoc // nil when the function is not annotated
+}
+
+// Coverage cross-references the routes registered in the beego router and
+// the chi routes file with the @router annotations of their handlers.
+// Documents are not written, whatever the configured formats.
+func (g *Generator) Coverage(ctx context.Context) (*Coverage, []Diagnostic, error) {
+	formats := g.opts.Formats
+	g.opts.Formats = nil
+	defer func() { g.opts.Formats = formats }()
+
+	_, diagnostics, err := g.Generate(ctx)
+	if err != nil {
+		return nil, diagnostics, err
+	}
+
+	var regs []registration
+	fset := token.NewFileSet()
+	if f, err := parser.ParseFile(fset, filepath.Join(g.dir, g.opts.Router), nil, 0); err == nil {
+		ast.Inspect(f, func(n ast.Node) bool {
+			call, ok := n.(*ast.CallExpr)
+			if !ok {
+				return true
+			}
+			if sel, ok := call.Fun.(*ast.SelectorExpr); ok && fmt.Sprint(sel.X) == "beego" {
+				switch sel.Sel.Name {
+				case "NewNamespace", "Router", "Include", "Get", "Post", "Put", "Patch", "Delete", "Head", "Options", "Any":
+					regs = append(regs, g.beegoRegistrations(call, "")...)
+					return false
+				}
+			}
+			return true
+		})
+	}
+	if f, err := parser.ParseFile(fset, filepath.Join(g.dir, g.opts.ChiRoutes), nil, 0); err == nil {
+		imports := fileImports(f)
+		for _, decl := range f.Decls {
+			if fn, ok := decl.(*ast.FuncDecl); ok && fn.Body != nil {
+				regs = append(regs, g.chiRegistrations(fn.Body.List, "/", imports)...)
+			}
+		}
+	} else if !os.IsNotExist(err) {
+		g.warnf("coverage: %v", err)
+	}
+
+	cov := &Coverage{}
+	used := make(map[*handlerDoc]bool)
+	for _, reg := range regs {
+		if reg.doc != nil {
+			used[reg.doc] = true
+		}
+		cov.Routes = append(cov.Routes, coverRoute(reg, true))
+	}
+	for i := range g.handlers {
+		h := &g.handlers[i]
+		if used[h] {
+			continue
+		}
+		reg := registration{method: h.method, path: h.path, doc: h}
+		if h.chi {
+			reg.group = path.Base(h.pkgpath)
+		} else {
+			reg.group = h.controller
+		}
+		cov.Routes = append(cov.Routes, coverRoute(reg, false))
+	}
+
+	sort.SliceStable(cov.Routes, func(i, j int) bool {
+		a, b := cov.Routes[i], cov.Routes[j]
+		if a.Group != b.Group {
+			return a.Group < b.Group
+		}
+		if a.Path != b.Path {
+			return a.Path < b.Path
+		}
+		return a.Method < b.Method
+	})
+	return cov, g.diagnostics, nil
+}
+
+func coverRoute(reg registration, registered bool) RouteCoverage {
+	rt, params := translateRoute(reg.path)
+	r := RouteCoverage{
+		Method:     reg.method,
+		Path:       rt,
+		Handler:    reg.handler,
+		Group:      reg.group,
+		Registered: registered,
+		Documented: reg.doc != nil,
+	}
+	if reg.doc == nil {
+		if len(params) == 0 && !hasBody(reg.method) {
+			r.Params = CheckNotApplicable
+		}
+		return r
+	}
+
+	op := reg.doc.op
+	r.Handler = reg.doc.function
+	if reg.doc.controller != "" {
+		r.Handler = reg.doc.controller + "." + reg.doc.function
+	} else {
+		r.Handler = path.Base(reg.doc.pkgpath) + "." + reg.doc.function
+	}
+
+	if op.Summary != "" || op.Description != "" {
+		r.Summary = CheckPassed
+	}
+
+	documented := make(map[string]bool)
+	for _, p := range op.Parameters {
+		documented[p.In+":"+p.Name] = true
+	}
+	switch {
+	case len(params) > 0:
+		r.Params = CheckPassed
+		for _, p := range params {
+			if !documented["path:"+p.Name] {
+				r.Params = CheckMissing
+			}
+		}
+	case len(op.Parameters) > 0:
+		r.Params = CheckPassed
+	case !hasBody(reg.method):
+		r.Params = CheckNotApplicable
+	}
+
+	for code := range op.Responses {
+		switch {
+		case strings.HasPrefix(code, "2") || code == "default":
+			r.Success = CheckPassed
+		case strings.HasPrefix(code, "4") || strings.HasPrefix(code, "5"):
+			r.Failure = CheckPassed
+		}
+	}
+	return r
+}
+
+func hasBody(method string) bool {
+	switch method {
+	case "POST", "PUT", "PATCH":
+		return true
+	}
+	return false
+}
+
+// beegoRegistrations returns the routes registered by a call of the beego
+// router, following the namespaces.
+func (g *Generator) beegoRegistrations(call *ast.CallExpr, prefix string) []registration {
+	var regs []registration
+	name := callName(call)
+	switch name {
+	case "NewNamespace", "NSNamespace":
+		if len(call.Args) == 0 {
+			return nil
+		}
+		prefix += stringLit(call.Args[0])
+		for _, arg := range call.Args[1:] {
+			if c, ok := arg.(*ast.CallExpr); ok {
+				regs = append(regs, g.beegoRegistrations(c, prefix)...)
+			}
+		}
+	case "NSInclude", "Include":
+		// Only the annotated methods are registered.
+		for _, arg := range call.Args {
+			key, _ := g.controllerRef(arg)
+			for i := range g.handlers {
+				h := &g.handlers[i]
+				if !h.chi && h.pkgpath+h.controller == key {
+					regs = append(regs, registration{
+						method: h.method,
+						path:   prefix + h.path,
+						group:  h.controller,
+						doc:    h,
+					})
+				}
+			}
+		}
+	case "NSRouter", "Router":
+		if len(call.Args) < 2 {
+			return nil
+		}
+		rt := prefix + stringLit(call.Args[0])
+		key, controller := g.controllerRef(call.Args[1])
+		mapping := ""
+		if len(call.Args) > 2 {
+			mapping = stringLit(call.Args[2])
+		}
+		for method, function := range beegoMappings(mapping) {
+			reg := registration{method: method, path: rt, group: controller, handler: controller + "." + function}
+			for i := range g.handlers {
+				h := &g.handlers[i]
+				if !h.chi && h.pkgpath+h.controller == key && h.function == function {
+					reg.doc = h
+				}
+			}
+			// Without a mapping only the methods the controller
+			// implements are routed, the annotated ones are all we know.
+			if mapping == "" && reg.doc == nil {
+				continue
+			}
+			regs = append(regs, reg)
+		}
+		if len(regs) == 0 {
+			regs = append(regs, registration{method: "*", path: rt, group: controller, handler: controller})
+		}
+	case "NSGet", "NSPost", "NSPut", "NSPatch", "NSDelete", "NSHead", "NSOptions", "NSAny",
+		"Get", "Post", "Put", "Patch", "Delete", "Head", "Options", "Any":
+		// Closures, which have no annotations.
+		method := strings.ToUpper(strings.TrimPrefix(name, "NS"))
+		if len(call.Args) == 0 {
+			return nil
+		}
+		if method == "ANY" {
+			method = "*"
+		}
+		regs = append(regs, registration{
+			method:  method,
+			path:    prefix + stringLit(call.Args[0]),
+			group:   "routers",
+			handler: "func",
+		})
+	}
+	return regs
+}
+
+// beegoMappings parses the method mapping of beego.Router, e.g.
+// "get,post:Save;delete:Remove". Without a mapping the RESTful methods
+// are registered.
+func beegoMappings(mapping string) map[string]string {
+	methods := make(map[string]string)
+	if mapping == "" {
+		for _, m := range operationMethods {
+			methods[m] = strings.Title(strings.ToLower(m))
+		}
+		return methods
+	}
+	for _, part := range strings.Split(mapping, ";") {
+		colon := strings.Index(part, ":")
+		if colon < 0 {
+			continue
+		}
+		for _, m := range strings.Split(part[:colon], ",") {
+			methods[strings.ToUpper(strings.TrimSpace(m))] = strings.TrimSpace(part[colon+1:])
+		}
+	}
+	return methods
+}
+
+// controllerRef returns the key (pkgpath+controller) and the name of the
+// controller of &pkg.Controller{}.
+func (g *Generator) controllerRef(expr ast.Expr) (key, name string) {
+	lit := compositeLit(expr)
+	if lit == nil {
+		return "", ""
+	}
+	sel, ok := lit.Type.(*ast.SelectorExpr)
+	if !ok {
+		return "", ""
+	}
+	return g.importlist[fmt.Sprint(sel.X)] + sel.Sel.Name, sel.Sel.Name
+}
+
+func compositeLit(expr ast.Expr) *ast.CompositeLit {
+	if u, ok := expr.(*ast.UnaryExpr); ok {
+		expr = u.X
+	}
+	lit, _ := expr.(*ast.CompositeLit)
+	return lit
+}
+
+var chiMethods = map[string]string{
+	"Get": "GET", "Post": "POST", "Put": "PUT", "Patch": "PATCH", "Delete": "DELETE",
+	"Head": "HEAD", "Options": "OPTIONS", "Connect": "CONNECT", "Trace": "TRACE",
+}
+
+// chiRegistrations returns the routes registered by the statements of a
+// chi router function, following Route and Group.
+func (g *Generator) chiRegistrations(stmts []ast.Stmt, prefix string, imports map[string]string) []registration {
+	var regs []registration
+	for _, stmt := range stmts {
+		expr, ok := stmt.(*ast.ExprStmt)
+		if !ok {
+			continue
+		}
+		call, ok := expr.X.(*ast.CallExpr)
+		if !ok {
+			continue
+		}
+
+		name := callName(call)
+		var method, pattern string
+		var handler ast.Expr
+		switch {
+		case name == "Route" && len(call.Args) == 2:
+			if fn, ok := call.Args[1].(*ast.FuncLit); ok {
+				regs = append(regs, g.chiRegistrations(fn.Body.List, path.Join(prefix, stringLit(call.Args[0])), imports)...)
+			}
+			continue
+		case name == "Group" && len(call.Args) == 1:
+			if fn, ok := call.Args[0].(*ast.FuncLit); ok {
+				regs = append(regs, g.chiRegistrations(fn.Body.List, prefix, imports)...)
+			}
+			continue
+		case chiMethods[name] != "" && len(call.Args) == 2:
+			method, pattern, handler = chiMethods[name], stringLit(call.Args[0]), call.Args[1]
+		case (name == "Method" || name == "MethodFunc") && len(call.Args) == 3:
+			method, pattern, handler = strings.ToUpper(stringLit(call.Args[0])), stringLit(call.Args[1]), call.Args[2]
+		case (name == "Handle" || name == "HandleFunc") && len(call.Args) == 2:
+			method, pattern, handler = "*", stringLit(call.Args[0]), call.Args[1]
+		default:
+			continue
+		}
+
+		rt := path.Join(prefix, pattern)
+		pkgpath, function := handlerName(handler, imports)
+		reg := registration{method: method, path: rt, group: path.Base(pkgpath), handler: path.Base(pkgpath) + "." + function}
+		if pkgpath == "" {
+			reg.group, reg.handler = "routes", function
+		}
+		reg.doc = g.chiHandler(pkgpath, function, method, rt)
+		if reg.doc != nil {
+			reg.group = path.Base(reg.doc.pkgpath)
+		}
+		regs = append(regs, reg)
+	}
+	return regs
+}
+
+// chiHandler returns the annotated handler of a chi route, by function
+// name first and by method and path otherwise.
+func (g *Generator) chiHandler(pkgpath, function, method, rt string) *handlerDoc {
+	route := routeKey(rt)
+	var byName *handlerDoc
+	for i := range g.handlers {
+		h := &g.handlers[i]
+		if !h.chi {
+			continue
+		}
+		if function != "" && h.function == function && (pkgpath == "" || h.pkgpath == pkgpath) {
+			if h.method == method && routeKey(h.path) == route {
+				return h
+			}
+			if byName == nil {
+				byName = h
+			}
+		}
+	}
+	if byName != nil {
+		return byName
+	}
+	for i := range g.handlers {
+		h := &g.handlers[i]
+		if h.chi && h.method == method && routeKey(h.path) == route {
+			return h
+		}
+	}
+	return nil
+}
+
+// routeKey drops the parameter names of a route so that /orders/:id and
+// /orders/{orderID} compare equal.
+func routeKey(rt string) string {
+	rt, _ = translateRoute(rt)
+	return "/" + strings.Trim(schema.PathParamRegexp.ReplaceAllString(rt, "{}"), "/")
+}
+
+// handlerName returns the package and function of a handler expression
+// such as orders.Get, h.Get or http.HandlerFunc(orders.Get).
+func handlerName(expr ast.Expr, imports map[string]string) (pkgpath, function string) {
+	switch e := expr.(type) {
+	case *ast.CallExpr:
+		if len(e.Args) == 1 {
+			return handlerName(e.Args[0], imports)
+		}
+	case *ast.SelectorExpr:
+		if ident, ok := e.X.(*ast.Ident); ok {
+			pkgpath = imports[ident.Name]
+		}
+		return pkgpath, e.Sel.Name
+	case *ast.Ident:
+		return "", e.Name
+	}
+	return "", ""
+}
+
+func fileImports(f *ast.File) map[string]string {
+	imports := make(map[string]string)
+	for _, im := range f.Imports {
+		pkgpath, _ := strconv.Unquote(im.Path.Value)
+		name := path.Base(pkgpath)
+		if im.Name != nil {
+			name = im.Name.Name
+		}
+		imports[name] = pkgpath
+	}
+	return imports
+}
+
+func callName(call *ast.CallExpr) string {
+	switch fn := call.Fun.(type) {
+	case *ast.SelectorExpr:
+		return fn.Sel.Name
+	case *ast.Ident:
+		return fn.Name
+	}
+	return ""
+}
+
+func stringLit(expr ast.Expr) string {
+	lit, ok := expr.(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return ""
+	}
+	s, _ := strconv.Unquote(lit.Value)
+	return s
+}
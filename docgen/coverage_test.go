@@ -0,0 +1,38 @@
+package docgen
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCoverage(t *testing.T) {
+	gen := New(Options{
+		Dir:              "testdata/shop",
+		ChiRoutes:        "chi/routes.go",
+		ChiHandlerPrefix: "github.com/acme/shop/pkg/handlers/",
+	})
+
+	cov, _, err := gen.Coverage(context.Background())
+	require.NoError(t, err)
+
+	yes, no, na := CheckPassed, CheckMissing, CheckNotApplicable
+	assert.Equal(t, []RouteCoverage{
+		{"DELETE", "/v1/carts/{id}", "CartController.Clear", "CartController", true, false, no, no, no, no},
+		{"GET", "/v1/carts/{id}", "CartController.Get", "CartController", true, true, yes, yes, yes, no},
+		{"GET", "/legacy", "LegacyController.List", "LegacyController", false, true, no, na, no, no},
+		{"GET", "/v1/orders/{id}", "OrderController.Get", "OrderController", true, true, yes, yes, yes, yes},
+		{"POST", "/v1/orders/{id}/cancel", "OrderController.Cancel", "OrderController", true, true, no, no, no, no},
+		{"POST", "/v2/carts", "carts.Create", "carts", true, false, no, no, no, no},
+		{"GET", "/v2/carts/{cartID}", "carts.Get", "carts", true, true, yes, yes, yes, yes},
+	}, cov.Routes)
+
+	assert.Equal(t, []string{"CartController", "OrderController", "carts"}, cov.Groups())
+	assert.Equal(t, 37.5, cov.Percent("CartController"))
+	assert.Equal(t, 50.0, cov.Percent("OrderController"))
+	assert.Equal(t, 50.0, cov.Percent("carts"))
+	assert.InDelta(t, 45.83, cov.Percent(""), 0.01)
+	assert.Len(t, cov.Unregistered(), 1)
+}
@@ -0,0 +1,72 @@
+package docgen
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/astaxie/beego/swagger"
+)
+
+const deprecationDateLayout = "2006-01-02"
+
+// now is replaced in tests.
+var now = time.Now
+
+// parseDeprecated parses
+//
+//	@Deprecated true since=2026-01-01 sunset=2026-06-30 replacement=/v2/orders
+//
+// The lifecycle details are emitted as x-deprecated-since, x-sunset and
+// x-replaced-by extensions of the operation.
+func (g *Generator) parseDeprecated(op *swagger.Operation, value, controllerName, funcName string) {
+	fields := strings.Fields(value)
+	if len(fields) == 0 {
+		return
+	}
+
+	op.Deprecated, _ = strconv.ParseBool(fields[0])
+	if !op.Deprecated {
+		return
+	}
+
+	for _, f := range fields[1:] {
+		kv := strings.SplitN(f, "=", 2)
+		if len(kv) != 2 || kv[1] == "" {
+			g.warnf("[%s.%s] invalid @Deprecated option: %s", controllerName, funcName, f)
+			continue
+		}
+
+		switch kv[0] {
+		case "since", "sunset":
+			if _, err := time.Parse(deprecationDateLayout, kv[1]); err != nil {
+				g.warnf("[%s.%s] @Deprecated %s should be a YYYY-MM-DD date: %s", controllerName, funcName, kv[0], kv[1])
+				continue
+			}
+			if kv[0] == "since" {
+				g.docExtras.setOperation(op, "x-deprecated-since", kv[1])
+			} else {
+				g.docExtras.setOperation(op, "x-sunset", kv[1])
+			}
+		case "replacement":
+			g.docExtras.setOperation(op, "x-replaced-by", kv[1])
+		default:
+			g.warnf("[%s.%s] unknown @Deprecated option: %s", controllerName, funcName, kv[0])
+		}
+	}
+}
+
+// isPastSunset reports whether the sunset date has passed.
+func isPastSunset(sunset string) bool {
+	return PastSunset(sunset, now())
+}
+
+// PastSunset reports whether the YYYY-MM-DD sunset date of an operation
+// has passed at t. The operation is still available on the sunset date.
+func PastSunset(sunset string, t time.Time) bool {
+	date, err := time.Parse(deprecationDateLayout, sunset)
+	if err != nil {
+		return false
+	}
+	return t.After(date.AddDate(0, 0, 1))
+}
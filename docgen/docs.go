@@ -0,0 +1,1580 @@
+// Copyright 2013 bee authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package docgen
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/astaxie/beego/swagger"
+	"github.com/astaxie/beego/utils"
+	"golang.org/x/mod/modfile"
+)
+
+const (
+	ajson  = "application/json"
+	axml   = "application/xml"
+	aplain = "text/plain"
+	ahtml  = "text/html"
+
+	contentTypeMultipartFormData = "multipart/form-data"
+	contentTypeFormUrlencoded    = "application/x-www-form-urlencoded"
+
+	content_type_thrift_binary_webcontent_v1 = "application/vnd.zalora.webcontent.v1+thrift.binary"
+	content_type_thrift_json_webcontent_v1   = "application/vnd.zalora.webcontent.v1+thrift.json"
+	content_type_thrift_binary               = "application/vnd.apache.thrift.binary"
+	content_type_thrift_json                 = "application/vnd.apache.thrift.json"
+
+	modfileName = "go.mod"
+)
+
+// generate parses the router file, the controllers it includes and the chi
+// routes into g.rootapi.
+func (g *Generator) generate() {
+	fset := token.NewFileSet()
+
+	f, err := parser.ParseFile(fset, filepath.Join(g.dir, g.opts.Router), nil, parser.ParseComments)
+
+	if err != nil {
+		g.failf("parse %s error: %v", g.opts.Router, err)
+	}
+
+	g.rootapi.Infos = swagger.Information{}
+	g.rootapi.SwaggerVersion = "2.0"
+	//analysis API comments
+	if f.Comments != nil {
+		for _, c := range f.Comments {
+			for _, s := range strings.Split(c.Text(), "\n") {
+				if strings.HasPrefix(s, "@APIVersion") {
+					g.rootapi.Infos.Version = strings.TrimSpace(s[len("@APIVersion"):])
+				} else if strings.HasPrefix(s, "@Title") {
+					g.rootapi.Infos.Title = strings.TrimSpace(s[len("@Title"):])
+				} else if strings.HasPrefix(s, "@Description") {
+					desc := strings.TrimSpace(s[len("@Description"):])
+					desc = strings.ReplaceAll(desc, "\\n", "\n")
+					g.rootapi.Infos.Description = desc
+				} else if strings.HasPrefix(s, "@TermsOfServiceUrl") {
+					g.rootapi.Infos.TermsOfService = strings.TrimSpace(s[len("@TermsOfServiceUrl"):])
+				} else if strings.HasPrefix(s, "@Contact") {
+					g.rootapi.Infos.Contact.EMail = strings.TrimSpace(s[len("@Contact"):])
+				} else if strings.HasPrefix(s, "@Name") {
+					g.rootapi.Infos.Contact.Name = strings.TrimSpace(s[len("@Name"):])
+				} else if strings.HasPrefix(s, "@URL") {
+					g.rootapi.Infos.Contact.URL = strings.TrimSpace(s[len("@URL"):])
+				} else if strings.HasPrefix(s, "@License") {
+					if g.rootapi.Infos.License == nil {
+						g.rootapi.Infos.License = &swagger.License{Name: strings.TrimSpace(s[len("@License"):])}
+					} else {
+						g.rootapi.Infos.License.Name = strings.TrimSpace(s[len("@License"):])
+					}
+				} else if strings.HasPrefix(s, "@LicenseUrl") {
+					if g.rootapi.Infos.License == nil {
+						g.rootapi.Infos.License = &swagger.License{URL: strings.TrimSpace(s[len("@LicenseUrl"):])}
+					} else {
+						g.rootapi.Infos.License.URL = strings.TrimSpace(s[len("@LicenseUrl"):])
+					}
+				} else if strings.HasPrefix(s, "@Schemes") {
+					g.rootapi.Schemes = strings.Split(strings.TrimSpace(s[len("@Schemes"):]), ",")
+				} else if strings.HasPrefix(s, "@Host") {
+					g.rootapi.Host = strings.TrimSpace(s[len("@Host"):])
+				} else if name, desc, ok := parseTagAnnotation(s); ok {
+					g.tagOverrides[name] = desc
+				} else if name, value, ok := g.parseExtension(s); ok {
+					g.docExtras.root[name] = value
+				}
+			}
+		}
+	}
+	// analisys controller package
+	for _, im := range f.Imports {
+		localName := ""
+		if im.Name != nil {
+			localName = im.Name.Name
+		}
+		g.analisyscontrollerPkg(localName, im.Path.Value)
+	}
+
+	for _, d := range f.Decls {
+		switch specDecl := d.(type) {
+		case *ast.FuncDecl:
+			for _, l := range specDecl.Body.List {
+				switch stmt := l.(type) {
+				case *ast.AssignStmt:
+					for _, l := range stmt.Rhs {
+						if v, ok := l.(*ast.CallExpr); ok {
+							// analisys NewNamespace, it will return version and the subfunction
+							if selName := v.Fun.(*ast.SelectorExpr).Sel.String(); selName != "NewNamespace" {
+								continue
+							}
+							version, params := analisysNewNamespace(v)
+							if g.rootapi.BasePath == "" && version != "" {
+								g.rootapi.BasePath = version
+							}
+							for _, p := range params {
+								switch pp := p.(type) {
+								case *ast.CallExpr:
+									controllerName := ""
+									if selname := pp.Fun.(*ast.SelectorExpr).Sel.String(); selname == "NSNamespace" {
+										s, params := analisysNewNamespace(pp)
+										for _, sp := range params {
+											switch pp := sp.(type) {
+											case *ast.CallExpr:
+												if pp.Fun.(*ast.SelectorExpr).Sel.String() == "NSInclude" {
+													controllerName = g.analisysNSInclude(s, pp)
+													if v, ok := g.controllerComments[controllerName]; ok {
+														g.rootapi.Tags = append(g.rootapi.Tags, swagger.Tag{
+															Name:        strings.Trim(s, "/"),
+															Description: v,
+														})
+													}
+													g.applyControllerExtensions(strings.Trim(s, "/"), controllerName)
+												}
+											}
+										}
+									} else if selname == "NSInclude" {
+										controllerName = g.analisysNSInclude("", pp)
+										if v, ok := g.controllerComments[controllerName]; ok {
+											g.rootapi.Tags = append(g.rootapi.Tags, swagger.Tag{
+												Name:        controllerName, // if the NSInclude has no prefix, we use the controllername as the tag
+												Description: v,
+											})
+										}
+										g.applyControllerExtensions(controllerName, controllerName)
+									}
+								}
+							}
+						}
+
+					}
+				}
+			}
+		}
+	}
+
+	err = g.generateChiDocs()
+	if err != nil {
+		g.warnf("Chi docs is not generated: %v", err)
+	}
+
+	g.applyTagOverrides()
+
+	g.warnSwaggerError(g.rootapi)
+}
+
+// return version and the others params
+func analisysNewNamespace(ce *ast.CallExpr) (first string, others []ast.Expr) {
+	for i, p := range ce.Args {
+		if i == 0 {
+			switch pp := p.(type) {
+			case *ast.BasicLit:
+				first = strings.Trim(pp.Value, `"`)
+			}
+			continue
+		}
+		others = append(others, p)
+	}
+	return
+}
+
+func (g *Generator) analisysNSInclude(baseurl string, ce *ast.CallExpr) string {
+	cname := ""
+	for _, p := range ce.Args {
+		x := p.(*ast.UnaryExpr).X.(*ast.CompositeLit).Type.(*ast.SelectorExpr)
+		if v, ok := g.importlist[fmt.Sprint(x.X)]; ok {
+			cname = v + x.Sel.Name
+		}
+		if apis, ok := g.controllerList[cname]; ok {
+			for rt, item := range apis {
+				tag := ""
+				if baseurl != "" {
+					rt = baseurl + rt
+					tag = strings.Trim(baseurl, "/")
+				} else {
+					tag = cname
+				}
+				if item.Get != nil {
+					item.Get.Tags = append(item.Get.Tags, tag)
+				}
+				if item.Post != nil {
+					item.Post.Tags = append(item.Post.Tags, tag)
+				}
+				if item.Put != nil {
+					item.Put.Tags = append(item.Put.Tags, tag)
+				}
+				if item.Patch != nil {
+					item.Patch.Tags = append(item.Patch.Tags, tag)
+				}
+				if item.Head != nil {
+					item.Head.Tags = append(item.Head.Tags, tag)
+				}
+				if item.Delete != nil {
+					item.Delete.Tags = append(item.Delete.Tags, tag)
+				}
+				if item.Options != nil {
+					item.Options.Tags = append(item.Options.Tags, tag)
+				}
+				if len(g.rootapi.Paths) == 0 {
+					g.rootapi.Paths = make(map[string]*swagger.Item)
+				}
+				rt, params := translateRoute(rt)
+				g.addRouteParameters(rt, item, params)
+				g.rootapi.Paths[rt] = item
+			}
+		}
+	}
+	return cname
+}
+
+func (g *Generator) analisyscontrollerPkg(localName, pkgpath string) {
+	g.checkContext()
+
+	pkgpath = strings.Trim(pkgpath, "\"")
+	if g.isSystemPackage(pkgpath) {
+		return
+	}
+	if pkgpath == "github.com/astaxie/beego" {
+		return
+	}
+	if localName != "" {
+		g.importlist[localName] = pkgpath
+	} else {
+		pps := strings.Split(pkgpath, "/")
+		g.importlist[pps[len(pps)-1]] = pkgpath
+	}
+
+	// Lets search for the beginning of package path in the project root
+	// directory. If found, replace the beginning of the package path
+	// in the root directory with the rest of the package path.
+	//
+	// root: /Users/foo/my/path/to/the/<project>
+	// pkgpath: github.com/<user>/<project>/pkg/server/handlers
+	//
+	// will become: /Users/foo/my/path/to/the/<project>/pkg/server/handlers
+	wd := g.dir
+
+	project, err := getProjectFromImportPath(pkgpath)
+	if err != nil {
+		return
+	}
+
+	if !strings.Contains(wd, project) {
+		// If we dont find the project in the cwd, lets not generate docs for it.
+		return
+	}
+
+	idx := strings.Index(pkgpath, project)
+	if idx < 0 {
+		g.failf("package path does not contain the project %q: %s", project, pkgpath)
+	}
+
+	// github.com/<user>/<project>/modules/foobar -> /modules/foobar
+	offset := idx + len(project)
+	fp := filepath.Join(wd, pkgpath[offset:])
+
+	pkgRealpath, _ := filepath.EvalSymlinks(fp)
+
+	if pkgRealpath != "" {
+		if _, ok := g.pkgCache[pkgpath]; ok {
+			return
+		}
+		g.pkgCache[pkgpath] = struct{}{}
+	} else {
+		g.failf("the %s pkg not exist in gopath", pkgpath)
+	}
+
+	astPkgs, err := g.getGoFilesInPackage(pkgRealpath)
+	if err != nil {
+		g.failf("the %s pkg parser.ParseDir error", pkgpath)
+	}
+
+	for _, pkg := range astPkgs {
+		if doc := packageDoc(pkg); doc != nil && g.packageDocs[pkgpath] == "" {
+			g.packageDocs[pkgpath] = godocText(doc)
+		}
+		for _, name := range sortedFileNames(pkg) {
+			fl := pkg.Files[name]
+			if fl.Doc != nil {
+				g.collectTagOverrides(fl.Doc)
+				// Package level extensions apply to the chi handlers,
+				// which have no controller.
+				if ext := g.collectExtensions(fl.Doc); ext != nil {
+					g.controllerExtensions[pkgpath] = ext
+				}
+			}
+
+			for _, d := range fl.Decls {
+				switch specDecl := d.(type) {
+				case *ast.FuncDecl:
+					// ControllerName can be empty for CHI.
+					var controllerName string
+					if specDecl.Recv != nil && len(specDecl.Recv.List) > 0 {
+						recv := specDecl.Recv.List[0]
+						t, ok := recv.Type.(*ast.StarExpr)
+						if !ok {
+							continue
+						}
+
+						controllerName = fmt.Sprint(t.X)
+					}
+
+					// parse controller method
+					g.parserComments(specDecl.Doc, specDecl.Name.String(), controllerName, pkgpath)
+				case *ast.GenDecl:
+					if specDecl.Tok == token.TYPE {
+						for _, s := range specDecl.Specs {
+							switch tp := s.(*ast.TypeSpec).Type.(type) {
+							case *ast.StructType:
+								_ = tp.Struct
+								//parse controller definition comments
+								g.collectTagOverrides(specDecl.Doc)
+								if ext := g.collectExtensions(specDecl.Doc); ext != nil {
+									g.controllerExtensions[pkgpath+s.(*ast.TypeSpec).Name.String()] = ext
+								}
+								if text := godocText(specDecl.Doc); text != "" {
+									g.controllerComments[pkgpath+s.(*ast.TypeSpec).Name.String()] = text + "\n"
+								}
+							}
+						}
+					}
+				}
+			}
+		}
+	}
+}
+
+func (g *Generator) isSystemPackage(pkgpath string) bool {
+	goroot := runtime.GOROOT()
+	if goroot == "" {
+		g.failf("goroot is empty, do you install Go right?")
+	}
+	wg, _ := filepath.EvalSymlinks(filepath.Join(goroot, "src", "pkg", pkgpath))
+	if utils.FileExists(wg) {
+		return true
+	}
+
+	//TODO(zh):support go1.4
+	wg, _ = filepath.EvalSymlinks(filepath.Join(goroot, "src", pkgpath))
+	if utils.FileExists(wg) {
+		return true
+	}
+
+	return false
+}
+
+func peekNextSplitString(ss string) (s string, spacePos int) {
+	spacePos = strings.IndexFunc(ss, unicode.IsSpace)
+	if spacePos < 0 {
+		s = ss
+		spacePos = len(ss)
+	} else {
+		s = strings.TrimSpace(ss[:spacePos])
+	}
+	return
+}
+
+// parse the func comments
+func (g *Generator) parserComments(comments *ast.CommentGroup, funcName, controllerName, pkgpath string) error {
+	var routerPath string
+	var httpMethod string
+	opts := swagger.Operation{
+		Responses: make(map[string]swagger.Response),
+	}
+	if comments != nil && comments.List != nil {
+		for _, c := range comments.List {
+			t := strings.TrimSpace(strings.TrimLeft(c.Text, "//"))
+			if strings.HasPrefix(t, "@router") {
+				elements := strings.TrimSpace(t[len("@router"):])
+				e1 := strings.SplitN(elements, " ", 2)
+				if len(e1) < 1 {
+					return errors.New("you should has router infomation")
+				}
+				routerPath = e1[0]
+				if len(e1) == 2 && e1[1] != "" {
+					e1 = strings.SplitN(e1[1], " ", 2)
+					httpMethod = strings.ToUpper(strings.Trim(e1[0], "[]"))
+				} else {
+					httpMethod = "GET"
+				}
+			} else if strings.HasPrefix(t, "@Title") {
+				opts.OperationID = controllerName + "." + strings.TrimSpace(t[len("@Title"):])
+			} else if strings.HasPrefix(t, "@Description") {
+				opts.Description = strings.TrimSpace(t[len("@Description"):])
+			} else if strings.HasPrefix(t, "@Summary") {
+				opts.Summary = strings.TrimSpace(t[len("@Summary"):])
+			} else if strings.HasPrefix(t, "@Success") {
+				ss := strings.TrimSpace(t[len("@Success"):])
+				rs := swagger.Response{}
+				respCode, pos := peekNextSplitString(ss)
+				ss = strings.TrimSpace(ss[pos:])
+				respType, pos := peekNextSplitString(ss)
+				if respType == "{object}" || respType == "{array}" {
+					isArray := respType == "{array}"
+					ss = strings.TrimSpace(ss[pos:])
+					schemaName, pos := peekNextSplitString(ss)
+					if schemaName == "" {
+						g.failf("[%s.%s] Schema must follow {object} or {array}", controllerName, funcName)
+					}
+					if strings.HasPrefix(schemaName, "[]") {
+						schemaName = schemaName[2:]
+						isArray = true
+					}
+					schema := swagger.Schema{}
+					if sType, ok := basicTypes[schemaName]; ok {
+						typeFormat := strings.Split(sType, ":")
+						schema.Type = typeFormat[0]
+						schema.Format = typeFormat[1]
+					} else {
+						m, mod, realTypes := g.getModel(schemaName)
+						schema.Ref = "#/definitions/" + m
+						g.modelsList[schemaName] = mod
+						g.appendModels(pkgpath, controllerName, realTypes)
+					}
+					if isArray {
+						rs.Schema = &swagger.Schema{
+							Type:  "array",
+							Items: &schema,
+						}
+					} else {
+						rs.Schema = &schema
+					}
+					rs.Description = strings.TrimSpace(schemaName + ss[pos:])
+				} else {
+					rs.Description = strings.TrimSpace(ss)
+				}
+				opts.Responses[respCode] = rs
+			} else if strings.HasPrefix(t, "@Param") {
+				para := swagger.Parameter{}
+				p := getparams(strings.TrimSpace(t[len("@Param "):]))
+				if len(p) < 4 {
+					g.failf("%s_%s's comments @Param at least should has 4 params", controllerName, funcName)
+				}
+				para.Name = p[0]
+				switch p[1] {
+				case "query":
+					fallthrough
+				case "header":
+					fallthrough
+				case "path":
+					fallthrough
+				case "formData":
+					fallthrough
+				case "body":
+					break
+				default:
+					g.warnf("[%s.%s] Unknow param location: %s, Possible values are `query`, `header`, `path`, `formData` or `body`.", controllerName, funcName, p[1])
+				}
+				para.In = p[1]
+				pp := strings.Split(p[2], ".")
+				typ := pp[len(pp)-1]
+				if len(pp) >= 2 {
+					m, mod, realTypes := g.getModel(p[2])
+					para.Schema = &swagger.Schema{
+						Ref: "#/definitions/" + m,
+					}
+					g.modelsList[typ] = mod
+					g.appendModels(pkgpath, controllerName, realTypes)
+				} else {
+					isArray := false
+					paraType := ""
+					paraFormat := ""
+					if strings.HasPrefix(typ, "[]") {
+						typ = typ[2:]
+						isArray = true
+					}
+
+					if typ == "string" || typ == "number" || typ == "integer" || typ == "boolean" ||
+						typ == "array" || typ == "file" {
+						paraType = typ
+					} else if sType, ok := basicTypes[typ]; ok {
+						typeFormat := strings.Split(sType, ":")
+						paraType = typeFormat[0]
+						paraFormat = typeFormat[1]
+					} else if typ == "enum" {
+						// enum type should always have sample values separated
+						// by comma (,) to be shown in swagger docs as a list
+						// of values.
+						if len(p) < 5 {
+							g.failf("enum should have sample values: %v", p)
+						}
+
+						paraType = "string"
+						para.Enum = strings.Split(p[4], ",")
+						if len(p) > 6 {
+							para.Default = p[5]
+						}
+					} else {
+						g.warnf("[%s.%s] Unknow param type: %s", controllerName, funcName, typ)
+					}
+
+					if isArray {
+						para.Type = "array"
+						para.Items = &swagger.ParameterItems{
+							Type:   paraType,
+							Format: paraFormat,
+						}
+					} else {
+						para.Type = paraType
+						para.Format = paraFormat
+					}
+				}
+
+				paraRequired, err := strconv.ParseBool(p[3])
+				if err != nil {
+					g.warnf("invalid value on 'required' field (%s)", p)
+				}
+				para.Required = paraRequired
+				para.Description = strings.Trim(p[len(p)-1], `" `)
+				opts.Parameters = append(opts.Parameters, para)
+			} else if strings.HasPrefix(t, "@Failure") {
+				rs := swagger.Response{}
+				st := strings.TrimSpace(t[len("@Failure"):])
+				var cd []rune
+				var start bool
+				for i, s := range st {
+					if unicode.IsSpace(s) {
+						if start {
+							rs.Description = strings.TrimSpace(st[i+1:])
+							break
+						} else {
+							continue
+						}
+					}
+					start = true
+					cd = append(cd, s)
+				}
+				opts.Responses[string(cd)] = rs
+			} else if strings.HasPrefix(t, "@Deprecated") {
+				g.parseDeprecated(&opts, t[len("@Deprecated"):], controllerName, funcName)
+			} else if strings.HasPrefix(t, "@Extension") {
+				if name, value, ok := g.parseExtension(t); ok {
+					g.docExtras.setOperation(&opts, name, value)
+				}
+			} else if strings.HasPrefix(t, "@Accept") {
+				accepts := strings.Split(strings.TrimSpace(strings.TrimSpace(t[len("@Accept"):])), ",")
+				for _, a := range accepts {
+					opts.Consumes = append(opts.Consumes, consumes(a)...)
+				}
+			}
+		}
+	}
+	if routerPath == "" {
+		return nil
+	}
+
+	applyGodoc(&opts, comments)
+	g.handlers = append(g.handlers, handlerDoc{
+		pkgpath:    pkgpath,
+		controller: controllerName,
+		function:   funcName,
+		method:     httpMethod,
+		path:       routerPath,
+		chi:        g.isCHI(pkgpath),
+		op:         opts,
+	})
+
+	if g.isCHI(pkgpath) {
+		item, ok := g.chiAPIs[routerPath]
+		if !ok {
+			item = &swagger.Item{}
+		}
+
+		enrichSwaggerItem(item, &opts, httpMethod)
+		g.chiAPIs[routerPath] = item
+		g.chiAPIPackages[routerPath] = pkgpath
+		return nil
+	}
+
+	controllerKey := pkgpath + controllerName
+	itemList, ok := g.controllerList[controllerKey]
+	if !ok {
+		g.controllerList[controllerKey] = make(map[string]*swagger.Item)
+	}
+
+	item, ok := itemList[routerPath]
+	if !ok {
+		item = &swagger.Item{}
+	}
+
+	enrichSwaggerItem(item, &opts, httpMethod)
+	g.controllerList[pkgpath+controllerName][routerPath] = item
+
+	return nil
+}
+
+func consumes(accept string) []string {
+	switch accept {
+	case "json":
+		return []string{ajson}
+	case "xml":
+		return []string{axml}
+	case "plain":
+		return []string{aplain}
+	case "html":
+		return []string{ahtml}
+	case "thrift_binary":
+		return []string{content_type_thrift_binary}
+	case "thrift_json":
+		return []string{content_type_thrift_json}
+	case "thrift_webcontent_binary":
+		return []string{content_type_thrift_binary_webcontent_v1}
+	case "thrift_webcontent_json":
+		return []string{content_type_thrift_json_webcontent_v1}
+	case "form", "multipart/form-data":
+		return []string{contentTypeMultipartFormData}
+	case "application/x-www-form-urlencoded":
+		return []string{contentTypeFormUrlencoded}
+	}
+
+	return []string{}
+}
+
+func enrichSwaggerItem(item *swagger.Item, opts *swagger.Operation, httpMethod string) {
+	switch httpMethod {
+	case http.MethodGet:
+		item.Get = opts
+	case http.MethodPost:
+		item.Post = opts
+	case http.MethodPut:
+		item.Put = opts
+	case http.MethodPatch:
+		item.Patch = opts
+	case http.MethodDelete:
+		item.Delete = opts
+	case http.MethodHead:
+		item.Head = opts
+	case http.MethodOptions:
+		item.Options = opts
+	}
+}
+
+// analisys params return []string
+// @Param	query		form	 string	true		"The email for login"
+// [query form string true "The email for login"]
+func getparams(str string) []string {
+	var s []rune
+	var j int
+	var start bool
+	var r []string
+	for i, c := range []rune(str) {
+		if len([]rune(str))-1 == i && start {
+			s = append(s, c)
+			r = append(r, string(s))
+		}
+		if unicode.IsSpace(c) {
+			if !start {
+				continue
+			} else {
+				start = false
+				j++
+				r = append(r, string(s))
+				s = make([]rune, 0)
+				continue
+			}
+		}
+		if c == '"' {
+			r = append(r, strings.TrimSpace((str[i:])))
+			break
+		}
+		start = true
+		s = append(s, c)
+	}
+	return r
+}
+
+func (g *Generator) getModel(str string) (objectname string, m swagger.Schema, realTypes []string) {
+	strs := strings.Split(str, ".")
+	objectname = strs[len(strs)-1]
+	pkgpath := strings.Join(strs[:len(strs)-1], "/")
+	pkgRealpath := path.Join(g.dir, pkgpath)
+	astPkgs, err := g.getGoFilesInPackage(pkgRealpath)
+	if err != nil {
+		g.failf("the model %s parser.ParseDir error", str)
+	}
+
+	m.Type = "object"
+	var packageName string
+	for _, pkg := range astPkgs {
+		for _, fl := range pkg.Files {
+			for k, d := range fl.Scope.Objects {
+				if d.Kind == ast.Typ {
+					if k != objectname {
+						continue
+					}
+
+					pathInfo, err := g.generatePathInfo(fl)
+					if err != nil {
+						g.failf("failed when generating path info: %v", err)
+					}
+
+					packageName = pkg.Name
+					pathInfo[packageName] = pkgpath
+					res := &objectResource{
+						g:           g,
+						object:      d,
+						doc:         typeSpecDoc(fl, d.Decl),
+						schema:      &m,
+						realTypes:   &realTypes,
+						astPkgs:     astPkgs,
+						packageName: packageName,
+						pathInfo:    pathInfo,
+					}
+					res.parse()
+				}
+			}
+		}
+	}
+	if m.Title == "" {
+		g.warnf("can't find the object: %s", str)
+		// TODO remove when all type have been supported
+		//os.Exit(1)
+	}
+	if len(g.rootapi.Definitions) == 0 {
+		g.rootapi.Definitions = make(map[string]swagger.Schema)
+	}
+	objectname = objectWithPackageName(objectname, packageName)
+	g.rootapi.Definitions[objectname] = m
+	return
+}
+
+type objectResource struct {
+	g           *Generator
+	object      *ast.Object
+	doc         *ast.CommentGroup
+	schema      *swagger.Schema
+	realTypes   *[]string
+	astPkgs     map[string]*ast.Package
+	packageName string
+	pathInfo    map[string]string
+}
+
+func (res *objectResource) parse() {
+	ts, ok := res.object.Decl.(*ast.TypeSpec)
+	if !ok {
+		res.g.failf("Unknown type without TypeSec: %v", res.object)
+	}
+
+	switch t := ts.Type.(type) {
+	case *ast.Ident:
+		res.schema.Title = res.object.Name
+		propertie := res.g.constructObjectPropertie(
+			ts.Type, res.packageName, res.realTypes, res.pathInfo,
+		)
+		res.schema.Properties = propertie.Properties
+		res.schema.Type = propertie.Type
+		res.schema.Format = propertie.Format
+	case *ast.StructType:
+		res.schema.Title = res.object.Name
+		if t.Fields.List != nil {
+			res.schema.Properties = make(map[string]swagger.Propertie)
+			for _, field := range t.Fields.List {
+				mp := res.g.constructObjectPropertie(field.Type, res.packageName, res.realTypes, res.pathInfo)
+				if field.Names == nil {
+					for _, pkg := range res.astPkgs {
+						for _, fl := range pkg.Files {
+							for _, obj := range fl.Scope.Objects {
+								if obj.Name == fmt.Sprint(field.Type) {
+									res := &objectResource{
+										g:           res.g,
+										object:      obj,
+										schema:      res.schema,
+										realTypes:   res.realTypes,
+										astPkgs:     res.astPkgs,
+										packageName: pkg.Name,
+									}
+									res.parse()
+								}
+							}
+						}
+					}
+					continue
+				}
+
+				// if no tag found skip tag processing
+				if field.Tag == nil {
+					name := field.Names[0].Name
+					res.schema.Properties[name] = mp
+					continue
+				}
+
+				name := fieldNameFromTag(field.Tag.Value)
+				if name == "" {
+					name = field.Names[0].Name
+				}
+
+				setSchemaProperties(res.schema, mp, field.Tag.Value, name)
+			}
+		}
+	case *ast.InterfaceType:
+		res.schema.Title = res.object.Name
+		res.schema.Type = "object"
+		res.parseOneOf()
+	default:
+		res.g.warnf("%v type is not supported yet", t)
+	}
+}
+
+// typeSpecDoc returns the doc comment of a type declaration. For
+// ungrouped declarations the parser attaches it to the GenDecl.
+func typeSpecDoc(file *ast.File, decl interface{}) *ast.CommentGroup {
+	ts, ok := decl.(*ast.TypeSpec)
+	if !ok {
+		return nil
+	}
+	if ts.Doc != nil {
+		return ts.Doc
+	}
+	for _, d := range file.Decls {
+		gd, ok := d.(*ast.GenDecl)
+		if !ok {
+			continue
+		}
+		for _, spec := range gd.Specs {
+			if spec == ts {
+				return gd.Doc
+			}
+		}
+	}
+	return nil
+}
+
+// objectWithPackageName returns an object with package name in format
+// `packageName.Object`. There are two types of object that can be identified
+// by ast, the same-package and imported objects.
+// The imported object comes with `&{PackageName Object}` format and
+// the internal object comes with `object` format. In the latter
+// case we need to assign the current packageName to the object.
+func objectWithPackageName(object, packageName string) string {
+	if len(strings.Split(object, " ")) > 1 {
+		object = strings.ReplaceAll(object, " ", ".")
+		object = strings.ReplaceAll(object, "&", "")
+		object = strings.ReplaceAll(object, "{", "")
+		object = strings.ReplaceAll(object, "}", "")
+
+		return object
+	}
+
+	if packageName == "" {
+		return object
+	}
+
+	return packageName + "." + object
+}
+
+// constructObjectPropertie constructs a swagger.Propertie out of
+// an ast.Expr. This function recursively traverse all expression
+// until it reaches one of object or pre-defined basic golang type / primitive.
+func (g *Generator) constructObjectPropertie(field ast.Expr, packageName string, realTypes *[]string, pathInfo map[string]string) swagger.Propertie {
+	var propertie swagger.Propertie
+
+	// check if there is a basic-type disguised as an object
+	object := objectWithPackageName(fmt.Sprint(field), "")
+
+	// basic Go types (primitives) can be directly translated into
+	// swagger-supported type with pre-defined mapping.
+	if basicType, ok := basicTypes[object]; ok {
+		propInfo := strings.Split(basicType, ":")
+
+		if len(propInfo) != 2 {
+			g.warnf("basicTypes const is not properly configured for %v", field)
+			return propertie
+		}
+
+		propertie.Type = propInfo[0]
+		propertie.Format = propInfo[1]
+
+		return propertie
+	}
+
+	switch f := field.(type) {
+	case *ast.StarExpr:
+		// Star Expression is a pointer object. The star expression can be
+		// defined in swagger doc as a reference, which its definition will
+		// later be appended in the definition list and traversed further
+		// via `appendModels` function.
+		// Star Expression example: *Wishlist
+		object := fmt.Sprint(f.X)
+		pkgObject := objectWithPackageName(object, packageName)
+		propertie.Ref = "#/definitions/" + pkgObject
+
+		// append object to realTypes to be traversed further by appendModels
+		// function.
+		g.appendObjectToRealTypes(realTypes, pkgObject, pathInfo)
+		return propertie
+	case *ast.ArrayType:
+		// Array Type is an array which can be directly stated to swagger doc
+		// type. But, the object of the array must be traversed further to know
+		// what the actual type is.
+		// Array Type example: []*int
+		object := g.constructObjectPropertie(f.Elt, packageName, realTypes, pathInfo)
+		propertie.Type = "array"
+		propertie.Items = &object
+		return propertie
+	case *ast.MapType:
+		// Map Type is a map/dictionary of other object. Map Type can be stated
+		// in swagger doc as type `object` and the value of the map can be
+		// positioned in the `AdditionalProperties` field of swagger.Propertie.
+		// Swagger Doc only support string as the map key.
+		// Map Type example: map[string]Product
+		object := g.constructObjectPropertie(f.Value, packageName, realTypes, pathInfo)
+		propertie.Type = "object"
+		propertie.AdditionalProperties = &object
+		return propertie
+	case *ast.Ident:
+		// Type Identity is a type alias of another type. To handle type
+		// identity, the aliased type must be traversed until it reaches a
+		// primitive. Pointers to struct of a slice is also
+		// identified as Type Identity.
+		// Type Identity example:
+		// - type myOwnCatalogID int
+		// - []*Wishlist // *Wishlist is identified as type identity for the
+		// Wishlist struct
+
+		if f.Obj == nil {
+			// Declared in another file of the package, which resolves when
+			// the model is traversed.
+			pkgObject := objectWithPackageName(f.Name, packageName)
+			propertie.Ref = "#/definitions/" + pkgObject
+			g.appendObjectToRealTypes(realTypes, pkgObject, pathInfo)
+			return propertie
+		}
+
+		v, ok := f.Obj.Decl.(*ast.TypeSpec)
+		if !ok {
+			g.warnf("Unknown type without TypeSpec: %v", field)
+			return propertie
+		}
+
+		// Create a definition for struct and interface types so it can be
+		// used by another type that needs it.
+		switch v.Type.(type) {
+		case *ast.StructType, *ast.InterfaceType:
+			object := fmt.Sprint(field)
+			pkgObject := objectWithPackageName(object, packageName)
+			propertie.Ref = "#/definitions/" + pkgObject
+			g.appendObjectToRealTypes(realTypes, pkgObject, pathInfo)
+			return propertie
+		}
+
+		// Construct the aliased type
+		return g.constructObjectPropertie(v.Type, packageName, realTypes, pathInfo)
+	case *ast.InterfaceType:
+		// An anonymous interface, e.g. interface{}, can hold any value which
+		// is stated in swagger doc as an empty schema.
+		return propertie
+	case *ast.StructType:
+		propertie.Properties = make(map[string]swagger.Propertie)
+		for _, v := range f.Fields.List {
+			fieldPropertie := g.constructObjectPropertie(
+				v.Type, packageName, realTypes, pathInfo,
+			)
+
+			if len(v.Names) == 0 {
+				g.warnf("%v: Unnamed struct field is currently not supported", v.Type)
+				continue
+			}
+
+			// if no tag is found, skip tag processing.
+			if v.Tag == nil {
+				name := v.Names[0].Name
+				propertie.Properties[name] = fieldPropertie
+				continue
+			}
+
+			name := fieldNameFromTag(v.Tag.Value)
+			if name == "" {
+				name = v.Names[0].Name
+			}
+
+			propertie.Properties[name] = fieldPropertie
+		}
+
+		propertie.Type = "object"
+		return propertie
+	case *ast.SelectorExpr:
+		// Selector Expression is an object that's located in external package.
+		// The object can be stated as ref in swagger.Propertie Ref field and
+		// then will be expanded and stated in swagger `definitions` from
+		// `appendModels` function.
+		object := fmt.Sprint(f)
+		pkgObject := objectWithPackageName(object, packageName)
+		propertie.Ref = "#/definitions/" + pkgObject
+		g.appendObjectToRealTypes(realTypes, pkgObject, pathInfo)
+		return propertie
+	}
+
+	pkgObject := objectWithPackageName(object, packageName)
+	propertie.Ref = "#/definitions/" + pkgObject
+	g.appendObjectToRealTypes(realTypes, pkgObject, pathInfo)
+
+	return propertie
+}
+
+// appendObjectToRealTypes appends an object with its full path
+// from the root package to *realTypes array.
+func (g *Generator) appendObjectToRealTypes(realTypes *[]string, pkgObject string, pathInfo map[string]string) {
+	if !strings.Contains(pkgObject, ".") {
+		*realTypes = append(*realTypes, pkgObject)
+		return
+	}
+
+	pkgObjectSplit := strings.Split(pkgObject, ".")
+
+	if len(pkgObjectSplit) != 2 {
+		g.warnf("%v pkgObject passed to realTypes length should be 2", pkgObjectSplit)
+		return
+	}
+
+	pkg := pkgObjectSplit[0]
+	object := pkgObjectSplit[1]
+
+	realType := pkgObject
+	if v, ok := pathInfo[pkg]; ok && v != "" {
+		realType = v + "." + object
+	}
+
+	realType = strings.Trim(realType, "/")
+	realType = strings.ReplaceAll(realType, "/", ".")
+	*realTypes = append(*realTypes, realType)
+}
+
+func isBasicType(Type string) bool {
+	if _, ok := basicTypes[Type]; ok {
+		return true
+	}
+	return false
+}
+
+// refer to builtin.go
+var basicTypes = map[string]string{
+	"bool":        "boolean:",
+	"uint":        "integer:int32",
+	"uint8":       "integer:int32",
+	"uint16":      "integer:int32",
+	"uint32":      "integer:int32",
+	"uint64":      "integer:int64",
+	"int":         "integer:int64",
+	"int8":        "integer:int32",
+	"int16":       "integer:int32",
+	"int16:int32": "integer:int32",
+	"int32":       "integer:int32",
+	"int64":       "integer:int64",
+	"uintptr":     "integer:int64",
+	"float32":     "number:float",
+	"float64":     "number:double",
+	"string":      "string:",
+	"complex64":   "number:float",
+	"complex128":  "number:double",
+	"byte":        "string:byte",
+	"rune":        "string:byte",
+	"time.Time":   "string:datetime",
+}
+
+// regexp get json tag
+func grepJSONTag(tag string) string {
+	r, _ := regexp.Compile(`json:"([^"]*)"`)
+	matches := r.FindAllStringSubmatch(tag, -1)
+	if len(matches) > 0 {
+		return matches[0][1]
+	}
+	return ""
+}
+
+// append models
+func (g *Generator) appendModels(pkgpath, controllerName string, realTypes []string) {
+	for _, realType := range realTypes {
+		if _, ok := g.modelsList[realType]; ok {
+			continue
+		}
+		_, mod, newRealTypes := g.getModel(realType)
+		g.modelsList[realType] = mod
+		g.appendModels(pkgpath, controllerName, newRealTypes)
+	}
+}
+
+// routeParam is a path parameter declared by a route pattern.
+type routeParam struct {
+	Name     string
+	Type     string
+	Format   string
+	Pattern  string
+	Optional bool
+}
+
+// urlReplace turns a beego or chi route pattern into a swagger path
+// template, dropping the type and regex parts of its parameters.
+func urlReplace(src string) string {
+	rt, _ := translateRoute(src)
+	return rt
+}
+
+// translateRoute turns a beego or chi route pattern into a swagger path
+// template and returns the path parameters it declares. Supported forms:
+//
+//	beego: /:id  /?:id  /:id:int  /:name:string  /:id([0-9]+)  /*  /*.*
+//	       /cms_:id([0-9]+).html
+//	chi:   /{id}  /{id:[0-9]+}  /*
+func translateRoute(src string) (string, []routeParam) {
+	var params []routeParam
+	pt := strings.Split(src, "/")
+	for i, p := range pt {
+		var segParams []routeParam
+		switch {
+		case p == "*":
+			pt[i] = "{splat}"
+			segParams = []routeParam{{Name: "splat", Type: "string"}}
+		case p == "*.*":
+			pt[i] = "{path}.{ext}"
+			segParams = []routeParam{
+				{Name: "path", Type: "string"},
+				{Name: "ext", Type: "string"},
+			}
+		case isChiSegment(p):
+			pt[i], segParams = translateChiSegment(p)
+		case strings.Contains(p, ":"):
+			pt[i], segParams = translateBeegoSegment(p)
+		}
+		params = append(params, segParams...)
+	}
+	return strings.Join(pt, "/"), params
+}
+
+// isChiSegment reports whether seg uses chi placeholders. Beego regexes may
+// contain braces too, so whichever of `{` and `:` comes first wins.
+func isChiSegment(seg string) bool {
+	brace := strings.Index(seg, "{")
+	if brace < 0 {
+		return false
+	}
+	colon := strings.Index(seg, ":")
+	return colon < 0 || brace < colon
+}
+
+// translateChiSegment translates `{name}` and `{name:regex}` placeholders.
+func translateChiSegment(seg string) (string, []routeParam) {
+	var out strings.Builder
+	var params []routeParam
+	for i := 0; i < len(seg); i++ {
+		if seg[i] != '{' {
+			out.WriteByte(seg[i])
+			continue
+		}
+
+		// Find the matching brace, regexes may contain quantifiers
+		// like {2,3}.
+		depth, end := 0, -1
+		for j := i; j < len(seg); j++ {
+			if seg[j] == '{' {
+				depth++
+			} else if seg[j] == '}' {
+				depth--
+				if depth == 0 {
+					end = j
+					break
+				}
+			}
+		}
+		if end < 0 {
+			out.WriteString(seg[i:])
+			break
+		}
+
+		param := routeParam{Type: "string"}
+		body := seg[i+1 : end]
+		if idx := strings.Index(body, ":"); idx >= 0 {
+			param.Name = body[:idx]
+			setParamRegex(&param, body[idx+1:])
+		} else {
+			param.Name = body
+		}
+		params = append(params, param)
+		out.WriteString("{" + param.Name + "}")
+		i = end
+	}
+	return out.String(), params
+}
+
+// translateBeegoSegment translates `:name`, `?:name`, `:name:int`,
+// `:name:string` and `:name(regex)` placeholders.
+func translateBeegoSegment(seg string) (string, []routeParam) {
+	var out strings.Builder
+	var params []routeParam
+	for i := 0; i < len(seg); i++ {
+		optional := false
+		if seg[i] == '?' && i+1 < len(seg) && seg[i+1] == ':' {
+			optional = true
+			i++
+		}
+		if seg[i] != ':' {
+			out.WriteByte(seg[i])
+			continue
+		}
+
+		j := i + 1
+		for j < len(seg) && isIdentByte(seg[j]) {
+			j++
+		}
+		if j == i+1 {
+			out.WriteByte(seg[i])
+			continue
+		}
+
+		param := routeParam{Name: seg[i+1 : j], Type: "string", Optional: optional}
+		switch {
+		case strings.HasPrefix(seg[j:], ":int"):
+			param.Type = "integer"
+			param.Format = "int64"
+			j += len(":int")
+		case strings.HasPrefix(seg[j:], ":string"):
+			param.Pattern = `^[\w]+$`
+			j += len(":string")
+		case j < len(seg) && seg[j] == '(':
+			depth, end := 0, -1
+			for k := j; k < len(seg); k++ {
+				if seg[k] == '\\' {
+					k++
+					continue
+				}
+				if seg[k] == '(' {
+					depth++
+				} else if seg[k] == ')' {
+					depth--
+					if depth == 0 {
+						end = k
+						break
+					}
+				}
+			}
+			if end > 0 {
+				setParamRegex(&param, seg[j+1:end])
+				j = end + 1
+			}
+		}
+
+		params = append(params, param)
+		out.WriteString("{" + param.Name + "}")
+		i = j - 1
+	}
+	return out.String(), params
+}
+
+func isIdentByte(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
+
+var integerRegexps = []string{`[0-9]+`, `\d+`, `[0-9]*`, `\d*`}
+
+// setParamRegex sets the pattern of param, narrowing its type to integer
+// when the regex only matches digits.
+func setParamRegex(param *routeParam, regex string) {
+	for _, r := range integerRegexps {
+		if regex == r {
+			param.Type = "integer"
+			param.Format = "int64"
+			return
+		}
+	}
+	param.Pattern = "^" + regex + "$"
+}
+
+// addRouteParameters makes sure every operation of item documents the path
+// parameters declared by its route. Parameters described with @Param keep
+// their description but get the type and pattern of the route when they
+// have none. @Param path entries that are not part of the route are
+// reported and left out, as the spec would be invalid with them.
+func (g *Generator) addRouteParameters(rt string, item *swagger.Item, params []routeParam) {
+	for method, op := range itemOperations(item) {
+		declared := make(map[string]bool)
+		for _, p := range params {
+			declared[p.Name] = true
+		}
+
+		kept := op.Parameters[:0]
+		for _, para := range op.Parameters {
+			if para.In == "path" && !declared[para.Name] {
+				g.warnf("@Param path %q is not part of route %s '%s', left it out", para.Name, strings.ToUpper(method), rt)
+				continue
+			}
+			kept = append(kept, para)
+		}
+		op.Parameters = kept
+
+		found := make(map[string]bool)
+		for i := range op.Parameters {
+			para := &op.Parameters[i]
+			if para.In != "path" {
+				continue
+			}
+			found[para.Name] = true
+			para.Required = true
+			for _, p := range params {
+				if p.Name != para.Name {
+					continue
+				}
+				if para.Type == "" && para.Schema == nil {
+					para.Type = p.Type
+					para.Format = p.Format
+				}
+				if p.Pattern != "" {
+					g.docExtras.setParameter(op, "path", p.Name, "pattern", p.Pattern)
+				}
+			}
+		}
+
+		for _, p := range params {
+			if found[p.Name] {
+				continue
+			}
+			found[p.Name] = true
+			para := swagger.Parameter{
+				In:       "path",
+				Name:     p.Name,
+				Type:     p.Type,
+				Format:   p.Format,
+				Required: true,
+			}
+			if p.Optional {
+				para.Description = "optional path segment"
+			}
+			op.Parameters = append(op.Parameters, para)
+			if p.Pattern != "" {
+				g.docExtras.setParameter(op, "path", p.Name, "pattern", p.Pattern)
+			}
+		}
+	}
+}
+
+// fieldNameFromTag processes a tag attached to a struct field to get the
+// respective name according to its encoding (thrift/json).  if a tag explicitly
+// wants to be ignored then the name returned will be an empty string.
+func fieldNameFromTag(tag string) string {
+	structTag := reflect.StructTag(strings.Trim(tag, "`"))
+
+	// skip ignored field.
+	if ignore := structTag.Get("ignore"); ignore != "" {
+		return ""
+	}
+
+	// set json tag name as field name.
+	jsonTag := structTag.Get("json")
+	jsonTagValues := strings.Split(jsonTag, ",")
+
+	// skip property with `-` tag.
+	if len(jsonTagValues) > 0 && jsonTagValues[0] == "-" {
+		return ""
+	}
+
+	var name string
+	if len(jsonTagValues) > 0 && jsonTagValues[0] != "omitempty" {
+		name = jsonTagValues[0]
+	}
+
+	// overwrite with thrift tag name if any.
+	thriftTag := structTag.Get("thrift")
+	thriftTagValues := strings.Split(thriftTag, ",")
+	if len(thriftTagValues) > 0 && thriftTagValues[0] != "" {
+		name = thriftTagValues[0]
+	}
+
+	return name
+}
+
+func setSchemaProperties(schema *swagger.Schema, fieldPropertie swagger.Propertie, tag, name string) {
+	structTag := reflect.StructTag(strings.Trim(tag, "`"))
+
+	if required := structTag.Get("required"); required != "" {
+		schema.Required = append(schema.Required, name)
+	}
+
+	if desc := structTag.Get("description"); desc != "" {
+		fieldPropertie.Description = desc
+	}
+
+	if example := structTag.Get("example"); example != "" {
+		fieldPropertie.Example = example
+	}
+
+	schema.Properties[name] = fieldPropertie
+}
+
+func contains(slice []string, val string) bool {
+	for _, item := range slice {
+		if item == val {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (g *Generator) warnSwaggerError(swaggerDoc swagger.Swagger) {
+	for path, item := range swaggerDoc.Paths {
+		if item == nil {
+			continue
+		}
+		g.validateSwaggerOperation(path, "GET", item.Get)
+		g.validateSwaggerOperation(path, "PUT", item.Put)
+		g.validateSwaggerOperation(path, "POST", item.Post)
+		g.validateSwaggerOperation(path, "DELETE", item.Delete)
+		g.validateSwaggerOperation(path, "OPTIONS", item.Options)
+		g.validateSwaggerOperation(path, "HEAD", item.Head)
+		g.validateSwaggerOperation(path, "PATCH", item.Patch)
+	}
+}
+
+func (g *Generator) validateSwaggerOperation(path, method string, methodOp *swagger.Operation) {
+	// The passed HTTP Method does not exist in the endpoint.
+	if methodOp == nil {
+		return
+	}
+
+	if len(methodOp.Responses) == 0 {
+		g.warnf("missing response [@Success, @Failure] for route %s '%s'", method, path)
+	}
+
+	for status, response := range methodOp.Responses {
+		if response.Description == "" {
+			g.warnf("missing description from '%s' Response for route %s '%s'", status, method, path)
+		}
+	}
+
+	if sunset, ok := g.docExtras.operations[methodOp]["x-sunset"].(string); ok && isPastSunset(sunset) {
+		g.warnf("route %s '%s' is past its sunset date %s", method, path, sunset)
+	}
+
+	for _, param := range methodOp.Parameters {
+		if len(param.Enum) == 0 || param.Default == "" {
+			continue
+		}
+
+		if !contains(param.Enum, param.Default) {
+			g.warnf("default value must be present in Enum parameter for route %s '%s'", method, path)
+		}
+	}
+}
+
+// generatePathInfo generates all imported packages in a file into a map.
+// the name of the package will be used as the map key, and the path
+// to the package will be used as the map value.
+func (g *Generator) generatePathInfo(file *ast.File) (map[string]string, error) {
+	pathInfo := make(map[string]string)
+
+	basePath, err := g.getPackageName()
+	if err != nil {
+		return pathInfo, err
+	}
+
+	// iterate through all imported packages in a file
+	// then create a package -> path dictionary out of it.
+	var importPath string
+	for _, v := range file.Imports {
+
+		// skip if the importPath is from external (outside org) package.
+		importPath = strings.Trim(v.Path.Value, "\"")
+		if !strings.HasPrefix(importPath, basePath) {
+			continue
+		}
+
+		importPath = strings.ReplaceAll(importPath, basePath, "")
+
+		// if the imported package is named, then use the name for the key.
+		// eg. rvsdk "github.com/zalora/revery-sdk-go/revery" ->
+		// map["rvsdk] = "github.com/zalora/revery-sdk-go/revery"
+		if v.Name != nil {
+			pathInfo[v.Name.Name] = importPath
+			continue
+		}
+
+		// for unnamed imported package.
+		// eg. "github.com/zalora/gfg-sdk-go/gfg" ->
+		// map["gfg"] = "github.com/zalora/gfg-sdk-go/gfg"
+		packageNames := strings.Split(importPath, "/")
+		name := packageNames[len(packageNames)-1]
+		pathInfo[name] = importPath
+	}
+
+	return pathInfo, nil
+}
+
+func (g *Generator) getGoFilesInPackage(pkg string) (map[string]*ast.Package, error) {
+	if g.isPackageIgnored(pkg) {
+		return nil, nil
+	}
+
+	fileSet := token.NewFileSet()
+	astPkgs, err := parser.ParseDir(fileSet, pkg, func(info os.FileInfo) bool {
+		name := info.Name()
+		return !info.IsDir() &&
+			strings.HasSuffix(name, ".go") &&
+			!strings.HasPrefix(name, ".")
+	}, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	return astPkgs, nil
+}
+
+func (g *Generator) isPackageIgnored(pkg string) bool {
+	if g.dir == "" {
+		return false
+	}
+	handlersPath := path.Join(g.dir, "/handlers")
+
+	if strings.HasPrefix(pkg, handlersPath) {
+		return true
+	}
+
+	return false
+}
+
+func (g *Generator) getPackageName() (string, error) {
+	// Go modules :)
+	f, err := os.ReadFile(filepath.Join(g.dir, modfileName))
+	if err == nil {
+		mf, err := modfile.Parse(modfileName, f, nil)
+		if err == nil {
+			return mf.Module.Mod.Path, nil
+		}
+	}
+
+	// Gopath :(
+	gopathSRC := os.Getenv("GOPATH") + "/src/"
+
+	return strings.ReplaceAll(g.dir, gopathSRC, ""), nil
+}
+
+// getProjectFromImportPath extracts the project name from the import path.
+// Assumption: package path has always the form github.com/<user>/<project>
+func getProjectFromImportPath(path string) (string, error) {
+	pathParts := strings.Split(path, string(os.PathSeparator))
+	if len(pathParts) < 3 {
+		return "", fmt.Errorf("unrecognized import path form: %s", path)
+	}
+
+	return pathParts[2], nil
+}
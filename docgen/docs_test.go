@@ -0,0 +1,680 @@
+package docgen
+
+import (
+	"context"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/astaxie/beego/swagger"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConsumes(t *testing.T) {
+	tests := []struct {
+		desc     string
+		a        string
+		expected []string
+	}{
+		{
+			desc:     "accept json, returns json",
+			a:        "json",
+			expected: []string{ajson},
+		},
+		{
+			desc:     "accept xml, returns xml",
+			a:        "xml",
+			expected: []string{axml},
+		},
+		{
+			desc:     "accept plain, returns plain",
+			a:        "plain",
+			expected: []string{aplain},
+		},
+		{
+			desc:     "accept html, returns html",
+			a:        "html",
+			expected: []string{ahtml},
+		},
+		{
+			desc:     "accept thrift_binary, returns thrift binary",
+			a:        "thrift_binary",
+			expected: []string{content_type_thrift_binary},
+		},
+		{
+			desc:     "accept thrift_json, returns thrift json",
+			a:        "thrift_json",
+			expected: []string{content_type_thrift_json},
+		},
+		{
+			desc:     "accept thrift_webcontent_binary, returns thrift web content binary",
+			a:        "thrift_webcontent_binary",
+			expected: []string{content_type_thrift_binary_webcontent_v1},
+		},
+		{
+			desc:     "accept thrift_webcontent_json, returns thrift web content json",
+			a:        "thrift_webcontent_json",
+			expected: []string{content_type_thrift_json_webcontent_v1},
+		},
+		{
+			desc:     "accept multipart, returns multipart/form-data",
+			a:        "form",
+			expected: []string{contentTypeMultipartFormData},
+		},
+		{
+			desc:     "unknown accept, returns empty slice",
+			a:        "skippy-chunky",
+			expected: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			actual := consumes(tt.a)
+			assert.Equal(t, tt.expected, actual)
+		})
+	}
+}
+
+func TestGetGoFilesInPackage(t *testing.T) {
+	tests := []struct {
+		desc     string
+		pkg      string
+		dir      string
+		expected map[string]*ast.Package
+		isError  assert.ErrorAssertionFunc
+	}{
+		{
+			desc: "Test package is not ignored, return list of parsed go files",
+			pkg:  "../testdata/router",
+			dir:  "/a/test/root/package",
+			expected: func() map[string]*ast.Package {
+				pkg := "../testdata/router"
+				pkgs, err := parser.
+					ParseDir(token.NewFileSet(), pkg, func(info os.FileInfo) bool {
+						return true
+					}, parser.ParseComments)
+
+				assert.NoError(t, err)
+
+				return pkgs
+			}(),
+			isError: assert.NoError,
+		},
+		{
+			desc:     "Test package is ignored, returns nil without error",
+			pkg:      "/a/test/root/package/handlers",
+			dir:      "/a/test/root/package",
+			expected: nil,
+			isError:  assert.NoError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			g := &Generator{dir: tt.dir}
+			actual, err := g.getGoFilesInPackage(tt.pkg)
+			assert.Equal(t, tt.expected, actual)
+			tt.isError(t, err)
+		})
+	}
+}
+
+func TestIsPackageIgnored(t *testing.T) {
+	tests := []struct {
+		desc     string
+		pkg      string
+		dir      string
+		expected bool
+	}{
+		{
+			desc:     "Test root dir is unknown, returns false",
+			pkg:      "/a/test/root/package/handlers",
+			expected: false,
+		},
+		{
+			desc:     "Test package is not ignored, returns false",
+			pkg:      "/a/test/root/package/notignored",
+			dir:      "/a/test/root/package",
+			expected: false,
+		},
+		{
+			desc:     "Test package is ignored, returns true",
+			pkg:      "/a/test/root/package/handlers",
+			dir:      "/a/test/root/package",
+			expected: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			g := &Generator{dir: tt.dir}
+			actual := g.isPackageIgnored(tt.pkg)
+			assert.Equal(t, tt.expected, actual)
+		})
+	}
+}
+
+func TestGetProjectFromImportPath(t *testing.T) {
+	tests := []struct {
+		desc     string
+		path     string
+		expected string
+		isError  assert.ErrorAssertionFunc
+	}{
+		{
+			desc:     "Input path is not an assumed path returns error",
+			path:     "go.uber.org/zap",
+			expected: "",
+			isError:  assert.Error,
+		},
+		{
+			desc:     "Input path is an assumed path returns the project name",
+			path:     "github.com/uber/zap",
+			expected: "zap",
+			isError:  assert.NoError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			actual, err := getProjectFromImportPath(tt.path)
+			assert.Equal(t, tt.expected, actual)
+			tt.isError(t, err)
+		})
+	}
+}
+
+func TestTranslateRoute(t *testing.T) {
+	tests := []struct {
+		desc           string
+		src            string
+		expectedPath   string
+		expectedParams []routeParam
+	}{
+		{
+			desc:         "static route is left untouched",
+			src:          "/v1/orders",
+			expectedPath: "/v1/orders",
+		},
+		{
+			desc:           "beego named parameter",
+			src:            "/orders/:id",
+			expectedPath:   "/orders/{id}",
+			expectedParams: []routeParam{{Name: "id", Type: "string"}},
+		},
+		{
+			desc:           "beego optional parameter",
+			src:            "/orders/?:id",
+			expectedPath:   "/orders/{id}",
+			expectedParams: []routeParam{{Name: "id", Type: "string", Optional: true}},
+		},
+		{
+			desc:           "beego int suffix",
+			src:            "/orders/:id:int",
+			expectedPath:   "/orders/{id}",
+			expectedParams: []routeParam{{Name: "id", Type: "integer", Format: "int64"}},
+		},
+		{
+			desc:           "beego string suffix",
+			src:            "/users/:name:string",
+			expectedPath:   "/users/{name}",
+			expectedParams: []routeParam{{Name: "name", Type: "string", Pattern: `^[\w]+$`}},
+		},
+		{
+			desc:           "beego digit regex",
+			src:            "/orders/:id([0-9]+)",
+			expectedPath:   "/orders/{id}",
+			expectedParams: []routeParam{{Name: "id", Type: "integer", Format: "int64"}},
+		},
+		{
+			desc:           "beego custom regex with prefix and suffix",
+			src:            "/cms_:slug([a-z]+(-[a-z]+)*).html",
+			expectedPath:   "/cms_{slug}.html",
+			expectedParams: []routeParam{{Name: "slug", Type: "string", Pattern: "^[a-z]+(-[a-z]+)*$"}},
+		},
+		{
+			desc:           "beego regex with quantifier",
+			src:            "/skus/:sku([A-Z]{3}-[0-9]+)",
+			expectedPath:   "/skus/{sku}",
+			expectedParams: []routeParam{{Name: "sku", Type: "string", Pattern: "^[A-Z]{3}-[0-9]+$"}},
+		},
+		{
+			desc:           "beego splat",
+			src:            "/static/*",
+			expectedPath:   "/static/{splat}",
+			expectedParams: []routeParam{{Name: "splat", Type: "string"}},
+		},
+		{
+			desc:         "beego path and extension",
+			src:          "/download/*.*",
+			expectedPath: "/download/{path}.{ext}",
+			expectedParams: []routeParam{
+				{Name: "path", Type: "string"},
+				{Name: "ext", Type: "string"},
+			},
+		},
+		{
+			desc:           "chi plain parameter",
+			src:            "/v1/orders/{orderID}",
+			expectedPath:   "/v1/orders/{orderID}",
+			expectedParams: []routeParam{{Name: "orderID", Type: "string"}},
+		},
+		{
+			desc:           "chi regex parameter with quantifier",
+			src:            "/v1/codes/{code:[A-Z]{2,3}}",
+			expectedPath:   "/v1/codes/{code}",
+			expectedParams: []routeParam{{Name: "code", Type: "string", Pattern: "^[A-Z]{2,3}$"}},
+		},
+		{
+			desc:           "chi digit regex parameter",
+			src:            "/v1/orders/{id:[0-9]+}/items",
+			expectedPath:   "/v1/orders/{id}/items",
+			expectedParams: []routeParam{{Name: "id", Type: "integer", Format: "int64"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			actualPath, actualParams := translateRoute(tt.src)
+			assert.Equal(t, tt.expectedPath, actualPath)
+			assert.Equal(t, tt.expectedParams, actualParams)
+		})
+	}
+}
+
+func TestAddRouteParameters(t *testing.T) {
+	g := newTestGenerator(t)
+	op := &swagger.Operation{
+		Parameters: []swagger.Parameter{
+			{In: "path", Name: "id", Description: "the order id"},
+			{In: "path", Name: "unknown"},
+		},
+	}
+	item := &swagger.Item{Get: op}
+
+	rt, params := translateRoute("/orders/{id:[a-f0-9]+}/lines/:line:int")
+	g.addRouteParameters(rt, item, params)
+
+	assert.Equal(t, []swagger.Parameter{
+		{In: "path", Name: "id", Description: "the order id", Required: true, Type: "string"},
+		{In: "path", Name: "line", Required: true, Type: "integer", Format: "int64"},
+	}, op.Parameters)
+	assert.Equal(t, "^[a-f0-9]+$", g.docExtras.parameters[op]["path:id"]["pattern"])
+	assert.Equal(t, []Diagnostic{{
+		Severity: SeverityWarning,
+		Message:  `@Param path "unknown" is not part of route GET '/orders/{id}/lines/{line}', left it out`,
+	}}, g.diagnostics)
+}
+
+func TestSplitGodoc(t *testing.T) {
+	tests := []struct {
+		desc            string
+		text            string
+		expectedSummary string
+		expectedRest    string
+	}{
+		{
+			desc:            "single sentence",
+			text:            "Get returns an order.",
+			expectedSummary: "Get returns an order.",
+		},
+		{
+			desc:            "first sentence becomes the summary",
+			text:            "Get returns an order. The order must belong\nto the current customer.",
+			expectedSummary: "Get returns an order.",
+			expectedRest:    "The order must belong\nto the current customer.",
+		},
+		{
+			desc:            "sentence wrapped over several lines",
+			text:            "Get returns an order of the\ncurrent customer.\n\nIt is cached.",
+			expectedSummary: "Get returns an order of the current customer.",
+			expectedRest:    "It is cached.",
+		},
+		{
+			desc:            "first paragraph without period",
+			text:            "Get returns an order\n\nIt is cached.",
+			expectedSummary: "Get returns an order",
+			expectedRest:    "It is cached.",
+		},
+		{
+			desc:            "version numbers do not end the sentence",
+			text:            "Get returns the v1.2 order.",
+			expectedSummary: "Get returns the v1.2 order.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			summary, rest := splitGodoc(tt.text)
+			assert.Equal(t, tt.expectedSummary, summary)
+			assert.Equal(t, tt.expectedRest, rest)
+		})
+	}
+}
+
+func TestApplyGodoc(t *testing.T) {
+	comments := func(lines ...string) *ast.CommentGroup {
+		g := &ast.CommentGroup{}
+		for _, l := range lines {
+			g.List = append(g.List, &ast.Comment{Text: "// " + l})
+		}
+		return g
+	}
+
+	tests := []struct {
+		desc     string
+		opts     swagger.Operation
+		comments *ast.CommentGroup
+		expected swagger.Operation
+	}{
+		{
+			desc:     "one sentence is only the summary",
+			comments: comments("Get returns an order.", "@router /:id [get]"),
+			expected: swagger.Operation{Summary: "Get returns an order."},
+		},
+		{
+			desc:     "the rest is the description",
+			comments: comments("Get returns an order.", "", "It is cached."),
+			expected: swagger.Operation{Summary: "Get returns an order.", Description: "It is cached."},
+		},
+		{
+			desc:     "annotated summary",
+			opts:     swagger.Operation{Summary: "get an order"},
+			comments: comments("Get returns an order."),
+			expected: swagger.Operation{Summary: "get an order"},
+		},
+		{
+			desc:     "annotated description",
+			opts:     swagger.Operation{Description: "cached"},
+			comments: comments("Get returns an order. It is cached."),
+			expected: swagger.Operation{Summary: "Get returns an order.", Description: "cached"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			opts := tt.opts
+			applyGodoc(&opts, tt.comments)
+			assert.Equal(t, tt.expected, opts)
+		})
+	}
+}
+
+func TestPackageDoc(t *testing.T) {
+	file := func(doc string) *ast.File {
+		if doc == "" {
+			return &ast.File{}
+		}
+		return &ast.File{Doc: &ast.CommentGroup{List: []*ast.Comment{{Text: "// " + doc}}}}
+	}
+
+	tests := []struct {
+		desc     string
+		files    map[string]*ast.File
+		expected string
+	}{
+		{
+			desc:     "first file in order",
+			files:    map[string]*ast.File{"orders/b.go": file("Package b."), "orders/a.go": file("Package a."), "orders/0.go": file("")},
+			expected: "Package a.",
+		},
+		{
+			desc:     "doc.go wins",
+			files:    map[string]*ast.File{"orders/a.go": file("Package a."), "orders/doc.go": file("Package orders.")},
+			expected: "Package orders.",
+		},
+		{
+			desc:  "no package comment",
+			files: map[string]*ast.File{"orders/a.go": file("")},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			for i := 0; i < 10; i++ {
+				assert.Equal(t, tt.expected, godocText(packageDoc(&ast.Package{Files: tt.files})))
+			}
+		})
+	}
+}
+
+func TestParseTagAnnotation(t *testing.T) {
+	tests := []struct {
+		desc         string
+		line         string
+		expectedName string
+		expectedDesc string
+		expectedOK   bool
+	}{
+		{
+			desc:         "quoted description",
+			line:         `@Tag orders "Everything about orders"`,
+			expectedName: "orders",
+			expectedDesc: "Everything about orders",
+			expectedOK:   true,
+		},
+		{
+			desc:         "unquoted description",
+			line:         `@Tag orders Everything about orders`,
+			expectedName: "orders",
+			expectedDesc: "Everything about orders",
+			expectedOK:   true,
+		},
+		{
+			desc:         "name only",
+			line:         `@Tag orders`,
+			expectedName: "orders",
+			expectedOK:   true,
+		},
+		{
+			desc: "missing name",
+			line: `@Tag`,
+		},
+		{
+			desc: "other annotation",
+			line: `@Title orders`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			name, desc, ok := parseTagAnnotation(tt.line)
+			assert.Equal(t, tt.expectedName, name)
+			assert.Equal(t, tt.expectedDesc, desc)
+			assert.Equal(t, tt.expectedOK, ok)
+		})
+	}
+}
+
+func TestParseDeprecated(t *testing.T) {
+	tests := []struct {
+		desc               string
+		value              string
+		expectedDeprecated bool
+		expectedExtras     map[string]interface{}
+	}{
+		{
+			desc:               "plain boolean",
+			value:              " true",
+			expectedDeprecated: true,
+		},
+		{
+			desc:               "not deprecated ignores the lifecycle",
+			value:              " false sunset=2026-06-30",
+			expectedDeprecated: false,
+		},
+		{
+			desc:               "lifecycle details",
+			value:              " true since=2026-01-01 sunset=2026-06-30 replacement=/v2/orders",
+			expectedDeprecated: true,
+			expectedExtras: map[string]interface{}{
+				"x-deprecated-since": "2026-01-01",
+				"x-sunset":           "2026-06-30",
+				"x-replaced-by":      "/v2/orders",
+			},
+		},
+		{
+			desc:               "invalid dates are skipped",
+			value:              " true sunset=30/06/2026 replacement=/v2/orders",
+			expectedDeprecated: true,
+			expectedExtras: map[string]interface{}{
+				"x-replaced-by": "/v2/orders",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			g := newTestGenerator(t)
+			op := &swagger.Operation{}
+			g.parseDeprecated(op, tt.value, "OrderController", "Get")
+			assert.Equal(t, tt.expectedDeprecated, op.Deprecated)
+			assert.Equal(t, tt.expectedExtras, map[string]interface{}(g.docExtras.operations[op]))
+		})
+	}
+}
+
+func TestIsPastSunset(t *testing.T) {
+	now = func() time.Time {
+		return time.Date(2026, 6, 30, 12, 0, 0, 0, time.UTC)
+	}
+	defer func() { now = time.Now }()
+
+	assert.False(t, isPastSunset("2026-06-30"))
+	assert.True(t, isPastSunset("2026-06-29"))
+	assert.False(t, isPastSunset("not a date"))
+}
+
+func TestParseExtension(t *testing.T) {
+	tests := []struct {
+		desc          string
+		line          string
+		expectedName  string
+		expectedValue interface{}
+		expectedOK    bool
+	}{
+		{
+			desc:          "json object",
+			line:          `@Extension x-rate-limit {"limit": 100, "window": "1m"}`,
+			expectedName:  "x-rate-limit",
+			expectedValue: map[string]interface{}{"limit": float64(100), "window": "1m"},
+			expectedOK:    true,
+		},
+		{
+			desc:          "json boolean",
+			line:          `@Extension x-internal true`,
+			expectedName:  "x-internal",
+			expectedValue: true,
+			expectedOK:    true,
+		},
+		{
+			desc:          "plain string",
+			line:          `@Extension x-owner-team catalog squad`,
+			expectedName:  "x-owner-team",
+			expectedValue: "catalog squad",
+			expectedOK:    true,
+		},
+		{
+			desc:          "no value is a flag",
+			line:          `@Extension x-internal`,
+			expectedName:  "x-internal",
+			expectedValue: true,
+			expectedOK:    true,
+		},
+		{
+			desc: "name without x- prefix",
+			line: `@Extension rate-limit 100`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			name, value, ok := newTestGenerator(t).parseExtension(tt.line)
+			assert.Equal(t, tt.expectedName, name)
+			assert.Equal(t, tt.expectedValue, value)
+			assert.Equal(t, tt.expectedOK, ok)
+		})
+	}
+}
+
+func TestFilterInternal(t *testing.T) {
+	doc := map[string]interface{}{
+		"tags": []interface{}{
+			map[string]interface{}{"name": "orders"},
+			map[string]interface{}{"name": "admin", "x-internal": true},
+		},
+		"paths": map[string]interface{}{
+			"/orders": map[string]interface{}{
+				"get": map[string]interface{}{
+					"tags": []interface{}{"orders"},
+					"responses": map[string]interface{}{
+						"200": map[string]interface{}{
+							"schema": map[string]interface{}{"$ref": "#/definitions/models.Order"},
+						},
+					},
+				},
+				"delete": map[string]interface{}{
+					"tags":       []interface{}{"orders"},
+					"x-internal": true,
+				},
+			},
+			"/admin/users": map[string]interface{}{
+				"get": map[string]interface{}{
+					"tags": []interface{}{"admin"},
+					"responses": map[string]interface{}{
+						"200": map[string]interface{}{
+							"schema": map[string]interface{}{"$ref": "#/definitions/models.User"},
+						},
+					},
+				},
+			},
+		},
+		"definitions": map[string]interface{}{
+			"models.Order": map[string]interface{}{
+				"properties": map[string]interface{}{
+					"line": map[string]interface{}{"$ref": "#/definitions/models.Line"},
+				},
+			},
+			"models.Line": map[string]interface{}{},
+			"models.User": map[string]interface{}{},
+		},
+	}
+
+	expected := map[string]interface{}{
+		"tags": []interface{}{
+			map[string]interface{}{"name": "orders"},
+		},
+		"paths": map[string]interface{}{
+			"/orders": map[string]interface{}{
+				"get": map[string]interface{}{
+					"tags": []interface{}{"orders"},
+					"responses": map[string]interface{}{
+						"200": map[string]interface{}{
+							"schema": map[string]interface{}{"$ref": "#/definitions/models.Order"},
+						},
+					},
+				},
+			},
+		},
+		"definitions": map[string]interface{}{
+			"models.Order": map[string]interface{}{
+				"properties": map[string]interface{}{
+					"line": map[string]interface{}{"$ref": "#/definitions/models.Line"},
+				},
+			},
+			"models.Line": map[string]interface{}{},
+		},
+	}
+
+	assert.Equal(t, expected, FilterInternal(doc))
+}
+
+func newTestGenerator(t *testing.T) *Generator {
+	g := New(Options{Dir: "../testdata/router"})
+	assert.NoError(t, g.reset(context.Background()))
+	return g
+}
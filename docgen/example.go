@@ -0,0 +1,174 @@
+package docgen
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Example returns a sample value of schema. It uses the example, default
+// or first enum value of a schema when there is one and builds objects and
+// arrays out of their properties and items otherwise. References are
+// followed once per branch so that recursive definitions terminate.
+func (s *Spec) Example(schema map[string]interface{}) interface{} {
+	return s.example(schema, make(map[string]bool))
+}
+
+func (s *Spec) example(schema map[string]interface{}, seen map[string]bool) interface{} {
+	if schema == nil {
+		return nil
+	}
+
+	if name := RefName(schema); name != "" {
+		if seen[name] {
+			return nil
+		}
+		seen[name] = true
+		defer delete(seen, name)
+		return s.example(s.Definition(name), seen)
+	}
+
+	typ, _ := schema["type"].(string)
+	if v, ok := schema["example"]; ok {
+		return coerce(typ, v)
+	}
+	if v, ok := schema["default"]; ok {
+		return coerce(typ, v)
+	}
+	if enum, ok := schema["enum"].([]interface{}); ok && len(enum) > 0 {
+		return coerce(typ, enum[0])
+	}
+
+	// Interfaces are represented by their first implementation.
+	if oneOf := stringMapList(schema["x-oneOf"]); len(oneOf) > 0 {
+		return s.example(oneOf[0], seen)
+	}
+	if oneOf := stringMapList(schema["oneOf"]); len(oneOf) > 0 {
+		return s.example(oneOf[0], seen)
+	}
+
+	if _, ok := schema["properties"]; ok || typ == "object" || schema["allOf"] != nil {
+		return s.objectExample(schema, seen)
+	}
+
+	switch typ {
+	case "array":
+		items, _ := schema["items"].(map[string]interface{})
+		if item := s.example(items, seen); item != nil {
+			return []interface{}{item}
+		}
+		return []interface{}{}
+	case "string":
+		return stringExample(schema)
+	case "integer", "number":
+		if min, ok := schema["minimum"].(float64); ok {
+			return min
+		}
+		return 0
+	case "boolean":
+		return false
+	case "file":
+		return ""
+	}
+	return nil
+}
+
+func (s *Spec) objectExample(schema map[string]interface{}, seen map[string]bool) interface{} {
+	obj := make(map[string]interface{})
+
+	// allOf parts are merged, the implementations of an interface get the
+	// discriminator value of their own type.
+	discriminator := ""
+	for _, part := range stringMapList(schema["allOf"]) {
+		name := RefName(part)
+		base := s.Resolve(part)
+		if d, ok := base["discriminator"].(string); ok {
+			discriminator = d
+		}
+		if base == nil || seen[name] {
+			continue
+		}
+		// The base is expanded as a plain object, its x-oneOf would lead
+		// back to the implementations.
+		seen[name] = true
+		if m, ok := s.objectExample(base, seen).(map[string]interface{}); ok {
+			for k, v := range m {
+				obj[k] = v
+			}
+		}
+		delete(seen, name)
+	}
+
+	props, _ := schema["properties"].(map[string]interface{})
+	names := make([]string, 0, len(props))
+	for name := range props {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		prop, _ := props[name].(map[string]interface{})
+		if v := s.example(prop, seen); v != nil {
+			obj[name] = v
+		} else if _, ok := obj[name]; !ok {
+			obj[name] = nil
+		}
+	}
+
+	if additional, ok := schema["additionalProperties"].(map[string]interface{}); ok && len(obj) == 0 {
+		if v := s.example(additional, seen); v != nil {
+			obj["key"] = v
+		}
+	}
+
+	if value, ok := schema["x-discriminator-value"].(string); ok && discriminator != "" {
+		obj[discriminator] = value
+	}
+	return obj
+}
+
+func stringExample(schema map[string]interface{}) string {
+	format, _ := schema["format"].(string)
+	switch format {
+	case "date":
+		return "2006-01-02"
+	case "date-time", "datetime":
+		return "2006-01-02T15:04:05Z"
+	case "byte":
+		return "ZXhhbXBsZQ=="
+	case "email":
+		return "user@example.com"
+	case "uuid":
+		return "00000000-0000-0000-0000-000000000000"
+	}
+	return "string"
+}
+
+// coerce converts the string examples and defaults of the beego structs
+// into the type of their schema.
+func coerce(typ string, v interface{}) interface{} {
+	str, ok := v.(string)
+	if !ok {
+		return v
+	}
+	switch typ {
+	case "integer":
+		if i, err := strconv.ParseInt(str, 10, 64); err == nil {
+			return i
+		}
+	case "number":
+		if f, err := strconv.ParseFloat(str, 64); err == nil {
+			return f
+		}
+	case "boolean":
+		if b, err := strconv.ParseBool(str); err == nil {
+			return b
+		}
+	case "array":
+		var out []interface{}
+		for _, item := range strings.Split(str, ",") {
+			out = append(out, strings.TrimSpace(item))
+		}
+		return out
+	}
+	return v
+}
@@ -0,0 +1,201 @@
+package docgen
+
+import (
+	"encoding/json"
+	"go/ast"
+	"strings"
+
+	"github.com/astaxie/beego/swagger"
+)
+
+// parseExtension parses `@Extension x-name <json value>`. Values that are
+// not valid JSON are kept as plain strings, e.g. `@Extension x-owner-team
+// catalog`.
+func (g *Generator) parseExtension(line string) (name string, value interface{}, ok bool) {
+	line = strings.TrimSpace(line)
+	if !strings.HasPrefix(line, "@Extension") {
+		return "", nil, false
+	}
+
+	rest := strings.TrimSpace(line[len("@Extension"):])
+	name, pos := peekNextSplitString(rest)
+	if !strings.HasPrefix(name, "x-") {
+		g.warnf("@Extension name must start with 'x-': %s", line)
+		return "", nil, false
+	}
+
+	raw := strings.TrimSpace(rest[pos:])
+	if raw == "" {
+		return name, true, true
+	}
+	if err := json.Unmarshal([]byte(raw), &value); err != nil {
+		if strings.HasPrefix(raw, "{") || strings.HasPrefix(raw, "[") {
+			g.warnf("@Extension %s has an invalid JSON value: %v", name, err)
+		}
+		value = raw
+	}
+	return name, value, true
+}
+
+// collectExtensions returns the @Extension annotations found in comments.
+func (g *Generator) collectExtensions(comments *ast.CommentGroup) map[string]interface{} {
+	if comments == nil {
+		return nil
+	}
+	var extensions map[string]interface{}
+	for _, line := range strings.Split(comments.Text(), "\n") {
+		if name, value, ok := g.parseExtension(line); ok {
+			if extensions == nil {
+				extensions = make(map[string]interface{})
+			}
+			extensions[name] = value
+		}
+	}
+	return extensions
+}
+
+// applyControllerExtensions attaches the @Extension annotations of a
+// controller to its tag.
+func (g *Generator) applyControllerExtensions(tag, controllerName string) {
+	extensions, ok := g.controllerExtensions[controllerName]
+	if !ok {
+		return
+	}
+
+	found := false
+	for _, t := range g.rootapi.Tags {
+		if t.Name == tag {
+			found = true
+			break
+		}
+	}
+	if !found {
+		g.rootapi.Tags = append(g.rootapi.Tags, swagger.Tag{Name: tag})
+	}
+
+	for name, value := range extensions {
+		g.docExtras.setTag(tag, name, value)
+	}
+}
+
+// isInternal reports whether an extension value marks something internal.
+func isInternal(v interface{}) bool {
+	switch t := v.(type) {
+	case bool:
+		return t
+	case string:
+		return t == "true"
+	}
+	return false
+}
+
+// FilterInternal returns a copy of doc without the operations marked with
+// `x-internal: true`, either directly or through one of their tags, and
+// without the tags and definitions only those operations used.
+func FilterInternal(doc map[string]interface{}) map[string]interface{} {
+	out := copyFields(doc)
+
+	internalTags := make(map[string]bool)
+	var tags []interface{}
+	for _, t := range stringMapList(doc["tags"]) {
+		name, _ := t["name"].(string)
+		if isInternal(t["x-internal"]) {
+			internalTags[name] = true
+			continue
+		}
+		tags = append(tags, t)
+	}
+
+	paths := make(map[string]interface{})
+	usedTags := make(map[string]bool)
+	if docPaths, ok := doc["paths"].(map[string]interface{}); ok {
+		for rt, it := range docPaths {
+			item, ok := it.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			itemOut := make(map[string]interface{})
+			for method, o := range item {
+				op, ok := o.(map[string]interface{})
+				if !ok {
+					itemOut[method] = o
+					continue
+				}
+				if isInternal(op["x-internal"]) {
+					continue
+				}
+				internal := false
+				for _, tag := range stringList(op["tags"]) {
+					if internalTags[tag] {
+						internal = true
+					}
+				}
+				if internal {
+					continue
+				}
+				for _, tag := range stringList(op["tags"]) {
+					usedTags[tag] = true
+				}
+				itemOut[method] = op
+			}
+			if len(itemOut) > 0 {
+				paths[rt] = itemOut
+			}
+		}
+	}
+	out["paths"] = paths
+
+	var publicTags []interface{}
+	for _, t := range tags {
+		name, _ := t.(map[string]interface{})["name"].(string)
+		if usedTags[name] {
+			publicTags = append(publicTags, t)
+		}
+	}
+	if len(publicTags) > 0 {
+		out["tags"] = publicTags
+	} else {
+		delete(out, "tags")
+	}
+
+	if definitions, ok := doc["definitions"].(map[string]interface{}); ok {
+		used := make(map[string]bool)
+		collectDefinitionRefs(paths, definitions, used)
+		publicDefinitions := make(map[string]interface{})
+		for name, def := range definitions {
+			if used[name] {
+				publicDefinitions[name] = def
+			}
+		}
+		if len(publicDefinitions) > 0 {
+			out["definitions"] = publicDefinitions
+		} else {
+			delete(out, "definitions")
+		}
+	}
+
+	return out
+}
+
+// collectDefinitionRefs marks the definitions referenced from v, following
+// the references of the definitions themselves.
+func collectDefinitionRefs(v interface{}, definitions map[string]interface{}, used map[string]bool) {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		for k, val := range t {
+			if ref, ok := val.(string); ok && k == "$ref" && strings.HasPrefix(ref, "#/definitions/") {
+				name := strings.TrimPrefix(ref, "#/definitions/")
+				if !used[name] {
+					used[name] = true
+					collectDefinitionRefs(definitions[name], definitions, used)
+				}
+				continue
+			}
+			collectDefinitionRefs(val, definitions, used)
+		}
+	case []interface{}:
+		for _, val := range t {
+			collectDefinitionRefs(val, definitions, used)
+		}
+	}
+}
@@ -0,0 +1,189 @@
+package docgen
+
+import (
+	"encoding/json"
+
+	"github.com/astaxie/beego/swagger"
+)
+
+// specExtras holds the fields of the generated document that the beego
+// swagger structs have no room for, such as parameter patterns. They are
+// merged into the marshalled document right before it is written.
+type specExtras struct {
+	root        map[string]interface{}
+	tags        map[string]map[string]interface{}
+	definitions map[string]map[string]interface{}
+	operations  map[*swagger.Operation]map[string]interface{}
+	// parameters are keyed by operation and then by "in:name".
+	parameters map[*swagger.Operation]map[string]map[string]interface{}
+}
+
+func newSpecExtras() *specExtras {
+	return &specExtras{
+		root:        make(map[string]interface{}),
+		tags:        make(map[string]map[string]interface{}),
+		definitions: make(map[string]map[string]interface{}),
+		operations:  make(map[*swagger.Operation]map[string]interface{}),
+		parameters:  make(map[*swagger.Operation]map[string]map[string]interface{}),
+	}
+}
+
+// setOperation records an extra field on the given operation.
+func (e *specExtras) setOperation(op *swagger.Operation, key string, value interface{}) {
+	fields, ok := e.operations[op]
+	if !ok {
+		fields = make(map[string]interface{})
+		e.operations[op] = fields
+	}
+	fields[key] = value
+}
+
+// setParameter records an extra field on the parameter of op identified by
+// its location and name.
+func (e *specExtras) setParameter(op *swagger.Operation, in, name, key string, value interface{}) {
+	params, ok := e.parameters[op]
+	if !ok {
+		params = make(map[string]map[string]interface{})
+		e.parameters[op] = params
+	}
+	fields, ok := params[in+":"+name]
+	if !ok {
+		fields = make(map[string]interface{})
+		params[in+":"+name] = fields
+	}
+	fields[key] = value
+}
+
+// setDefinition records an extra field on the named definition.
+func (e *specExtras) setDefinition(name, key string, value interface{}) {
+	fields, ok := e.definitions[name]
+	if !ok {
+		fields = make(map[string]interface{})
+		e.definitions[name] = fields
+	}
+	fields[key] = value
+}
+
+// addDefinitionAllOf adds a reference to the allOf of the named definition,
+// keeping the ones added before, e.g. by the other interfaces it implements.
+func (e *specExtras) addDefinitionAllOf(name, ref string) {
+	allOf, _ := e.definitions[name]["allOf"].([]interface{})
+	for _, part := range allOf {
+		if part.(map[string]interface{})["$ref"] == ref {
+			return
+		}
+	}
+	e.setDefinition(name, "allOf", append(allOf, map[string]interface{}{"$ref": ref}))
+}
+
+// setTag records an extra field on the named tag.
+func (e *specExtras) setTag(name, key string, value interface{}) {
+	fields, ok := e.tags[name]
+	if !ok {
+		fields = make(map[string]interface{})
+		e.tags[name] = fields
+	}
+	fields[key] = value
+}
+
+// marshalSpec turns doc into a generic JSON document and merges the extras
+// into it.
+func marshalSpec(doc swagger.Swagger, extras *specExtras) (map[string]interface{}, error) {
+	b, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	var out map[string]interface{}
+	if err := json.Unmarshal(b, &out); err != nil {
+		return nil, err
+	}
+
+	mergeFields(out, extras.root)
+
+	if definitions, ok := out["definitions"].(map[string]interface{}); ok {
+		for name, fields := range extras.definitions {
+			if def, ok := definitions[name].(map[string]interface{}); ok {
+				allOf, _ := def["allOf"].([]interface{})
+				mergeFields(def, fields)
+				if extra, ok := fields["allOf"].([]interface{}); ok && len(allOf) > 0 {
+					def["allOf"] = append(allOf, extra...)
+				}
+			}
+		}
+	}
+
+	if tags, ok := out["tags"].([]interface{}); ok {
+		for _, t := range tags {
+			tag, ok := t.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			name, _ := tag["name"].(string)
+			mergeFields(tag, extras.tags[name])
+		}
+	}
+
+	paths, _ := out["paths"].(map[string]interface{})
+	for rt, item := range doc.Paths {
+		itemOut, ok := paths[rt].(map[string]interface{})
+		if !ok || item == nil {
+			continue
+		}
+		for method, op := range itemOperations(item) {
+			opOut, ok := itemOut[method].(map[string]interface{})
+			if !ok {
+				continue
+			}
+			mergeFields(opOut, extras.operations[op])
+
+			params, _ := opOut["parameters"].([]interface{})
+			for i, p := range op.Parameters {
+				if i >= len(params) {
+					break
+				}
+				paramOut, ok := params[i].(map[string]interface{})
+				if !ok {
+					continue
+				}
+				mergeFields(paramOut, extras.parameters[op][p.In+":"+p.Name])
+			}
+		}
+	}
+
+	return out, nil
+}
+
+// itemOperations returns the operations defined on item keyed by their
+// lower-cased HTTP method, as they appear in the document.
+func itemOperations(item *swagger.Item) map[string]*swagger.Operation {
+	ops := make(map[string]*swagger.Operation)
+	if item.Get != nil {
+		ops["get"] = item.Get
+	}
+	if item.Put != nil {
+		ops["put"] = item.Put
+	}
+	if item.Post != nil {
+		ops["post"] = item.Post
+	}
+	if item.Delete != nil {
+		ops["delete"] = item.Delete
+	}
+	if item.Options != nil {
+		ops["options"] = item.Options
+	}
+	if item.Head != nil {
+		ops["head"] = item.Head
+	}
+	if item.Patch != nil {
+		ops["patch"] = item.Patch
+	}
+	return ops
+}
+
+func mergeFields(dst map[string]interface{}, fields map[string]interface{}) {
+	for k, v := range fields {
+		dst[k] = v
+	}
+}
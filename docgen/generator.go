@@ -0,0 +1,310 @@
+// Package docgen generates swagger documents out of the beego annotations
+// and chi routes of a project.
+//
+//	gen := docgen.New(docgen.Options{Dir: "/path/to/project"})
+//	spec, diagnostics, err := gen.Generate(ctx)
+//
+// A Generator keeps no state between runs, so it can be used several times
+// in the same process.
+package docgen
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/astaxie/beego/swagger"
+	"gopkg.in/yaml.v3"
+)
+
+// Format is an output format of the generated documents.
+type Format string
+
+const (
+	FormatJSON         Format = "json"          // swagger.json
+	FormatYAML         Format = "yaml"          // swagger.yml
+	FormatOpenAPI3JSON Format = "openapi3-json" // openapi.json
+	FormatOpenAPI3YAML Format = "openapi3-yaml" // openapi.yml
+	FormatTypeScript   Format = "typescript"    // api.ts
+)
+
+// Default entry points, relative to the root directory.
+const (
+	DefaultRouter           = "routers/router.go"
+	DefaultChiRoutes        = "pkg/router/routes.go"
+	DefaultChiHandlerPrefix = "github.com/zalora/doraemon/handlers/"
+	DefaultOutputDir        = "swagger"
+)
+
+// Options configures a Generator.
+type Options struct {
+	// Dir is the root directory of the project. Defaults to the current
+	// directory.
+	Dir string
+	// Router is the beego router file. Defaults to DefaultRouter.
+	Router string
+	// ChiRoutes is the chi routes file. Defaults to DefaultChiRoutes.
+	ChiRoutes string
+	// ChiHandlerPrefix is the import path prefix of the chi handler
+	// packages. Defaults to DefaultChiHandlerPrefix.
+	ChiHandlerPrefix string
+
+	// OutputDir is where the documents are written, relative to Dir unless
+	// absolute. Nothing is written when Formats is empty.
+	OutputDir string
+	Formats   []Format
+	// Public also writes the documents without the operations marked
+	// with x-internal, suffixed with -public.
+	Public bool
+
+	// OneOf declares the concrete types of interface definitions, keyed by
+	// the definition name.
+	OneOf map[string]OneOf
+
+	// Transform is called with the generated spec before it is written,
+	// to add vendor extensions to its document.
+	Transform func(spec *Spec)
+}
+
+// OneOf declares the concrete types of an interface definition.
+type OneOf struct {
+	Discriminator string            `json:"discriminator" yaml:"discriminator"`
+	Mapping       map[string]string `json:"mapping" yaml:"mapping"` // discriminator value: type
+}
+
+// Severity is the severity of a Diagnostic.
+type Severity int
+
+const (
+	SeverityWarning Severity = iota
+	SeverityError
+)
+
+func (s Severity) String() string {
+	if s == SeverityError {
+		return "error"
+	}
+	return "warning"
+}
+
+// Diagnostic is a problem found in the annotations.
+type Diagnostic struct {
+	Severity Severity
+	Message  string
+}
+
+func (d Diagnostic) String() string {
+	return d.Severity.String() + ": " + d.Message
+}
+
+// Spec is a generated document.
+type Spec struct {
+	// Swagger is the document as parsed from the annotations.
+	Swagger swagger.Swagger
+	// Document is the generic form of Swagger including the vendor
+	// extensions, which the swagger structs cannot hold.
+	Document map[string]interface{}
+}
+
+// OpenAPI3 returns the document converted to OpenAPI 3.
+func (s *Spec) OpenAPI3() map[string]interface{} {
+	return ConvertToOpenAPI3(s.Document)
+}
+
+// Public returns the document without the internal operations.
+func (s *Spec) Public() map[string]interface{} {
+	return FilterInternal(s.Document)
+}
+
+// Generator generates the swagger document of a project.
+type Generator struct {
+	opts Options
+	dir  string
+	ctx  context.Context
+
+	diagnostics []Diagnostic
+
+	pkgCache             map[string]struct{}               // pkg:controller:function:comments comments: key:value
+	controllerComments   map[string]string                 // pkgpath+controller: doc comment
+	controllerExtensions map[string]map[string]interface{} // pkgpath+controller: @Extension
+	importlist           map[string]string
+	controllerList       map[string]map[string]*swagger.Item // controllername Paths items
+	modelsList           map[string]swagger.Schema
+	rootapi              swagger.Swagger
+	chiAPIs              map[string]*swagger.Item
+	chiAPIPackages       map[string]string // routerPath: handler pkgpath
+	docExtras            *specExtras
+	tagOverrides         map[string]string // tag name: description from @Tag
+	packageDocs          map[string]string // pkgpath: package doc comment
+	handlers             []handlerDoc      // functions with a @router annotation
+}
+
+// New returns a Generator, filling in the defaults of opts.
+func New(opts Options) *Generator {
+	if opts.Router == "" {
+		opts.Router = DefaultRouter
+	}
+	if opts.ChiRoutes == "" {
+		opts.ChiRoutes = DefaultChiRoutes
+	}
+	if opts.ChiHandlerPrefix == "" {
+		opts.ChiHandlerPrefix = DefaultChiHandlerPrefix
+	}
+	if opts.OutputDir == "" {
+		opts.OutputDir = DefaultOutputDir
+	}
+	return &Generator{opts: opts}
+}
+
+// failure aborts a run, it is recovered by Generate.
+type failure struct {
+	err error
+}
+
+func (g *Generator) warnf(format string, args ...interface{}) {
+	g.diagnostics = append(g.diagnostics, Diagnostic{
+		Severity: SeverityWarning,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+// failf records an error and aborts the run.
+func (g *Generator) failf(format string, args ...interface{}) {
+	msg := fmt.Sprintf(format, args...)
+	g.diagnostics = append(g.diagnostics, Diagnostic{Severity: SeverityError, Message: msg})
+	panic(failure{err: fmt.Errorf("docgen: %s", msg)})
+}
+
+// checkContext aborts the run when ctx is done.
+func (g *Generator) checkContext() {
+	if err := g.ctx.Err(); err != nil {
+		panic(failure{err: err})
+	}
+}
+
+func (g *Generator) reset(ctx context.Context) error {
+	dir, err := filepath.Abs(g.opts.Dir)
+	if err != nil {
+		return err
+	}
+
+	g.dir = dir
+	g.ctx = ctx
+	g.diagnostics = nil
+	g.pkgCache = make(map[string]struct{})
+	g.controllerComments = make(map[string]string)
+	g.controllerExtensions = make(map[string]map[string]interface{})
+	g.importlist = make(map[string]string)
+	g.controllerList = make(map[string]map[string]*swagger.Item)
+	g.modelsList = make(map[string]swagger.Schema)
+	g.rootapi = swagger.Swagger{}
+	g.chiAPIs = make(map[string]*swagger.Item)
+	g.chiAPIPackages = make(map[string]string)
+	g.docExtras = newSpecExtras()
+	g.tagOverrides = make(map[string]string)
+	g.packageDocs = make(map[string]string)
+	g.handlers = nil
+	return nil
+}
+
+// Generate parses the project and writes the documents in the configured
+// formats. The diagnostics are returned even when generation fails.
+func (g *Generator) Generate(ctx context.Context) (spec *Spec, diagnostics []Diagnostic, err error) {
+	if err := g.reset(ctx); err != nil {
+		return nil, nil, err
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			f, ok := r.(failure)
+			if !ok {
+				panic(r)
+			}
+			spec, diagnostics, err = nil, g.diagnostics, f.err
+		}
+	}()
+
+	g.checkContext()
+	g.generate()
+
+	doc, err := marshalSpec(g.rootapi, g.docExtras)
+	if err != nil {
+		return nil, g.diagnostics, err
+	}
+	spec = &Spec{Swagger: g.rootapi, Document: doc}
+	if g.opts.Transform != nil {
+		g.opts.Transform(spec)
+	}
+
+	if err := g.write(spec); err != nil {
+		return nil, g.diagnostics, err
+	}
+	return spec, g.diagnostics, nil
+}
+
+// write writes spec in the configured formats.
+func (g *Generator) write(spec *Spec) error {
+	if len(g.opts.Formats) == 0 {
+		return nil
+	}
+
+	outDir := g.opts.OutputDir
+	if !filepath.IsAbs(outDir) {
+		outDir = filepath.Join(g.dir, outDir)
+	}
+	if err := os.MkdirAll(outDir, 0755); err != nil {
+		return err
+	}
+
+	if err := writeFormats(outDir, "", spec.Document, g.opts.Formats); err != nil {
+		return err
+	}
+	if g.opts.Public {
+		return writeFormats(outDir, "-public", spec.Public(), g.opts.Formats)
+	}
+	return nil
+}
+
+func writeFormats(dir, suffix string, doc map[string]interface{}, formats []Format) error {
+	var openapi map[string]interface{}
+	for _, format := range formats {
+		var (
+			name string
+			data []byte
+			err  error
+		)
+		switch format {
+		case FormatJSON:
+			name = "swagger" + suffix + ".json"
+			data, err = json.MarshalIndent(doc, "", "    ")
+		case FormatYAML:
+			name = "swagger" + suffix + ".yml"
+			data, err = yaml.Marshal(doc)
+		case FormatOpenAPI3JSON, FormatOpenAPI3YAML:
+			if openapi == nil {
+				openapi = ConvertToOpenAPI3(doc)
+			}
+			if format == FormatOpenAPI3JSON {
+				name = "openapi" + suffix + ".json"
+				data, err = json.MarshalIndent(openapi, "", "    ")
+			} else {
+				name = "openapi" + suffix + ".yml"
+				data, err = yaml.Marshal(openapi)
+			}
+		case FormatTypeScript:
+			name = "api" + suffix + ".ts"
+			data, err = (&Spec{Document: doc}).TypeScript()
+		default:
+			return fmt.Errorf("docgen: unknown format %q", format)
+		}
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package docgen
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerate(t *testing.T) {
+	gen := New(Options{Dir: "testdata/shop"})
+
+	spec, diagnostics, err := gen.Generate(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, "Shop API", spec.Swagger.Infos.Title)
+	assert.Equal(t, "/v1", spec.Swagger.BasePath)
+	assert.Contains(t, spec.Swagger.Paths, "/orders/{id}")
+	assert.Contains(t, spec.Swagger.Paths, "/orders/{id}/cancel")
+	assert.Contains(t, spec.Swagger.Definitions, "models.Order")
+	assert.Contains(t, spec.Swagger.Definitions, "models.Line")
+	assert.Equal(t, []string{"orders"}, spec.Swagger.Paths["/orders/{id}"].Get.Tags)
+	assert.Contains(t, diagnostics, Diagnostic{
+		Severity: SeverityWarning,
+		Message:  "missing response [@Success, @Failure] for route POST '/orders/{id}/cancel'",
+	})
+
+	// A second run starts from scratch.
+	again, againDiagnostics, err := gen.Generate(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, spec.Document, again.Document)
+	assert.Equal(t, []string{"orders"}, again.Swagger.Paths["/orders/{id}"].Get.Tags)
+	assert.Equal(t, len(diagnostics), len(againDiagnostics))
+}
+
+func TestGenerateWritesFormats(t *testing.T) {
+	out := t.TempDir()
+	gen := New(Options{
+		Dir:       "testdata/shop",
+		OutputDir: out,
+		Formats:   []Format{FormatJSON, FormatOpenAPI3YAML},
+	})
+
+	_, _, err := gen.Generate(context.Background())
+	require.NoError(t, err)
+
+	entries, err := os.ReadDir(out)
+	require.NoError(t, err)
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	assert.Equal(t, []string{"openapi.yml", "swagger.json"}, names)
+
+	data, err := os.ReadFile(filepath.Join(out, "swagger.json"))
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"/orders/{id}/cancel"`)
+}
+
+func TestGenerateErrors(t *testing.T) {
+	_, diagnostics, err := New(Options{Dir: "testdata/missing"}).Generate(context.Background())
+	assert.Error(t, err)
+	assert.Len(t, diagnostics, 1)
+	assert.Equal(t, SeverityError, diagnostics[0].Severity)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, _, err = New(Options{Dir: "testdata/shop"}).Generate(ctx)
+	assert.Equal(t, context.Canceled, err)
+}
+
+func TestGenerateOneOf(t *testing.T) {
+	spec, _, err := New(Options{Dir: "testdata/shop"}).Generate(context.Background())
+	require.NoError(t, err)
+
+	definitions := spec.Document["definitions"].(map[string]interface{})
+	banner := definitions["models.Banner"].(map[string]interface{})
+	// Banner implements both interfaces, and keeps the fields it embeds.
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"$ref": "#/definitions/models.Widget"},
+		map[string]interface{}{"$ref": "#/definitions/models.Media"},
+	}, banner["allOf"])
+	assert.Contains(t, banner["properties"], "title")
+	assert.Contains(t, banner["properties"], "image")
+
+	widget := definitions["models.Widget"].(map[string]interface{})
+	assert.Equal(t, "type", widget["discriminator"])
+	assert.Equal(t, []interface{}{map[string]interface{}{"$ref": "#/definitions/models.Banner"}}, widget["x-oneOf"])
+}
@@ -0,0 +1,147 @@
+package docgen
+
+import (
+	"go/ast"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/astaxie/beego/swagger"
+)
+
+// godocText returns the plain doc comment of a declaration, leaving out
+// the annotation lines.
+func godocText(comments *ast.CommentGroup) string {
+	if comments == nil {
+		return ""
+	}
+
+	var lines []string
+	for _, line := range strings.Split(comments.Text(), "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "@") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// sortedFileNames returns the names of the files of pkg in order, so that
+// the output does not depend on the order of the map.
+func sortedFileNames(pkg *ast.Package) []string {
+	names := make([]string, 0, len(pkg.Files))
+	for name := range pkg.Files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// packageDoc returns the package comment of pkg, preferring the one of
+// doc.go when several files have one.
+func packageDoc(pkg *ast.Package) *ast.CommentGroup {
+	var doc *ast.CommentGroup
+	for _, name := range sortedFileNames(pkg) {
+		fl := pkg.Files[name]
+		if fl.Doc == nil {
+			continue
+		}
+		if filepath.Base(name) == "doc.go" {
+			return fl.Doc
+		}
+		if doc == nil {
+			doc = fl.Doc
+		}
+	}
+	return doc
+}
+
+// splitGodoc splits a doc comment into its first sentence and the rest. The
+// first sentence ends at the first period followed by a space, or at the
+// end of the first paragraph.
+func splitGodoc(text string) (summary, rest string) {
+	text = strings.TrimSpace(text)
+	end := len(text)
+	if i := strings.Index(text, "\n\n"); i >= 0 {
+		end = i
+	}
+	for i := 0; i < end-1; i++ {
+		if text[i] == '.' && unicode.IsSpace(rune(text[i+1])) {
+			end = i + 1
+			break
+		}
+	}
+
+	summary = strings.Join(strings.Fields(text[:end]), " ")
+	rest = strings.TrimSpace(text[end:])
+	return
+}
+
+// applyGodoc fills in the summary and description of an operation from the
+// godoc of its handler when they are not annotated. The first sentence only
+// goes to the summary, so that a one-line godoc is not shown twice.
+func applyGodoc(opts *swagger.Operation, comments *ast.CommentGroup) {
+	summary, rest := splitGodoc(godocText(comments))
+	if opts.Summary == "" {
+		opts.Summary = summary
+	}
+	if opts.Description == "" {
+		opts.Description = rest
+	}
+}
+
+// parseTagAnnotation parses `@Tag name "description"`.
+func parseTagAnnotation(line string) (name, description string, ok bool) {
+	line = strings.TrimSpace(line)
+	if !strings.HasPrefix(line, "@Tag") {
+		return "", "", false
+	}
+
+	name, pos := peekNextSplitString(strings.TrimSpace(line[len("@Tag"):]))
+	if name == "" {
+		return "", "", false
+	}
+	rest := strings.TrimSpace(strings.TrimSpace(line[len("@Tag"):])[pos:])
+	description = strings.ReplaceAll(strings.Trim(rest, `"`), "\\n", "\n")
+	return name, description, true
+}
+
+// collectTagOverrides records the @Tag annotations found in comments.
+func (g *Generator) collectTagOverrides(comments *ast.CommentGroup) {
+	if comments == nil {
+		return
+	}
+	for _, line := range strings.Split(comments.Text(), "\n") {
+		if name, desc, ok := parseTagAnnotation(line); ok {
+			g.tagOverrides[name] = desc
+		}
+	}
+}
+
+// applyTagOverrides sets the description of the tags named by @Tag
+// annotations, adding the tags that do not exist yet.
+func (g *Generator) applyTagOverrides() {
+	names := make([]string, 0, len(g.tagOverrides))
+	for name := range g.tagOverrides {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		desc := g.tagOverrides[name]
+		found := false
+		for i := range g.rootapi.Tags {
+			if g.rootapi.Tags[i].Name == name {
+				g.rootapi.Tags[i].Description = desc
+				found = true
+			}
+		}
+		if !found {
+			g.rootapi.Tags = append(g.rootapi.Tags, swagger.Tag{
+				Name:        name,
+				Description: desc,
+			})
+		}
+	}
+}
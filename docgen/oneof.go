@@ -0,0 +1,92 @@
+package docgen
+
+import (
+	"sort"
+	"strings"
+)
+
+// parseOneOf documents the concrete types of an interface definition. They
+// are declared either on the interface itself:
+//
+//	// @Discriminator type
+//	// @OneOf banner=BannerWidget carousel=CarouselWidget
+//	type Widget interface{}
+//
+// or in the docs.one_of section of the Beefile, keyed by the definition name.
+// Types without an explicit discriminator value use their own name.
+//
+// Swagger 2 has no oneOf, so the interface gets the discriminator and an
+// x-oneOf list while every implementation extends it through allOf. The
+// OpenAPI 3 output turns these into oneOf with a discriminator mapping.
+func (res *objectResource) parseOneOf() {
+	name := objectWithPackageName(res.object.Name, res.packageName)
+
+	poly := res.g.opts.OneOf[name]
+	mapping := make(map[string]string)
+	for value, typ := range poly.Mapping {
+		mapping[value] = typ
+	}
+
+	if res.doc != nil {
+		for _, c := range res.doc.List {
+			t := strings.TrimSpace(strings.TrimLeft(c.Text, "//"))
+			if strings.HasPrefix(t, "@Discriminator") {
+				poly.Discriminator = strings.TrimSpace(t[len("@Discriminator"):])
+			} else if strings.HasPrefix(t, "@OneOf") {
+				for _, impl := range strings.Fields(t[len("@OneOf"):]) {
+					value, typ := impl, impl
+					if i := strings.Index(impl, "="); i >= 0 {
+						value, typ = impl[:i], impl[i+1:]
+					} else if i := strings.LastIndex(impl, "."); i >= 0 {
+						value = impl[i+1:]
+					}
+					mapping[value] = typ
+				}
+			}
+		}
+	}
+
+	if len(mapping) == 0 {
+		return
+	}
+	if poly.Discriminator == "" {
+		res.g.warnf("%s declares @OneOf without a @Discriminator, defaulting to 'type'", name)
+		poly.Discriminator = "type"
+	}
+
+	values := make([]string, 0, len(mapping))
+	for value := range mapping {
+		values = append(values, value)
+	}
+	sort.Strings(values)
+
+	var refs []interface{}
+	for _, value := range values {
+		impl := mapping[value]
+		if !strings.Contains(impl, ".") {
+			impl = objectWithPackageName(impl, res.packageName)
+		}
+		res.g.appendObjectToRealTypes(res.realTypes, impl, res.pathInfo)
+
+		ref := "#/definitions/" + impl
+		refs = append(refs, map[string]interface{}{"$ref": ref})
+		res.g.docExtras.addDefinitionAllOf(impl, "#/definitions/"+name)
+		res.g.docExtras.setDefinition(impl, "x-discriminator-value", value)
+	}
+
+	enum := make([]interface{}, 0, len(values))
+	for _, value := range values {
+		enum = append(enum, value)
+	}
+	if !contains(res.schema.Required, poly.Discriminator) {
+		res.schema.Required = append(res.schema.Required, poly.Discriminator)
+	}
+	res.g.docExtras.setDefinition(name, "discriminator", poly.Discriminator)
+	res.g.docExtras.setDefinition(name, "properties", map[string]interface{}{
+		poly.Discriminator: map[string]interface{}{
+			"type": "string",
+			"enum": enum,
+		},
+	})
+	res.g.docExtras.setDefinition(name, "x-oneOf", refs)
+}
@@ -0,0 +1,653 @@
+package docgen
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const openAPIVersion = "3.0.3"
+
+// ConvertToOpenAPI3 converts a marshalled swagger 2.0 document into an
+// OpenAPI 3 document. Vendor extensions are carried over as they are.
+func ConvertToOpenAPI3(doc map[string]interface{}) map[string]interface{} {
+	out := map[string]interface{}{
+		"openapi": openAPIVersion,
+		"paths":   map[string]interface{}{},
+	}
+
+	for k, v := range doc {
+		switch k {
+		case "info", "tags", "externalDocs", "security":
+			out[k] = v
+		default:
+			if strings.HasPrefix(k, "x-") {
+				out[k] = v
+			}
+		}
+	}
+	if _, ok := out["info"]; !ok {
+		out["info"] = map[string]interface{}{}
+	}
+
+	if servers := openAPIServers(doc); len(servers) > 0 {
+		out["servers"] = servers
+	}
+
+	components := map[string]interface{}{}
+	if definitions, ok := doc["definitions"].(map[string]interface{}); ok {
+		components["schemas"] = openAPISchemas(definitions)
+	}
+	if security, ok := doc["securityDefinitions"].(map[string]interface{}); ok {
+		components["securitySchemes"] = openAPISecuritySchemes(security)
+	}
+	if len(components) > 0 {
+		out["components"] = components
+	}
+
+	rootConsumes := stringList(doc["consumes"])
+	rootProduces := stringList(doc["produces"])
+
+	paths := out["paths"].(map[string]interface{})
+	if docPaths, ok := doc["paths"].(map[string]interface{}); ok {
+		for rt, it := range docPaths {
+			item, ok := it.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			itemOut := map[string]interface{}{}
+			for method, o := range item {
+				op, ok := o.(map[string]interface{})
+				if !ok {
+					itemOut[method] = o
+					continue
+				}
+				itemOut[method] = openAPIOperation(op, rootConsumes, rootProduces)
+			}
+			paths[rt] = itemOut
+		}
+	}
+
+	return rewriteRefs(out).(map[string]interface{})
+}
+
+func openAPIServers(doc map[string]interface{}) []interface{} {
+	host, _ := doc["host"].(string)
+	basePath, _ := doc["basePath"].(string)
+	if host == "" {
+		if basePath == "" {
+			return nil
+		}
+		return []interface{}{map[string]interface{}{"url": basePath}}
+	}
+
+	schemes := stringList(doc["schemes"])
+	if len(schemes) == 0 {
+		schemes = []string{"http"}
+	}
+	var servers []interface{}
+	for _, scheme := range schemes {
+		servers = append(servers, map[string]interface{}{
+			"url": strings.TrimSpace(scheme) + "://" + host + basePath,
+		})
+	}
+	return servers
+}
+
+// openAPISchemas converts the swagger definitions. Polymorphic definitions
+// declared with x-oneOf become oneOf with a discriminator mapping, and their
+// implementations drop the allOf back-reference which would otherwise make
+// the schemas recursive.
+func openAPISchemas(definitions map[string]interface{}) map[string]interface{} {
+	schemas := make(map[string]interface{}, len(definitions))
+	for name, d := range definitions {
+		schemas[name] = d
+	}
+
+	for name, d := range definitions {
+		def, ok := d.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		oneOf, ok := def["x-oneOf"].([]interface{})
+		if !ok {
+			continue
+		}
+
+		baseRef := "#/definitions/" + name
+		mapping := map[string]interface{}{}
+		for _, r := range oneOf {
+			ref, _ := r.(map[string]interface{})["$ref"].(string)
+			implName := strings.TrimPrefix(ref, "#/definitions/")
+			impl, ok := definitions[implName].(map[string]interface{})
+			if !ok {
+				continue
+			}
+			if value, ok := impl["x-discriminator-value"].(string); ok {
+				mapping[value] = strings.Replace(ref, "#/definitions/", "#/components/schemas/", 1)
+			}
+
+			implOut := copyFields(impl)
+			delete(implOut, "x-discriminator-value")
+			var allOf []interface{}
+			for _, a := range stringMapList(impl["allOf"]) {
+				if a["$ref"] != baseRef {
+					allOf = append(allOf, a)
+				}
+			}
+			if len(allOf) == 0 {
+				delete(implOut, "allOf")
+			} else {
+				implOut["allOf"] = allOf
+			}
+			schemas[implName] = implOut
+		}
+
+		defOut := copyFields(def)
+		delete(defOut, "x-oneOf")
+		delete(defOut, "type")
+		delete(defOut, "properties")
+		delete(defOut, "required")
+		defOut["oneOf"] = oneOf
+		discriminator := map[string]interface{}{"propertyName": def["discriminator"]}
+		if len(mapping) > 0 {
+			discriminator["mapping"] = mapping
+		}
+		defOut["discriminator"] = discriminator
+		schemas[name] = defOut
+	}
+
+	return schemas
+}
+
+func openAPISecuritySchemes(security map[string]interface{}) map[string]interface{} {
+	schemes := make(map[string]interface{}, len(security))
+	for name, s := range security {
+		sec, ok := s.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		out := map[string]interface{}{}
+		if desc, ok := sec["description"]; ok {
+			out["description"] = desc
+		}
+		switch sec["type"] {
+		case "basic":
+			out["type"] = "http"
+			out["scheme"] = "basic"
+		case "apiKey":
+			out["type"] = "apiKey"
+			out["name"] = sec["name"]
+			out["in"] = sec["in"]
+		case "oauth2":
+			out["type"] = "oauth2"
+			flow := map[string]interface{}{"scopes": map[string]interface{}{}}
+			if scopes, ok := sec["scopes"]; ok {
+				flow["scopes"] = scopes
+			}
+			if u, ok := sec["authorizationUrl"]; ok {
+				flow["authorizationUrl"] = u
+			}
+			if u, ok := sec["tokenUrl"]; ok {
+				flow["tokenUrl"] = u
+			}
+			flowName, _ := sec["flow"].(string)
+			switch flowName {
+			case "accessCode":
+				flowName = "authorizationCode"
+			case "application":
+				flowName = "clientCredentials"
+			}
+			out["flows"] = map[string]interface{}{flowName: flow}
+		}
+		schemes[name] = out
+	}
+	return schemes
+}
+
+// openAPIOperation moves body and formData parameters into a requestBody and
+// response schemas into content entries.
+func openAPIOperation(op map[string]interface{}, rootConsumes, rootProduces []string) map[string]interface{} {
+	out := map[string]interface{}{}
+	for k, v := range op {
+		switch k {
+		case "parameters", "responses", "consumes", "produces", "schemes":
+		default:
+			out[k] = v
+		}
+	}
+
+	consumes := stringList(op["consumes"])
+	if len(consumes) == 0 {
+		consumes = rootConsumes
+	}
+	produces := stringList(op["produces"])
+	if len(produces) == 0 {
+		produces = rootProduces
+	}
+	if len(produces) == 0 {
+		produces = []string{ajson}
+	}
+
+	var params []interface{}
+	var formProps = map[string]interface{}{}
+	var formRequired []interface{}
+	for _, p := range stringMapList(op["parameters"]) {
+		switch p["in"] {
+		case "body":
+			bodyConsumes := consumes
+			if len(bodyConsumes) == 0 {
+				bodyConsumes = []string{ajson}
+			}
+			body := map[string]interface{}{"content": mediaTypes(bodyConsumes, p["schema"])}
+			if desc, ok := p["description"]; ok {
+				body["description"] = desc
+			}
+			if req, ok := p["required"]; ok {
+				body["required"] = req
+			}
+			out["requestBody"] = body
+		case "formData":
+			name, _ := p["name"].(string)
+			formProps[name] = parameterSchema(p)
+			if req, _ := p["required"].(bool); req {
+				formRequired = append(formRequired, name)
+			}
+		default:
+			param := map[string]interface{}{"schema": parameterSchema(p)}
+			for k, v := range p {
+				switch k {
+				case "name", "in", "description", "required", "deprecated", "example":
+					param[k] = v
+				default:
+					if strings.HasPrefix(k, "x-") {
+						param[k] = v
+					}
+				}
+			}
+			params = append(params, param)
+		}
+	}
+	if len(params) > 0 {
+		out["parameters"] = params
+	}
+	if len(formProps) > 0 {
+		formConsumes := []string{}
+		for _, c := range consumes {
+			if c == contentTypeMultipartFormData || c == contentTypeFormUrlencoded {
+				formConsumes = append(formConsumes, c)
+			}
+		}
+		if len(formConsumes) == 0 {
+			formConsumes = []string{contentTypeMultipartFormData}
+		}
+		schema := map[string]interface{}{"type": "object", "properties": formProps}
+		if len(formRequired) > 0 {
+			schema["required"] = formRequired
+		}
+		out["requestBody"] = map[string]interface{}{"content": mediaTypes(formConsumes, schema)}
+	}
+
+	responses := map[string]interface{}{}
+	if rs, ok := op["responses"].(map[string]interface{}); ok {
+		for code, r := range rs {
+			resp, ok := r.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			respOut := map[string]interface{}{"description": ""}
+			for k, v := range resp {
+				if k == "schema" {
+					respOut["content"] = mediaTypes(produces, v)
+					continue
+				}
+				respOut[k] = v
+			}
+			responses[code] = respOut
+		}
+	}
+	out["responses"] = responses
+
+	return out
+}
+
+// parameterSchema builds the schema of a non-body swagger 2 parameter.
+func parameterSchema(p map[string]interface{}) map[string]interface{} {
+	schema := map[string]interface{}{}
+	for _, k := range []string{"type", "format", "items", "enum", "default", "pattern",
+		"minimum", "maximum", "minLength", "maxLength"} {
+		if v, ok := p[k]; ok {
+			schema[k] = v
+		}
+	}
+	if schema["type"] == "file" {
+		schema["type"] = "string"
+		schema["format"] = "binary"
+	}
+	return schema
+}
+
+func mediaTypes(contentTypes []string, schema interface{}) map[string]interface{} {
+	content := make(map[string]interface{}, len(contentTypes))
+	for _, ct := range contentTypes {
+		media := map[string]interface{}{}
+		if schema != nil {
+			media["schema"] = schema
+		}
+		content[ct] = media
+	}
+	return content
+}
+
+// rewriteRefs points every swagger 2 definition reference at the OpenAPI 3
+// components and turns swagger 2 discriminators into discriminator objects.
+func rewriteRefs(v interface{}) interface{} {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(t))
+		for k, val := range t {
+			if k == "$ref" {
+				if ref, ok := val.(string); ok {
+					out[k] = strings.Replace(ref, "#/definitions/", "#/components/schemas/", 1)
+					continue
+				}
+			}
+			if k == "discriminator" {
+				if name, ok := val.(string); ok {
+					out[k] = map[string]interface{}{"propertyName": name}
+					continue
+				}
+			}
+			out[k] = rewriteRefs(val)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(t))
+		for i, val := range t {
+			out[i] = rewriteRefs(val)
+		}
+		return out
+	}
+	return v
+}
+
+func copyFields(m map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		out[k] = v
+	}
+	return out
+}
+
+func stringList(v interface{}) []string {
+	list, _ := v.([]interface{})
+	var out []string
+	for _, s := range list {
+		if str, ok := s.(string); ok {
+			out = append(out, str)
+		}
+	}
+	return out
+}
+
+func stringMapList(v interface{}) []map[string]interface{} {
+	list, _ := v.([]interface{})
+	var out []map[string]interface{}
+	for _, m := range list {
+		if mm, ok := m.(map[string]interface{}); ok {
+			out = append(out, mm)
+		}
+	}
+	return out
+}
+
+// ConvertToSwagger2 converts an OpenAPI 3 document into the swagger 2.0
+// form the rest of the package works with. Request bodies become body or
+// formData parameters, and the first content type of a response gives its
+// schema.
+func ConvertToSwagger2(doc map[string]interface{}) map[string]interface{} {
+	out := map[string]interface{}{
+		"swagger": "2.0",
+		"paths":   map[string]interface{}{},
+	}
+	for k, v := range doc {
+		switch k {
+		case "info", "tags", "externalDocs", "security":
+			out[k] = v
+		default:
+			if strings.HasPrefix(k, "x-") {
+				out[k] = v
+			}
+		}
+	}
+
+	if servers := stringMapList(doc["servers"]); len(servers) > 0 {
+		url, _ := servers[0]["url"].(string)
+		if i := strings.Index(url, "://"); i >= 0 {
+			url = url[i+3:]
+			if j := strings.Index(url, "/"); j >= 0 {
+				out["host"], url = url[:j], url[j:]
+			} else {
+				out["host"], url = url, ""
+			}
+		}
+		if url = strings.TrimSuffix(url, "/"); url != "" {
+			out["basePath"] = url
+		}
+	}
+
+	if components, ok := doc["components"].(map[string]interface{}); ok {
+		if schemas, ok := components["schemas"].(map[string]interface{}); ok {
+			out["definitions"] = schemas
+		}
+		if security, ok := components["securitySchemes"].(map[string]interface{}); ok {
+			out["securityDefinitions"] = security
+		}
+	}
+
+	paths := out["paths"].(map[string]interface{})
+	if docPaths, ok := doc["paths"].(map[string]interface{}); ok {
+		for rt, it := range docPaths {
+			item, ok := it.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			common := stringMapList(item["parameters"])
+			itemOut := map[string]interface{}{}
+			for method, o := range item {
+				op, ok := o.(map[string]interface{})
+				if !ok || method == "parameters" {
+					continue
+				}
+				itemOut[method] = swaggerOperation(op, common)
+			}
+			paths[rt] = itemOut
+		}
+	}
+
+	return swaggerRefs(out).(map[string]interface{})
+}
+
+// swaggerOperation is the reverse of openAPIOperation. The parameters of the
+// path item come first unless the operation overrides them.
+func swaggerOperation(op map[string]interface{}, common []map[string]interface{}) map[string]interface{} {
+	out := map[string]interface{}{}
+	for k, v := range op {
+		switch k {
+		case "parameters", "requestBody", "responses", "servers", "callbacks":
+		default:
+			out[k] = v
+		}
+	}
+
+	own := stringMapList(op["parameters"])
+	overridden := make(map[string]bool)
+	for _, p := range own {
+		overridden[fmt.Sprint(p["in"], " ", p["name"])] = true
+	}
+	var inherited []map[string]interface{}
+	for _, p := range common {
+		if !overridden[fmt.Sprint(p["in"], " ", p["name"])] {
+			inherited = append(inherited, p)
+		}
+	}
+	var params []interface{}
+	for _, p := range append(inherited, own...) {
+		param := map[string]interface{}{}
+		for k, v := range p {
+			if k != "schema" && k != "style" && k != "explode" {
+				param[k] = v
+			}
+		}
+		if schema, ok := p["schema"].(map[string]interface{}); ok {
+			for _, k := range []string{"type", "format", "items", "enum", "default", "pattern",
+				"minimum", "maximum", "minLength", "maxLength"} {
+				if v, ok := schema[k]; ok {
+					param[k] = v
+				}
+			}
+		}
+		params = append(params, param)
+	}
+
+	if body, ok := op["requestBody"].(map[string]interface{}); ok {
+		content, _ := body["content"].(map[string]interface{})
+		var types []string
+		for ct := range content {
+			types = append(types, ct)
+		}
+		sort.Strings(types)
+		out["consumes"] = interfaceList(types)
+
+		for _, ct := range types {
+			media, _ := content[ct].(map[string]interface{})
+			schema, _ := media["schema"].(map[string]interface{})
+			if ct == contentTypeMultipartFormData || ct == contentTypeFormUrlencoded {
+				params = append(params, formParameters(schema)...)
+				break
+			}
+			param := map[string]interface{}{"name": "body", "in": "body", "schema": schema}
+			for _, k := range []string{"description", "required"} {
+				if v, ok := body[k]; ok {
+					param[k] = v
+				}
+			}
+			params = append(params, param)
+			break
+		}
+	}
+	if len(params) > 0 {
+		out["parameters"] = params
+	}
+
+	responses := map[string]interface{}{}
+	var produces []string
+	if rs, ok := op["responses"].(map[string]interface{}); ok {
+		for code, r := range rs {
+			resp, ok := r.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			respOut := map[string]interface{}{}
+			for k, v := range resp {
+				if k != "content" && k != "links" {
+					respOut[k] = v
+				}
+			}
+			content, _ := resp["content"].(map[string]interface{})
+			var types []string
+			for ct := range content {
+				types = append(types, ct)
+			}
+			sort.Strings(types)
+			for _, ct := range types {
+				if !contains(produces, ct) {
+					produces = append(produces, ct)
+				}
+			}
+			if len(types) > 0 {
+				media, _ := content[types[0]].(map[string]interface{})
+				if schema, ok := media["schema"]; ok {
+					respOut["schema"] = schema
+				}
+			}
+			responses[code] = respOut
+		}
+	}
+	out["responses"] = responses
+	if len(produces) > 0 {
+		sort.Strings(produces)
+		out["produces"] = interfaceList(produces)
+	}
+	return out
+}
+
+// formParameters turns the properties of a form schema into formData
+// parameters.
+func formParameters(schema map[string]interface{}) []interface{} {
+	props, _ := schema["properties"].(map[string]interface{})
+	required := make(map[string]bool)
+	for _, name := range stringList(schema["required"]) {
+		required[name] = true
+	}
+	names := make([]string, 0, len(props))
+	for name := range props {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var params []interface{}
+	for _, name := range names {
+		prop, _ := props[name].(map[string]interface{})
+		param := map[string]interface{}{"name": name, "in": "formData", "required": required[name]}
+		for k, v := range prop {
+			param[k] = v
+		}
+		if prop["type"] == "string" && prop["format"] == "binary" {
+			param["type"] = "file"
+			delete(param, "format")
+		}
+		params = append(params, param)
+	}
+	return params
+}
+
+func interfaceList(list []string) []interface{} {
+	out := make([]interface{}, len(list))
+	for i, s := range list {
+		out[i] = s
+	}
+	return out
+}
+
+// swaggerRefs points the OpenAPI 3 component references at the swagger 2
+// definitions.
+func swaggerRefs(v interface{}) interface{} {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(t))
+		for k, val := range t {
+			if k == "$ref" {
+				if ref, ok := val.(string); ok {
+					out[k] = strings.Replace(ref, "#/components/schemas/", "#/definitions/", 1)
+					continue
+				}
+			}
+			if k == "discriminator" {
+				if d, ok := val.(map[string]interface{}); ok {
+					out[k] = d["propertyName"]
+					continue
+				}
+			}
+			out[k] = swaggerRefs(val)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(t))
+		for i, val := range t {
+			out[i] = swaggerRefs(val)
+		}
+		return out
+	}
+	return v
+}
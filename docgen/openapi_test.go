@@ -0,0 +1,232 @@
+package docgen
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConvertToOpenAPI3(t *testing.T) {
+	doc := map[string]interface{}{
+		"swagger":  "2.0",
+		"info":     map[string]interface{}{"title": "Shop API"},
+		"basePath": "/v1",
+		"paths": map[string]interface{}{
+			"/widgets": map[string]interface{}{
+				"post": map[string]interface{}{
+					"operationId": "WidgetController.Post",
+					"consumes":    []interface{}{ajson},
+					"parameters": []interface{}{
+						map[string]interface{}{
+							"in":       "body",
+							"name":     "body",
+							"required": true,
+							"schema":   map[string]interface{}{"$ref": "#/definitions/models.Widget"},
+						},
+						map[string]interface{}{
+							"in":      "query",
+							"name":    "lang",
+							"type":    "string",
+							"enum":    []interface{}{"en", "id"},
+							"pattern": "^[a-z]{2}$",
+						},
+					},
+					"responses": map[string]interface{}{
+						"200": map[string]interface{}{
+							"description": "ok",
+							"schema":      map[string]interface{}{"$ref": "#/definitions/models.Widget"},
+						},
+					},
+				},
+			},
+		},
+		"definitions": map[string]interface{}{
+			"models.Widget": map[string]interface{}{
+				"title":         "Widget",
+				"type":          "object",
+				"discriminator": "type",
+				"required":      []interface{}{"type"},
+				"properties": map[string]interface{}{
+					"type": map[string]interface{}{"type": "string", "enum": []interface{}{"banner"}},
+				},
+				"x-oneOf": []interface{}{
+					map[string]interface{}{"$ref": "#/definitions/models.BannerWidget"},
+				},
+			},
+			"models.BannerWidget": map[string]interface{}{
+				"title":                 "BannerWidget",
+				"type":                  "object",
+				"allOf":                 []interface{}{map[string]interface{}{"$ref": "#/definitions/models.Widget"}},
+				"x-discriminator-value": "banner",
+			},
+		},
+	}
+
+	expected := map[string]interface{}{
+		"openapi": openAPIVersion,
+		"info":    map[string]interface{}{"title": "Shop API"},
+		"servers": []interface{}{map[string]interface{}{"url": "/v1"}},
+		"paths": map[string]interface{}{
+			"/widgets": map[string]interface{}{
+				"post": map[string]interface{}{
+					"operationId": "WidgetController.Post",
+					"parameters": []interface{}{
+						map[string]interface{}{
+							"in":   "query",
+							"name": "lang",
+							"schema": map[string]interface{}{
+								"type":    "string",
+								"enum":    []interface{}{"en", "id"},
+								"pattern": "^[a-z]{2}$",
+							},
+						},
+					},
+					"requestBody": map[string]interface{}{
+						"required": true,
+						"content": map[string]interface{}{
+							ajson: map[string]interface{}{
+								"schema": map[string]interface{}{"$ref": "#/components/schemas/models.Widget"},
+							},
+						},
+					},
+					"responses": map[string]interface{}{
+						"200": map[string]interface{}{
+							"description": "ok",
+							"content": map[string]interface{}{
+								ajson: map[string]interface{}{
+									"schema": map[string]interface{}{"$ref": "#/components/schemas/models.Widget"},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		"components": map[string]interface{}{
+			"schemas": map[string]interface{}{
+				"models.Widget": map[string]interface{}{
+					"title": "Widget",
+					"oneOf": []interface{}{
+						map[string]interface{}{"$ref": "#/components/schemas/models.BannerWidget"},
+					},
+					"discriminator": map[string]interface{}{
+						"propertyName": "type",
+						"mapping": map[string]interface{}{
+							"banner": "#/components/schemas/models.BannerWidget",
+						},
+					},
+				},
+				"models.BannerWidget": map[string]interface{}{
+					"title": "BannerWidget",
+					"type":  "object",
+				},
+			},
+		},
+	}
+
+	assert.Equal(t, expected, ConvertToOpenAPI3(doc))
+}
+
+func TestConvertToSwagger2(t *testing.T) {
+	doc := map[string]interface{}{
+		"openapi": "3.0.3",
+		"info":    map[string]interface{}{"title": "Shop API"},
+		"servers": []interface{}{map[string]interface{}{"url": "https://shop.example.com/v1/"}},
+		"paths": map[string]interface{}{
+			"/orders/{id}": map[string]interface{}{
+				"parameters": []interface{}{
+					map[string]interface{}{"name": "id", "in": "path", "required": true, "schema": map[string]interface{}{"type": "integer"}},
+				},
+				"put": map[string]interface{}{
+					"operationId": "updateOrder",
+					"requestBody": map[string]interface{}{
+						"required": true,
+						"content": map[string]interface{}{
+							ajson: map[string]interface{}{
+								"schema": map[string]interface{}{"$ref": "#/components/schemas/Order"},
+							},
+						},
+					},
+					"responses": map[string]interface{}{
+						"200": map[string]interface{}{
+							"description": "ok",
+							"content": map[string]interface{}{
+								ajson: map[string]interface{}{
+									"schema": map[string]interface{}{"$ref": "#/components/schemas/Order"},
+								},
+							},
+						},
+						"404": map[string]interface{}{"description": "not found"},
+					},
+				},
+			},
+			"/orders/{id}/note": map[string]interface{}{
+				"post": map[string]interface{}{
+					"requestBody": map[string]interface{}{
+						"content": map[string]interface{}{
+							contentTypeFormUrlencoded: map[string]interface{}{
+								"schema": map[string]interface{}{
+									"type":       "object",
+									"required":   []interface{}{"note"},
+									"properties": map[string]interface{}{"note": map[string]interface{}{"type": "string"}},
+								},
+							},
+						},
+					},
+					"responses": map[string]interface{}{"204": map[string]interface{}{"description": "saved"}},
+				},
+			},
+		},
+		"components": map[string]interface{}{
+			"schemas": map[string]interface{}{
+				"Order": map[string]interface{}{"type": "object"},
+			},
+		},
+	}
+
+	expected := map[string]interface{}{
+		"swagger":  "2.0",
+		"info":     map[string]interface{}{"title": "Shop API"},
+		"host":     "shop.example.com",
+		"basePath": "/v1",
+		"paths": map[string]interface{}{
+			"/orders/{id}": map[string]interface{}{
+				"put": map[string]interface{}{
+					"operationId": "updateOrder",
+					"consumes":    []interface{}{ajson},
+					"produces":    []interface{}{ajson},
+					"parameters": []interface{}{
+						map[string]interface{}{"name": "id", "in": "path", "required": true, "type": "integer"},
+						map[string]interface{}{
+							"name":     "body",
+							"in":       "body",
+							"required": true,
+							"schema":   map[string]interface{}{"$ref": "#/definitions/Order"},
+						},
+					},
+					"responses": map[string]interface{}{
+						"200": map[string]interface{}{
+							"description": "ok",
+							"schema":      map[string]interface{}{"$ref": "#/definitions/Order"},
+						},
+						"404": map[string]interface{}{"description": "not found"},
+					},
+				},
+			},
+			"/orders/{id}/note": map[string]interface{}{
+				"post": map[string]interface{}{
+					"consumes": []interface{}{contentTypeFormUrlencoded},
+					"parameters": []interface{}{
+						map[string]interface{}{"name": "note", "in": "formData", "required": true, "type": "string"},
+					},
+					"responses": map[string]interface{}{"204": map[string]interface{}{"description": "saved"}},
+				},
+			},
+		},
+		"definitions": map[string]interface{}{
+			"Order": map[string]interface{}{"type": "object"},
+		},
+	}
+
+	assert.Equal(t, expected, ConvertToSwagger2(doc))
+}
@@ -0,0 +1,67 @@
+package docgen
+
+import (
+	"net/http"
+
+	"github.com/zalora/bee/docgen/schema"
+)
+
+// PathParamRegexp matches the parameters of a path template, e.g. {id}.
+var PathParamRegexp = schema.PathParamRegexp
+
+// ValidationError is a value that does not match its schema.
+type ValidationError = schema.ValidationError
+
+// Route matches the request paths of an operation.
+type Route struct {
+	Operation
+	// Template is the path template including the base path, e.g.
+	// /v1/orders/{id}.
+	Template string
+
+	route schema.Route
+}
+
+// Routes returns the routes of the operations. Literal segments win over
+// parameters, e.g. /orders/files comes before /orders/{id}.
+func (s *Spec) Routes() []Route {
+	var routes []Route
+	for _, r := range schema.Document(s.Document).Routes() {
+		routes = append(routes, Route{
+			Operation: Operation{Method: r.Method, Path: r.Path, Op: r.Op},
+			Template:  r.Template,
+			route:     r,
+		})
+	}
+	return routes
+}
+
+// Match returns the path parameters of path when it belongs to r.
+func (r *Route) Match(path string) (map[string]string, bool) {
+	return r.route.Match(path)
+}
+
+// ValidateRequest checks the parameters and the JSON body of req against
+// op. pathParams are the values matched by the route of op. The body is
+// left for the handler.
+func (s *Spec) ValidateRequest(op Operation, req *http.Request, pathParams map[string]string) []ValidationError {
+	return schema.Document(s.Document).ValidateRequest(op.Op, req, pathParams)
+}
+
+// Validate checks value, as decoded by encoding/json, against schema and
+// returns the mismatches. field names the value in the errors.
+func (s *Spec) Validate(field string, sch map[string]interface{}, value interface{}) []ValidationError {
+	return schema.Document(s.Document).Validate(field, sch, value)
+}
+
+// ValidateParameter checks the raw values of a non-body parameter. Missing
+// values are only reported for required parameters.
+func (s *Spec) ValidateParameter(param map[string]interface{}, raw []string) []ValidationError {
+	return schema.Document(s.Document).ValidateParameter(param, raw)
+}
+
+// ParseParameter converts the raw values of a non-body parameter into the
+// value its schema describes, e.g. "42" into 42 for an integer parameter.
+func ParseParameter(param map[string]interface{}, raw []string) (interface{}, error) {
+	return schema.ParseParameter(param, raw)
+}
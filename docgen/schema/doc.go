@@ -0,0 +1,7 @@
+// Package schema validates requests against a swagger 2.0 document in its
+// generic form, as decoded by encoding/json.
+//
+// It only depends on the standard library: bee generate validators copies
+// schema.go into the generated package, so that the apps using it don't
+// depend on bee.
+package schema
@@ -0,0 +1,543 @@
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Document is a swagger 2.0 document as decoded by encoding/json.
+type Document map[string]interface{}
+
+// BasePath returns the base path of the document.
+func (d Document) BasePath() string {
+	basePath, _ := d["basePath"].(string)
+	return strings.TrimSuffix(basePath, "/")
+}
+
+// Definition returns the named definition.
+func (d Document) Definition(name string) map[string]interface{} {
+	definitions, _ := d["definitions"].(map[string]interface{})
+	def, _ := definitions[name].(map[string]interface{})
+	return def
+}
+
+// Resolve follows the $ref of schema. Unknown references resolve to nil.
+func (d Document) Resolve(schema map[string]interface{}) map[string]interface{} {
+	for i := 0; schema != nil && i < 32; i++ {
+		ref, ok := schema["$ref"].(string)
+		if !ok {
+			return schema
+		}
+		schema = d.Definition(strings.TrimPrefix(ref, "#/definitions/"))
+	}
+	return schema
+}
+
+// PathParamRegexp matches the parameters of a path template, e.g. {id}.
+var PathParamRegexp = regexp.MustCompile(`{([^{}]+)}`)
+
+// routeMethods are the methods of a path item, in document order.
+var routeMethods = []string{
+	http.MethodGet, http.MethodPut, http.MethodPost, http.MethodDelete,
+	http.MethodOptions, http.MethodHead, http.MethodPatch,
+}
+
+// Route matches the request paths of an operation.
+type Route struct {
+	Method string // upper case, e.g. GET
+	Path   string // path template relative to the base path
+	// Template is the path template including the base path, e.g.
+	// /v1/orders/{id}.
+	Template string
+	Op       map[string]interface{}
+
+	pattern  *regexp.Regexp
+	params   []string
+	literals int
+}
+
+func newRoute(method, path, template string, op map[string]interface{}) Route {
+	route := Route{Method: method, Path: path, Template: template, Op: op}
+	var pattern strings.Builder
+	pattern.WriteString("^")
+	last := 0
+	for _, loc := range PathParamRegexp.FindAllStringSubmatchIndex(template, -1) {
+		literal := template[last:loc[0]]
+		route.literals += len(literal)
+		pattern.WriteString(regexp.QuoteMeta(literal))
+		pattern.WriteString(`([^/]+?)`)
+		route.params = append(route.params, template[loc[2]:loc[3]])
+		last = loc[1]
+	}
+	route.literals += len(template[last:])
+	pattern.WriteString(regexp.QuoteMeta(template[last:]))
+	pattern.WriteString("/?$")
+	route.pattern = regexp.MustCompile(pattern.String())
+	return route
+}
+
+// Routes returns the routes of the operations, by path and method. Literal
+// segments win over parameters, e.g. /orders/files comes before
+// /orders/{id}.
+func (d Document) Routes() []Route {
+	paths, _ := d["paths"].(map[string]interface{})
+	rts := make([]string, 0, len(paths))
+	for rt := range paths {
+		rts = append(rts, rt)
+	}
+	sort.Strings(rts)
+
+	var routes []Route
+	for _, rt := range rts {
+		item, _ := paths[rt].(map[string]interface{})
+		for _, method := range routeMethods {
+			if op, ok := item[strings.ToLower(method)].(map[string]interface{}); ok {
+				routes = append(routes, newRoute(method, rt, d.BasePath()+rt, op))
+			}
+		}
+	}
+	sort.SliceStable(routes, func(i, j int) bool {
+		return routes[i].literals > routes[j].literals
+	})
+	return routes
+}
+
+// Match returns the path parameters of path when it belongs to r.
+func (r *Route) Match(path string) (map[string]string, bool) {
+	matches := r.pattern.FindStringSubmatch(path)
+	if matches == nil {
+		return nil, false
+	}
+	params := make(map[string]string)
+	for i, name := range r.params {
+		params[name] = matches[i+1]
+	}
+	return params, true
+}
+
+// ValidationError is a value that does not match its schema.
+type ValidationError struct {
+	Field   string `json:"field"` // e.g. body.lines[0].sku
+	Message string `json:"message"`
+}
+
+func (e ValidationError) Error() string {
+	return e.Field + ": " + e.Message
+}
+
+// ValidateRequest checks the parameters and the JSON body of req against
+// the operation op. pathParams are the values matched by its route. The
+// body is left for the handler.
+func (d Document) ValidateRequest(op map[string]interface{}, req *http.Request, pathParams map[string]string) []ValidationError {
+	var errs []ValidationError
+	for _, param := range stringMapList(op["parameters"]) {
+		name, _ := param["name"].(string)
+		var raw []string
+		switch param["in"] {
+		case "path":
+			v, ok := pathParams[name]
+			if !ok {
+				// Not part of the route, bee generate docs leaves it out.
+				continue
+			}
+			raw = []string{v}
+		case "query":
+			raw = req.URL.Query()[name]
+		case "header":
+			raw = req.Header.Values(name)
+		case "formData":
+			req.ParseMultipartForm(32 << 20)
+			raw = req.Form[name]
+			if req.MultipartForm != nil && len(req.MultipartForm.File[name]) > 0 {
+				raw = []string{req.MultipartForm.File[name][0].Filename}
+			}
+		case "body":
+			errs = append(errs, d.validateBody(param, req)...)
+			continue
+		}
+		errs = append(errs, d.ValidateParameter(param, raw)...)
+	}
+	return errs
+}
+
+func (d Document) validateBody(param map[string]interface{}, req *http.Request) []ValidationError {
+	if req.Body == nil {
+		req.Body = http.NoBody
+	}
+	body, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		return []ValidationError{{Field: "body", Message: err.Error()}}
+	}
+	req.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	if len(bytes.TrimSpace(body)) == 0 {
+		if required, _ := param["required"].(bool); required {
+			return []ValidationError{{Field: "body", Message: "is required"}}
+		}
+		return nil
+	}
+	if ct := req.Header.Get("Content-Type"); ct != "" && !strings.Contains(ct, "json") {
+		// Only JSON bodies can be checked against the schema.
+		return nil
+	}
+
+	var v interface{}
+	if err := json.Unmarshal(body, &v); err != nil {
+		return []ValidationError{{Field: "body", Message: "invalid JSON: " + err.Error()}}
+	}
+	schema, _ := param["schema"].(map[string]interface{})
+	return d.Validate("body", schema, v)
+}
+
+// Validate checks value, as decoded by encoding/json, against schema and
+// returns the mismatches. field names the value in the errors.
+//
+// It understands the subset of JSON Schema the generated documents use:
+// $ref, type, enum, pattern, required, properties, additionalProperties,
+// items, allOf, x-oneOf and the minimum/maximum and length keywords.
+func (d Document) Validate(field string, schema map[string]interface{}, value interface{}) []ValidationError {
+	var errs []ValidationError
+	d.validate(field, schema, value, &errs, 0)
+	return errs
+}
+
+func (d Document) validate(field string, schema map[string]interface{}, value interface{}, errs *[]ValidationError, depth int) {
+	if depth > 64 {
+		return
+	}
+	schema = d.Resolve(schema)
+	if len(schema) == 0 {
+		return
+	}
+	fail := func(format string, args ...interface{}) {
+		*errs = append(*errs, ValidationError{Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+
+	for _, part := range stringMapList(schema["allOf"]) {
+		// The base of an interface implementation is checked without its
+		// x-oneOf, which would lead back to the implementation.
+		base := d.Resolve(part)
+		if _, ok := base["x-oneOf"]; ok {
+			base = copyFields(base)
+			delete(base, "x-oneOf")
+		}
+		d.validate(field, base, value, errs, depth+1)
+	}
+
+	if oneOf := stringMapList(schema["x-oneOf"]); len(oneOf) > 0 {
+		d.validateOneOf(field, schema, oneOf, value, errs, depth)
+		return
+	}
+
+	if value == nil {
+		if nullable, _ := schema["x-nullable"].(bool); !nullable && schema["type"] != nil {
+			fail("must not be null")
+		}
+		return
+	}
+
+	if enum, ok := schema["enum"].([]interface{}); ok && len(enum) > 0 {
+		found := false
+		for _, e := range enum {
+			if fmt.Sprint(coerce(fmt.Sprint(schema["type"]), e)) == fmt.Sprint(value) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			fail("must be one of %v", enumList(enum))
+		}
+	}
+
+	typ, _ := schema["type"].(string)
+	if typ == "" && schema["properties"] != nil {
+		typ = "object"
+	}
+	switch typ {
+	case "object":
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			fail("must be an object")
+			return
+		}
+		for _, name := range stringList(schema["required"]) {
+			if _, ok := obj[name]; !ok {
+				*errs = append(*errs, ValidationError{Field: joinField(field, name), Message: "is required"})
+			}
+		}
+		props, _ := schema["properties"].(map[string]interface{})
+		additional, _ := schema["additionalProperties"].(map[string]interface{})
+		names := make([]string, 0, len(obj))
+		for name := range obj {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if prop, ok := props[name].(map[string]interface{}); ok {
+				d.validate(joinField(field, name), prop, obj[name], errs, depth+1)
+			} else if additional != nil {
+				d.validate(joinField(field, name), additional, obj[name], errs, depth+1)
+			}
+		}
+	case "array":
+		list, ok := value.([]interface{})
+		if !ok {
+			fail("must be an array")
+			return
+		}
+		items, _ := schema["items"].(map[string]interface{})
+		for i, item := range list {
+			d.validate(fmt.Sprintf("%s[%d]", field, i), items, item, errs, depth+1)
+		}
+	case "string":
+		str, ok := value.(string)
+		if !ok {
+			fail("must be a string")
+			return
+		}
+		if pattern, ok := schema["pattern"].(string); ok {
+			if re, err := regexp.Compile(pattern); err == nil && !re.MatchString(str) {
+				fail("must match %s", pattern)
+			}
+		}
+		if min, ok := schema["minLength"].(float64); ok && float64(len(str)) < min {
+			fail("must be at least %v characters long", min)
+		}
+		if max, ok := schema["maxLength"].(float64); ok && float64(len(str)) > max {
+			fail("must be at most %v characters long", max)
+		}
+	case "integer", "number":
+		n, ok := value.(float64)
+		if !ok {
+			fail("must be a number")
+			return
+		}
+		if typ == "integer" && n != float64(int64(n)) {
+			fail("must be an integer")
+		}
+		if min, ok := schema["minimum"].(float64); ok && n < min {
+			fail("must be at least %v", min)
+		}
+		if max, ok := schema["maximum"].(float64); ok && n > max {
+			fail("must be at most %v", max)
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			fail("must be a boolean")
+		}
+	}
+}
+
+// validateOneOf checks value against the implementation selected by the
+// discriminator, or against any of them when there is no discriminator.
+func (d Document) validateOneOf(field string, schema map[string]interface{}, oneOf []map[string]interface{}, value interface{}, errs *[]ValidationError, depth int) {
+	if discriminator, ok := schema["discriminator"].(string); ok {
+		obj, _ := value.(map[string]interface{})
+		got, _ := obj[discriminator].(string)
+		for _, impl := range oneOf {
+			if d.Resolve(impl)["x-discriminator-value"] == got {
+				d.validate(field, impl, value, errs, depth+1)
+				return
+			}
+		}
+		*errs = append(*errs, ValidationError{
+			Field:   joinField(field, discriminator),
+			Message: fmt.Sprintf("must be one of %v", schemaEnum(schema, discriminator)),
+		})
+		return
+	}
+
+	for _, impl := range oneOf {
+		var implErrs []ValidationError
+		d.validate(field, impl, value, &implErrs, depth+1)
+		if len(implErrs) == 0 {
+			return
+		}
+	}
+	*errs = append(*errs, ValidationError{Field: field, Message: "does not match any of the allowed types"})
+}
+
+// ParseParameter converts the raw values of a non-body parameter into the
+// value its schema describes, e.g. "42" into 42 for an integer parameter.
+// Arrays are split according to collectionFormat unless they are given
+// several times.
+func ParseParameter(param map[string]interface{}, raw []string) (interface{}, error) {
+	typ, _ := param["type"].(string)
+	if typ != "array" {
+		if len(raw) == 0 {
+			return nil, nil
+		}
+		return parseScalar(typ, raw[0])
+	}
+
+	values := raw
+	if len(raw) == 1 {
+		sep := ","
+		switch param["collectionFormat"] {
+		case "ssv":
+			sep = " "
+		case "tsv":
+			sep = "\t"
+		case "pipes":
+			sep = "|"
+		case "multi":
+			sep = ""
+		}
+		if sep != "" {
+			values = strings.Split(raw[0], sep)
+		}
+	}
+
+	items, _ := param["items"].(map[string]interface{})
+	itemType, _ := items["type"].(string)
+	out := make([]interface{}, 0, len(values))
+	for _, v := range values {
+		parsed, err := parseScalar(itemType, v)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, parsed)
+	}
+	return out, nil
+}
+
+func parseScalar(typ, raw string) (interface{}, error) {
+	switch typ {
+	case "integer":
+		if _, err := strconv.ParseInt(raw, 10, 64); err != nil {
+			return nil, fmt.Errorf("must be an integer")
+		}
+		f, _ := strconv.ParseFloat(raw, 64)
+		return f, nil
+	case "number":
+		f, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return nil, fmt.Errorf("must be a number")
+		}
+		return f, nil
+	case "boolean":
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, fmt.Errorf("must be a boolean")
+		}
+		return b, nil
+	}
+	return raw, nil
+}
+
+// ValidateParameter checks the raw values of a non-body parameter. Missing
+// values are only reported for required parameters.
+func (d Document) ValidateParameter(param map[string]interface{}, raw []string) []ValidationError {
+	in, _ := param["in"].(string)
+	name, _ := param["name"].(string)
+	field := in + "." + name
+
+	if len(raw) == 0 {
+		if required, _ := param["required"].(bool); required {
+			return []ValidationError{{Field: field, Message: "is required"}}
+		}
+		return nil
+	}
+
+	if param["type"] == "file" {
+		return nil
+	}
+	value, err := ParseParameter(param, raw)
+	if err != nil {
+		return []ValidationError{{Field: field, Message: err.Error()}}
+	}
+
+	schema := copyFields(param)
+	for _, k := range []string{"in", "name", "required", "description"} {
+		delete(schema, k)
+	}
+	return d.Validate(field, schema, value)
+}
+
+// coerce converts the string enum values of a schema into its type.
+func coerce(typ string, v interface{}) interface{} {
+	str, ok := v.(string)
+	if !ok {
+		return v
+	}
+	switch typ {
+	case "integer":
+		if i, err := strconv.ParseInt(str, 10, 64); err == nil {
+			return i
+		}
+	case "number":
+		if f, err := strconv.ParseFloat(str, 64); err == nil {
+			return f
+		}
+	case "boolean":
+		if b, err := strconv.ParseBool(str); err == nil {
+			return b
+		}
+	case "array":
+		var out []interface{}
+		for _, item := range strings.Split(str, ",") {
+			out = append(out, strings.TrimSpace(item))
+		}
+		return out
+	}
+	return v
+}
+
+func joinField(field, name string) string {
+	if field == "" {
+		return name
+	}
+	return field + "." + name
+}
+
+func enumList(enum []interface{}) string {
+	values := make([]string, len(enum))
+	for i, e := range enum {
+		values[i] = fmt.Sprint(e)
+	}
+	return "[" + strings.Join(values, ", ") + "]"
+}
+
+func schemaEnum(schema map[string]interface{}, property string) string {
+	props, _ := schema["properties"].(map[string]interface{})
+	prop, _ := props[property].(map[string]interface{})
+	enum, _ := prop["enum"].([]interface{})
+	return enumList(enum)
+}
+
+func copyFields(m map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		out[k] = v
+	}
+	return out
+}
+
+func stringList(v interface{}) []string {
+	list, _ := v.([]interface{})
+	var out []string
+	for _, s := range list {
+		if str, ok := s.(string); ok {
+			out = append(out, str)
+		}
+	}
+	return out
+}
+
+func stringMapList(v interface{}) []map[string]interface{} {
+	list, _ := v.([]interface{})
+	var out []map[string]interface{}
+	for _, m := range list {
+		if mm, ok := m.(map[string]interface{}); ok {
+			out = append(out, mm)
+		}
+	}
+	return out
+}
@@ -0,0 +1,179 @@
+package docgen
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/zalora/bee/docgen/schema"
+	"gopkg.in/yaml.v3"
+)
+
+// LoadSpec reads a generated swagger.json or swagger.yml. OpenAPI 3
+// documents are converted to swagger 2.0.
+func LoadSpec(filename string) (*Spec, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc map[string]interface{}
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".yml", ".yaml":
+		err = yaml.Unmarshal(data, &doc)
+		if err == nil {
+			// Bring the document to the form encoding/json produces.
+			data, err = json.Marshal(doc)
+		}
+		if err == nil {
+			doc = nil
+			err = json.Unmarshal(data, &doc)
+		}
+	default:
+		err = json.Unmarshal(data, &doc)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not parse %s: %v", filename, err)
+	}
+	if _, ok := doc["openapi"]; ok {
+		doc = ConvertToSwagger2(doc)
+		if data, err = json.Marshal(doc); err != nil {
+			return nil, err
+		}
+	}
+
+	spec := &Spec{Document: doc}
+	if err := json.Unmarshal(data, &spec.Swagger); err != nil {
+		return nil, fmt.Errorf("could not parse %s: %v", filename, err)
+	}
+	return spec, nil
+}
+
+// Operation is an operation of the document in its generic form.
+type Operation struct {
+	Method string // upper case, e.g. GET
+	Path   string // path template relative to the base path
+	Op     map[string]interface{}
+}
+
+// ID returns the operationId, or the method and path when there is none.
+func (o Operation) ID() string {
+	if id, ok := o.Op["operationId"].(string); ok && id != "" {
+		return id
+	}
+	return o.Method + " " + o.Path
+}
+
+// Parameters returns the parameters of the operation.
+func (o Operation) Parameters() []map[string]interface{} {
+	return stringMapList(o.Op["parameters"])
+}
+
+// Parameter returns the parameter with the given location and name.
+func (o Operation) Parameter(in, name string) map[string]interface{} {
+	for _, p := range o.Parameters() {
+		if p["in"] == in && p["name"] == name {
+			return p
+		}
+	}
+	return nil
+}
+
+// Responses returns the responses of the operation keyed by status code.
+func (o Operation) Responses() map[string]map[string]interface{} {
+	responses := make(map[string]map[string]interface{})
+	if rs, ok := o.Op["responses"].(map[string]interface{}); ok {
+		for code, r := range rs {
+			if resp, ok := r.(map[string]interface{}); ok {
+				responses[code] = resp
+			}
+		}
+	}
+	return responses
+}
+
+// Tags returns the tags of the operation.
+func (o Operation) Tags() []string {
+	return stringList(o.Op["tags"])
+}
+
+// operationMethods are the methods of a path item, in document order.
+var operationMethods = []string{
+	http.MethodGet, http.MethodPut, http.MethodPost, http.MethodDelete,
+	http.MethodOptions, http.MethodHead, http.MethodPatch,
+}
+
+// BasePath returns the base path of the document.
+func (s *Spec) BasePath() string {
+	return schema.Document(s.Document).BasePath()
+}
+
+// Operations returns the operations of the document sorted by path and
+// method.
+func (s *Spec) Operations() []Operation {
+	paths, _ := s.Document["paths"].(map[string]interface{})
+	rts := make([]string, 0, len(paths))
+	for rt := range paths {
+		rts = append(rts, rt)
+	}
+	sort.Strings(rts)
+
+	var ops []Operation
+	for _, rt := range rts {
+		item, _ := paths[rt].(map[string]interface{})
+		for _, method := range operationMethods {
+			if op, ok := item[strings.ToLower(method)].(map[string]interface{}); ok {
+				ops = append(ops, Operation{Method: method, Path: rt, Op: op})
+			}
+		}
+	}
+	return ops
+}
+
+// Consumes returns the content types accepted by op.
+func (s *Spec) Consumes(op Operation) []string {
+	if consumes := stringList(op.Op["consumes"]); len(consumes) > 0 {
+		return consumes
+	}
+	return stringList(s.Document["consumes"])
+}
+
+// Produces returns the content types returned by op.
+func (s *Spec) Produces(op Operation) []string {
+	if produces := stringList(op.Op["produces"]); len(produces) > 0 {
+		return produces
+	}
+	return stringList(s.Document["produces"])
+}
+
+// Resolve follows the $ref of schema. Unknown references resolve to nil.
+func (s *Spec) Resolve(sch map[string]interface{}) map[string]interface{} {
+	return schema.Document(s.Document).Resolve(sch)
+}
+
+// Definition returns the named definition.
+func (s *Spec) Definition(name string) map[string]interface{} {
+	return schema.Document(s.Document).Definition(name)
+}
+
+// Definitions returns the names of the definitions in sorted order.
+func (s *Spec) Definitions() []string {
+	definitions, _ := s.Document["definitions"].(map[string]interface{})
+	names := make([]string, 0, len(definitions))
+	for name := range definitions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// RefName returns the definition name of a $ref, or "" when schema is not
+// a reference.
+func RefName(schema map[string]interface{}) string {
+	ref, _ := schema["$ref"].(string)
+	return strings.TrimPrefix(ref, "#/definitions/")
+}
@@ -0,0 +1,234 @@
+package docgen
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testSpec = `{
+	"basePath": "/v1",
+	"paths": {
+		"/orders/{id}": {
+			"get": {
+				"operationId": "OrderController.Get",
+				"parameters": [
+					{"in": "path", "name": "id", "required": true, "type": "integer"},
+					{"in": "query", "name": "status", "type": "string", "enum": ["open", "closed"]},
+					{"in": "query", "name": "ids", "type": "array", "items": {"type": "integer"}}
+				],
+				"responses": {"200": {"schema": {"$ref": "#/definitions/models.Order"}}}
+			}
+		}
+	},
+	"definitions": {
+		"models.Order": {
+			"type": "object",
+			"required": ["id"],
+			"properties": {
+				"id": {"type": "integer", "format": "int64", "example": "42"},
+				"created": {"type": "string", "format": "date-time"},
+				"status": {"type": "string", "enum": ["open", "closed"]},
+				"lines": {"type": "array", "items": {"$ref": "#/definitions/models.Line"}},
+				"parent": {"$ref": "#/definitions/models.Order"},
+				"widget": {"$ref": "#/definitions/models.Widget"}
+			}
+		},
+		"models.Line": {
+			"type": "object",
+			"properties": {"sku": {"type": "string", "pattern": "^[A-Z]+$"}}
+		},
+		"models.Widget": {
+			"type": "object",
+			"discriminator": "type",
+			"required": ["type"],
+			"properties": {"type": {"type": "string", "enum": ["banner", "carousel"]}},
+			"x-oneOf": [
+				{"$ref": "#/definitions/models.BannerWidget"},
+				{"$ref": "#/definitions/models.CarouselWidget"}
+			]
+		},
+		"models.BannerWidget": {
+			"type": "object",
+			"allOf": [{"$ref": "#/definitions/models.Widget"}],
+			"properties": {"type": {"type": "string"}, "image": {"type": "string"}},
+			"x-discriminator-value": "banner"
+		},
+		"models.CarouselWidget": {
+			"type": "object",
+			"allOf": [{"$ref": "#/definitions/models.Widget"}],
+			"properties": {"type": {"type": "string"}, "slides": {"type": "array", "items": {"type": "string"}}},
+			"x-discriminator-value": "carousel"
+		}
+	}
+}`
+
+func newTestSpec(t *testing.T) *Spec {
+	spec := &Spec{}
+	assert.NoError(t, json.Unmarshal([]byte(testSpec), &spec.Document))
+	return spec
+}
+
+func TestOperations(t *testing.T) {
+	spec := newTestSpec(t)
+	ops := spec.Operations()
+
+	assert.Len(t, ops, 1)
+	assert.Equal(t, "GET", ops[0].Method)
+	assert.Equal(t, "/orders/{id}", ops[0].Path)
+	assert.Equal(t, "OrderController.Get", ops[0].ID())
+	assert.Equal(t, "integer", ops[0].Parameter("path", "id")["type"])
+	assert.Equal(t, "/v1", spec.BasePath())
+}
+
+func TestExample(t *testing.T) {
+	spec := newTestSpec(t)
+
+	assert.Equal(t, map[string]interface{}{
+		"id":      int64(42),
+		"created": "2006-01-02T15:04:05Z",
+		"status":  "open",
+		"lines": []interface{}{
+			map[string]interface{}{"sku": "string"},
+		},
+		"parent": nil,
+		"widget": map[string]interface{}{
+			"type":  "banner",
+			"image": "string",
+		},
+	}, spec.Example(map[string]interface{}{"$ref": "#/definitions/models.Order"}))
+}
+
+func TestValidate(t *testing.T) {
+	spec := newTestSpec(t)
+	order := map[string]interface{}{"$ref": "#/definitions/models.Order"}
+
+	tests := []struct {
+		desc     string
+		body     string
+		expected []ValidationError
+	}{
+		{
+			desc: "valid body",
+			body: `{"id": 1, "status": "open", "lines": [{"sku": "ABC"}], "widget": {"type": "carousel", "slides": ["a"]}}`,
+		},
+		{
+			desc: "missing required property",
+			body: `{"status": "open"}`,
+			expected: []ValidationError{
+				{Field: "body.id", Message: "is required"},
+			},
+		},
+		{
+			desc: "wrong types and values",
+			body: `{"id": 1.5, "status": "lost", "lines": [{"sku": "abc"}, "x"]}`,
+			expected: []ValidationError{
+				{Field: "body.id", Message: "must be an integer"},
+				{Field: "body.lines[0].sku", Message: "must match ^[A-Z]+$"},
+				{Field: "body.lines[1]", Message: "must be an object"},
+				{Field: "body.status", Message: "must be one of [open, closed]"},
+			},
+		},
+		{
+			desc: "implementation selected by the discriminator",
+			body: `{"id": 1, "widget": {"type": "carousel", "slides": "a"}}`,
+			expected: []ValidationError{
+				{Field: "body.widget.slides", Message: "must be an array"},
+			},
+		},
+		{
+			desc: "unknown discriminator value",
+			body: `{"id": 1, "widget": {"type": "video"}}`,
+			expected: []ValidationError{
+				{Field: "body.widget.type", Message: "must be one of [banner, carousel]"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			var body interface{}
+			assert.NoError(t, json.Unmarshal([]byte(tt.body), &body))
+			assert.Equal(t, tt.expected, spec.Validate("body", order, body))
+		})
+	}
+}
+
+func TestValidateParameter(t *testing.T) {
+	spec := newTestSpec(t)
+	op := spec.Operations()[0]
+
+	tests := []struct {
+		desc     string
+		in       string
+		name     string
+		raw      []string
+		expected []ValidationError
+	}{
+		{
+			desc: "valid integer",
+			in:   "path",
+			name: "id",
+			raw:  []string{"42"},
+		},
+		{
+			desc:     "invalid integer",
+			in:       "path",
+			name:     "id",
+			raw:      []string{"abc"},
+			expected: []ValidationError{{Field: "path.id", Message: "must be an integer"}},
+		},
+		{
+			desc:     "missing required parameter",
+			in:       "path",
+			name:     "id",
+			expected: []ValidationError{{Field: "path.id", Message: "is required"}},
+		},
+		{
+			desc: "missing optional parameter",
+			in:   "query",
+			name: "status",
+		},
+		{
+			desc:     "value out of enum",
+			in:       "query",
+			name:     "status",
+			raw:      []string{"lost"},
+			expected: []ValidationError{{Field: "query.status", Message: "must be one of [open, closed]"}},
+		},
+		{
+			desc:     "csv array",
+			in:       "query",
+			name:     "ids",
+			raw:      []string{"1,x"},
+			expected: []ValidationError{{Field: "query.ids", Message: "must be an integer"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			assert.Equal(t, tt.expected, spec.ValidateParameter(op.Parameter(tt.in, tt.name), tt.raw))
+		})
+	}
+}
+
+func TestValidateRequest(t *testing.T) {
+	spec := newTestSpec(t)
+	routes := spec.Routes()
+	assert.Len(t, routes, 1)
+	assert.Equal(t, "/v1/orders/{id}", routes[0].Template)
+
+	_, ok := routes[0].Match("/v1/orders")
+	assert.False(t, ok)
+	params, ok := routes[0].Match("/v1/orders/abc/")
+	assert.True(t, ok)
+	assert.Equal(t, map[string]string{"id": "abc"}, params)
+
+	req := httptest.NewRequest("GET", "/v1/orders/abc?status=lost&ids=1,2", nil)
+	assert.Equal(t, []ValidationError{
+		{Field: "path.id", Message: "must be an integer"},
+		{Field: "query.status", Message: "must be one of [open, closed]"},
+	}, spec.ValidateRequest(routes[0].Operation, req, params))
+}
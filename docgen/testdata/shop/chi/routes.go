@@ -0,0 +1,19 @@
+package router
+
+import (
+	"github.com/acme/shop/pkg/handlers/carts"
+	"github.com/go-chi/chi"
+)
+
+func New() chi.Router {
+	mux := chi.NewRouter()
+	mux.Group(func(r chi.Router) {
+		r.Route("/v2", func(r chi.Router) {
+			r.Route("/carts", func(r chi.Router) {
+				r.Get("/{cartID}", carts.Get)
+				r.Post("/", carts.Create)
+			})
+		})
+	})
+	return mux
+}
@@ -0,0 +1,27 @@
+package controllers
+
+import "github.com/astaxie/beego"
+
+// Operations about carts
+type CartController struct {
+	beego.Controller
+}
+
+// @Title Get
+// @Summary get a cart
+// @Param	id		path 	int	true		"The cart id"
+// @Success 200 {object} models.Order
+// @router /:id [get]
+func (c *CartController) Get() {}
+
+// Clear empties the cart.
+func (c *CartController) Clear() {}
+
+// LegacyController is not registered by the router.
+type LegacyController struct {
+	beego.Controller
+}
+
+// @Title List
+// @router /legacy [get]
+func (l *LegacyController) List() {}
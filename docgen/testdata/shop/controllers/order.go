@@ -0,0 +1,20 @@
+package controllers
+
+import "github.com/astaxie/beego"
+
+// Operations about orders
+type OrderController struct {
+	beego.Controller
+}
+
+// @Title Get
+// @Summary get an order
+// @Param	id		path 	string	true		"The order id"
+// @Success 200 {object} models.Order
+// @Failure 404 not found
+// @router /:id([0-9]+) [get]
+func (o *OrderController) Get() {}
+
+// @Title Cancel
+// @router /:id([0-9]+)/cancel [post]
+func (o *OrderController) Cancel() {}
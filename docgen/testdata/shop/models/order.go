@@ -0,0 +1,13 @@
+package models
+
+type Order struct {
+	ID     int64   `json:"id" required:"true"`
+	Total  float64 `json:"total" description:"order total"`
+	Lines  []Line  `json:"lines"`
+	Widget Widget  `json:"widget"`
+	Media  Media   `json:"media"`
+}
+
+type Line struct {
+	SKU string `json:"sku"`
+}
@@ -0,0 +1,18 @@
+package models
+
+// @Discriminator type
+// @OneOf banner=Banner
+type Widget interface{}
+
+// @Discriminator kind
+// @OneOf Banner
+type Media interface{}
+
+type Block struct {
+	Title string `json:"title"`
+}
+
+type Banner struct {
+	Block
+	Image string `json:"image"`
+}
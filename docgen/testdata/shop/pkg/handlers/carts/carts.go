@@ -0,0 +1,13 @@
+package carts
+
+import "net/http"
+
+// Get returns a cart.
+// @Param	cartID	path	string	true	"The cart id"
+// @Success 200 ok
+// @Failure 404 not found
+// @router /v2/carts/{cartID} [get]
+func Get(w http.ResponseWriter, r *http.Request) {}
+
+// Create is not annotated.
+func Create(w http.ResponseWriter, r *http.Request) {}
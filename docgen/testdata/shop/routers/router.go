@@ -0,0 +1,24 @@
+// @APIVersion 1.0.0
+// @Title Shop API
+// @Description shop api
+package routers
+
+import (
+	"github.com/acme/shop/controllers"
+
+	"github.com/astaxie/beego"
+)
+
+func init() {
+	ns := beego.NewNamespace("/v1",
+		beego.NSNamespace("/orders",
+			beego.NSInclude(
+				&controllers.OrderController{},
+			),
+		),
+		beego.NSNamespace("/carts",
+			beego.NSRouter("/:id", &controllers.CartController{}, "get:Get;delete:Clear"),
+		),
+	)
+	beego.AddNamespace(ns)
+}
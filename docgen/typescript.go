@@ -0,0 +1,594 @@
+package docgen
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"github.com/zalora/bee/docgen/schema"
+)
+
+// TypeScript returns a TypeScript module with an interface per definition,
+// union types for enums and interfaces, and a typed fetch wrapper per
+// operation.
+func (s *Spec) TypeScript() ([]byte, error) {
+	ts := &tsGen{spec: s, names: make(map[string]string)}
+	data := ts.data()
+	var buf bytes.Buffer
+	if err := tsTemplate.Execute(&buf, data); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+type tsGen struct {
+	spec  *Spec
+	names map[string]string // definition name -> TypeScript name
+}
+
+type tsData struct {
+	Title      string
+	Models     []tsModel
+	Operations []tsOperation
+}
+
+type tsModel struct {
+	Name    string
+	Doc     []string
+	Type    string // type alias, empty for interfaces
+	Extends []string
+	Fields  []tsField
+}
+
+type tsField struct {
+	Name     string
+	Type     string
+	Optional bool
+	Doc      []string
+}
+
+type tsOperation struct {
+	Name     string
+	Doc      []string
+	Method   string
+	Path     string // template literal of the request path
+	Params   string
+	Fields   []tsField
+	Query    []tsParam
+	Headers  []tsParam
+	Form     []tsParam
+	Body     string // access expression of the body parameter
+	Accept   string
+	Consumes string
+	Result   string
+}
+
+type tsParam struct {
+	Name  string
+	Value string
+}
+
+var tsIdentifierRegexp = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+func (ts *tsGen) data() tsData {
+	data := tsData{Title: "the API"}
+	if info, ok := ts.spec.Document["info"].(map[string]interface{}); ok {
+		if title, ok := info["title"].(string); ok && title != "" {
+			data.Title = title
+		}
+	}
+
+	// Short names first, the package qualified name on collisions.
+	short := make(map[string]int)
+	for _, name := range ts.spec.Definitions() {
+		short[tsTypeName(name[strings.LastIndex(name, ".")+1:])]++
+	}
+	for _, name := range ts.spec.Definitions() {
+		ident := tsTypeName(name[strings.LastIndex(name, ".")+1:])
+		if short[ident] > 1 {
+			ident = tsTypeName(name)
+		}
+		ts.names[name] = ident
+	}
+
+	for _, name := range ts.spec.Definitions() {
+		data.Models = append(data.Models, ts.models(name)...)
+	}
+
+	taken := make(map[string]bool)
+	for _, op := range ts.spec.Operations() {
+		o := ts.operation(op)
+		for base, i := o.Name, 2; taken[o.Name]; i++ {
+			o.Name = base + strconv.Itoa(i)
+		}
+		taken[o.Name] = true
+		if o.Params != "" {
+			o.Params = strings.ToUpper(o.Name[:1]) + o.Name[1:] + "Params"
+		}
+		data.Operations = append(data.Operations, o)
+	}
+	return data
+}
+
+// models returns the declarations of a definition. Interface definitions
+// become a union of their implementations plus the interface of their
+// common properties, suffixed with Base.
+func (ts *tsGen) models(name string) []tsModel {
+	def := ts.spec.Definition(name)
+	m := tsModel{Name: ts.names[name]}
+	if desc, ok := def["description"].(string); ok {
+		m.Doc = tsDoc(desc)
+	}
+
+	if !isObject(def) {
+		m.Type = ts.tsType(def)
+		return []tsModel{m}
+	}
+
+	var models []tsModel
+	if impls := ts.implementations(def); len(impls) > 0 {
+		models = append(models, tsModel{Name: m.Name, Doc: m.Doc, Type: strings.Join(impls, " | ")})
+		m.Name += "Base"
+		m.Doc = nil
+	}
+
+	// allOf parts are extended, the discriminator of an interface gets the
+	// value of the implementation.
+	discriminator := ""
+	for _, part := range stringMapList(def["allOf"]) {
+		base := RefName(part)
+		if base == "" || ts.names[base] == "" {
+			continue
+		}
+		parent := ts.names[base]
+		if len(ts.implementations(ts.spec.Definition(base))) > 0 {
+			parent += "Base"
+			discriminator, _ = ts.spec.Definition(base)["discriminator"].(string)
+		}
+		m.Extends = append(m.Extends, parent)
+	}
+
+	m.Fields = ts.fields(def)
+	if value, ok := def["x-discriminator-value"].(string); ok && discriminator != "" {
+		found := false
+		for i := range m.Fields {
+			if m.Fields[i].Name == tsKey(discriminator) {
+				m.Fields[i].Type = strconv.Quote(value)
+				m.Fields[i].Optional = false
+				found = true
+			}
+		}
+		if !found {
+			m.Fields = append(m.Fields, tsField{Name: tsKey(discriminator), Type: strconv.Quote(value)})
+		}
+	}
+	return append(models, m)
+}
+
+func (ts *tsGen) implementations(schema map[string]interface{}) []string {
+	var names []string
+	for _, impl := range stringMapList(schema["x-oneOf"]) {
+		if name, ok := ts.names[RefName(impl)]; ok {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+func (ts *tsGen) fields(schema map[string]interface{}) []tsField {
+	props, _ := schema["properties"].(map[string]interface{})
+	required := make(map[string]bool)
+	for _, name := range stringList(schema["required"]) {
+		required[name] = true
+	}
+
+	names := make([]string, 0, len(props))
+	for name := range props {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var fields []tsField
+	for _, name := range names {
+		prop, _ := props[name].(map[string]interface{})
+		f := tsField{Name: tsKey(name), Type: ts.tsType(prop), Optional: !required[name]}
+		if desc, ok := prop["description"].(string); ok {
+			f.Doc = tsDoc(desc)
+		}
+		fields = append(fields, f)
+	}
+	return fields
+}
+
+func isObject(schema map[string]interface{}) bool {
+	_, props := schema["properties"]
+	_, allOf := schema["allOf"]
+	_, additional := schema["additionalProperties"]
+	return props || allOf || (schema["type"] == "object" && !additional)
+}
+
+// tsType returns the TypeScript type of schema.
+func (ts *tsGen) tsType(schema map[string]interface{}) string {
+	if schema == nil {
+		return "unknown"
+	}
+	if name := RefName(schema); name != "" {
+		if ident, ok := ts.names[name]; ok {
+			return ident
+		}
+		return "unknown"
+	}
+	if impls := ts.implementations(schema); len(impls) > 0 {
+		return strings.Join(impls, " | ")
+	}
+	if enum, ok := schema["enum"].([]interface{}); ok && len(enum) > 0 {
+		values := make([]string, len(enum))
+		for i, v := range enum {
+			if str, ok := v.(string); ok && schema["type"] != "integer" && schema["type"] != "number" {
+				values[i] = strconv.Quote(str)
+			} else {
+				values[i] = fmt.Sprint(v)
+			}
+		}
+		return strings.Join(values, " | ")
+	}
+
+	typ, _ := schema["type"].(string)
+	switch typ {
+	case "array":
+		items, _ := schema["items"].(map[string]interface{})
+		item := ts.tsType(items)
+		if strings.Contains(item, " ") {
+			return "Array<" + item + ">"
+		}
+		return item + "[]"
+	case "object":
+		if additional, ok := schema["additionalProperties"].(map[string]interface{}); ok {
+			return "Record<string, " + ts.tsType(additional) + ">"
+		}
+		if _, ok := schema["properties"]; !ok {
+			return "Record<string, unknown>"
+		}
+	case "string":
+		return "string"
+	case "integer", "number":
+		return "number"
+	case "boolean":
+		return "boolean"
+	case "file":
+		return "Blob"
+	}
+	if props, ok := schema["properties"].(map[string]interface{}); ok && len(props) > 0 {
+		var b strings.Builder
+		b.WriteString("{ ")
+		for _, f := range ts.fields(schema) {
+			b.WriteString(f.Name)
+			if f.Optional {
+				b.WriteString("?")
+			}
+			b.WriteString(": " + f.Type + "; ")
+		}
+		b.WriteString("}")
+		return b.String()
+	}
+	return "unknown"
+}
+
+func (ts *tsGen) operation(op Operation) tsOperation {
+	rt := ts.spec.BasePath() + op.Path
+	o := tsOperation{
+		Name:   tsFuncName(op),
+		Method: op.Method,
+		Accept: strconv.Quote(strings.Join(ts.spec.Produces(op), ", ")),
+	}
+	if consumes := ts.spec.Consumes(op); len(consumes) > 0 {
+		o.Consumes = strconv.Quote(consumes[0])
+	}
+
+	for _, key := range []string{"summary", "description"} {
+		if text, ok := op.Op[key].(string); ok && text != "" {
+			o.Doc = append(o.Doc, tsDoc(text)...)
+			o.Doc = append(o.Doc, "")
+		}
+	}
+	o.Doc = append(o.Doc, op.Method+" "+rt)
+	if deprecated, _ := op.Op["deprecated"].(bool); deprecated {
+		note := "@deprecated"
+		if replacement, ok := op.Op["x-replaced-by"].(string); ok {
+			note += " Use " + replacement + " instead."
+		}
+		o.Doc = append(o.Doc, note)
+	}
+
+	access := func(name string) string {
+		if tsIdentifierRegexp.MatchString(name) {
+			return "params." + name
+		}
+		return "params[" + strconv.Quote(name) + "]"
+	}
+
+	// Path parameters follow the route, those that are not part of it can
+	// not be sent.
+	var path strings.Builder
+	last := 0
+	for _, loc := range schema.PathParamRegexp.FindAllStringSubmatchIndex(rt, -1) {
+		path.WriteString(tsTemplateEscape(rt[last:loc[0]]))
+		name := rt[loc[2]:loc[3]]
+		param := op.Parameter("path", name)
+		if param == nil {
+			param = map[string]interface{}{"name": name, "type": "string"}
+		}
+		o.Fields = append(o.Fields, ts.paramField(param, true))
+		if name == "splat" {
+			path.WriteString("${String(" + access(name) + ")}")
+		} else {
+			path.WriteString("${encodeURIComponent(String(" + access(name) + "))}")
+		}
+		last = loc[1]
+	}
+	path.WriteString(tsTemplateEscape(rt[last:]))
+	o.Path = "`" + path.String() + "`"
+
+	for _, param := range op.Parameters() {
+		name, _ := param["name"].(string)
+		required, _ := param["required"].(bool)
+		switch param["in"] {
+		case "query":
+			o.Query = append(o.Query, tsParam{Name: strconv.Quote(name), Value: access(name)})
+		case "header":
+			o.Headers = append(o.Headers, tsParam{Name: strconv.Quote(name), Value: access(name)})
+		case "formData":
+			o.Form = append(o.Form, tsParam{Name: strconv.Quote(name), Value: access(name)})
+		case "body":
+			schema, _ := param["schema"].(map[string]interface{})
+			f := tsField{Name: tsKey(name), Type: ts.tsType(schema), Optional: !required}
+			if desc, ok := param["description"].(string); ok {
+				f.Doc = tsDoc(desc)
+			}
+			o.Fields = append(o.Fields, f)
+			o.Body = access(name)
+			continue
+		default:
+			continue
+		}
+		o.Fields = append(o.Fields, ts.paramField(param, required))
+	}
+	if len(o.Fields) > 0 {
+		o.Params = "params"
+	}
+
+	o.Result = "void"
+	responses := op.Responses()
+	if resp, ok := responses[successStatus(responses)]; ok {
+		if schema, ok := resp["schema"].(map[string]interface{}); ok {
+			o.Result = ts.tsType(schema)
+		}
+	}
+
+	var failures []string
+	for status, resp := range responses {
+		if strings.HasPrefix(status, "2") {
+			continue
+		}
+		typ := "unknown"
+		if schema, ok := resp["schema"].(map[string]interface{}); ok {
+			typ = ts.tsType(schema)
+		}
+		desc, _ := resp["description"].(string)
+		failures = append(failures, strings.TrimSpace(fmt.Sprintf("@throws {ApiError<%s>} %s %s", typ, status, desc)))
+	}
+	sort.Strings(failures)
+	o.Doc = append(o.Doc, failures...)
+	return o
+}
+
+func (ts *tsGen) paramField(param map[string]interface{}, required bool) tsField {
+	name, _ := param["name"].(string)
+	f := tsField{Name: tsKey(name), Type: ts.tsType(param), Optional: !required}
+	if desc, ok := param["description"].(string); ok {
+		f.Doc = tsDoc(desc)
+	}
+	return f
+}
+
+// successStatus returns the lowest documented 2xx status, then "default".
+func successStatus(responses map[string]map[string]interface{}) string {
+	var codes []string
+	for code := range responses {
+		if strings.HasPrefix(code, "2") {
+			codes = append(codes, code)
+		}
+	}
+	sort.Strings(codes)
+	if len(codes) > 0 {
+		return codes[0]
+	}
+	return "default"
+}
+
+// tsFuncName turns OrderController.Get into orderGet, operations without
+// an operationId are named after their method and path.
+func tsFuncName(op Operation) string {
+	name := strings.ToLower(op.Method) + " " + op.Path
+	if id, ok := op.Op["operationId"].(string); ok && id != "" {
+		parts := strings.Split(id, ".")
+		for i, part := range parts {
+			parts[i] = strings.TrimSuffix(part, "Controller")
+		}
+		name = strings.Join(parts, " ")
+	}
+	ident := tsTypeName(name)
+	return strings.ToLower(ident[:1]) + ident[1:]
+}
+
+// tsTypeName turns a name into a PascalCase identifier.
+func tsTypeName(name string) string {
+	words := strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	var b strings.Builder
+	for _, word := range words {
+		runes := []rune(word)
+		runes[0] = unicode.ToUpper(runes[0])
+		b.WriteString(string(runes))
+	}
+	ident := b.String()
+	if ident == "" || unicode.IsDigit([]rune(ident)[0]) {
+		ident = "T" + ident
+	}
+	return ident
+}
+
+// tsKey returns name as a property key, quoted when it is not an
+// identifier.
+func tsKey(name string) string {
+	if tsIdentifierRegexp.MatchString(name) {
+		return name
+	}
+	return strconv.Quote(name)
+}
+
+func tsTemplateEscape(s string) string {
+	return strings.NewReplacer("\\", "\\\\", "`", "\\`", "${", "\\${").Replace(s)
+}
+
+// tsDoc splits a description into the lines of a doc comment.
+func tsDoc(s string) []string {
+	s = strings.ReplaceAll(strings.TrimSpace(s), "*/", "*\\/")
+	return strings.Split(s, "\n")
+}
+
+var tsTemplate = template.Must(template.New("typescript").Funcs(template.FuncMap{
+	"json": func(v interface{}) string {
+		data, _ := json.Marshal(v)
+		return string(data)
+	},
+}).Parse(`// Code generated by bee generate docs. DO NOT EDIT.
+//
+// Types and fetch wrappers of {{.Title}}.
+
+export interface ClientConfig {
+  /** Base URL of the API, e.g. http://localhost:8080. */
+  baseURL: string;
+  /** Headers sent with every request. */
+  headers?: Record<string, string>;
+  /** Replaces the global fetch, e.g. in tests. */
+  fetch?: typeof fetch;
+}
+
+/** Configuration of the fetch wrappers. */
+export const config: ClientConfig = { baseURL: "" };
+
+/** Error of the responses with a status outside of 2xx. */
+export class ApiError<T = unknown> extends Error {
+  constructor(public readonly status: number, public readonly body: T) {
+    super("request failed with status " + status);
+    this.name = "ApiError";
+  }
+}
+
+type Value = string | number | boolean | Blob | undefined | null | Array<string | number | boolean>;
+
+interface RequestOptions {
+  query?: Record<string, Value>;
+  headers?: Record<string, Value>;
+  form?: Record<string, Value>;
+  body?: unknown;
+  accept?: string;
+  contentType?: string;
+}
+
+async function request<T>(method: string, path: string, options: RequestOptions, init?: RequestInit): Promise<T> {
+  const url = new URL(config.baseURL.replace(/\/$/, "") + path, typeof window === "undefined" ? undefined : window.location.href);
+  for (const [name, value] of Object.entries(options.query ?? {})) {
+    if (value === undefined || value === null) continue;
+    for (const v of Array.isArray(value) ? value : [value]) url.searchParams.append(name, String(v));
+  }
+
+  const headers = new Headers(config.headers);
+  for (const [name, value] of Object.entries(options.headers ?? {})) {
+    if (value !== undefined && value !== null) headers.set(name, String(value));
+  }
+  if (options.accept) headers.set("Accept", options.accept);
+
+  let body: BodyInit | undefined;
+  if (options.form) {
+    const form = new FormData();
+    for (const [name, value] of Object.entries(options.form)) {
+      if (value === undefined || value === null) continue;
+      form.append(name, value instanceof Blob ? value : String(value));
+    }
+    body = form;
+  } else if (options.body !== undefined) {
+    headers.set("Content-Type", options.contentType ?? "application/json");
+    body = JSON.stringify(options.body);
+  }
+
+  const response = await (config.fetch ?? fetch)(url.toString(), { ...init, method, headers, body });
+  const text = await response.text();
+  let data: unknown = text;
+  if (text && (response.headers.get("Content-Type") ?? "").includes("json")) {
+    data = JSON.parse(text);
+  }
+  if (!response.ok) {
+    throw new ApiError(response.status, data);
+  }
+  return (text ? data : undefined) as T;
+}
+{{range .Models}}
+{{if .Doc}}/**
+{{range .Doc}} *{{if .}} {{.}}{{end}}
+{{end}} */
+{{end -}}
+{{if .Type}}export type {{.Name}} = {{.Type}};
+{{else}}export interface {{.Name}}{{if .Extends}} extends {{range $i, $e := .Extends}}{{if $i}}, {{end}}{{$e}}{{end}}{{end}} {
+{{- range .Fields}}
+{{- if .Doc}}
+  /**
+{{range .Doc}}   *{{if .}} {{.}}{{end}}
+{{end}}   */
+{{- end}}
+  {{.Name}}{{if .Optional}}?{{end}}: {{.Type}};
+{{- end}}
+}
+{{end}}{{end}}
+{{- range .Operations}}{{$op := .}}
+{{if .Params}}export interface {{.Params}} {
+{{- range .Fields}}
+{{- if .Doc}}
+  /**
+{{range .Doc}}   *{{if .}} {{.}}{{end}}
+{{end}}   */
+{{- end}}
+  {{.Name}}{{if .Optional}}?{{end}}: {{.Type}};
+{{- end}}
+}
+
+{{end}}/**
+{{range .Doc}} *{{if .}} {{.}}{{end}}
+{{end}} */
+export function {{.Name}}({{if .Params}}params: {{.Params}}, {{end}}init?: RequestInit): Promise<{{.Result}}> {
+  return request<{{.Result}}>({{json .Method}}, {{.Path}}, {
+{{- if .Query}}
+    query: { {{range $i, $p := .Query}}{{if $i}}, {{end}}{{$p.Name}}: {{$p.Value}}{{end}} },
+{{- end}}
+{{- if .Headers}}
+    headers: { {{range $i, $p := .Headers}}{{if $i}}, {{end}}{{$p.Name}}: {{$p.Value}}{{end}} },
+{{- end}}
+{{- if .Form}}
+    form: { {{range $i, $p := .Form}}{{if $i}}, {{end}}{{$p.Name}}: {{$p.Value}}{{end}} },
+{{- end}}
+{{- if .Body}}
+    body: {{.Body}},{{if .Consumes}}
+    contentType: {{.Consumes}},{{end}}
+{{- end}}
+    accept: {{if eq .Accept "\"\""}}"application/json"{{else}}{{.Accept}}{{end}},
+  }, init);
+}
+{{end}}`))
@@ -0,0 +1,65 @@
+package docgen
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTypeScript(t *testing.T) {
+	src, err := newTestSpec(t).TypeScript()
+	require.NoError(t, err)
+	ts := string(src)
+
+	tests := []struct {
+		desc     string
+		expected string
+	}{
+		{
+			desc: "interface with optional and recursive properties",
+			expected: `export interface Order {
+  created?: string;
+  id: number;
+  lines?: Line[];
+  parent?: Order;
+  status?: "open" | "closed";
+  widget?: Widget;
+}`,
+		},
+		{
+			desc:     "interface definitions become unions",
+			expected: `export type Widget = BannerWidget | CarouselWidget;`,
+		},
+		{
+			desc: "implementations narrow the discriminator",
+			expected: `export interface BannerWidget extends WidgetBase {
+  image?: string;
+  type: "banner";
+}`,
+		},
+		{
+			desc: "parameters",
+			expected: `export interface OrderGetParams {
+  id: number;
+  status?: "open" | "closed";
+  ids?: number[];
+}`,
+		},
+		{
+			desc: "fetch wrapper",
+			expected: `export function orderGet(params: OrderGetParams, init?: RequestInit): Promise<Order> {
+  return request<Order>("GET", ` + "`/v1/orders/${encodeURIComponent(String(params.id))}`" + `, {
+    query: { "status": params.status, "ids": params.ids },
+    accept: "application/json",
+  }, init);
+}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			assert.Contains(t, ts, tt.expected)
+		})
+	}
+}
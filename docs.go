@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/zalora/bee/docgen"
+)
+
+var cmdDocs = &Command{
+	UsageLine: "docs coverage [-min=0]",
+	Short:     "report the documentation coverage of the registered routes",
+	Long: `
+Coverage cross-references the routes registered in routers/router.go and
+the chi routes file with the @router annotations of their handlers. For
+every route it reports whether it has a summary or description, the
+@Param of its path parameters (or its request body), a success response
+and a failure response:
+
+    METHOD  PATH                    HANDLER                 SUMMARY  PARAMS  SUCCESS  FAILURE
+    GET     /v1/orders/{id}         OrderController.Get     yes      yes     yes      yes
+    POST    /v1/orders/{id}/cancel  OrderController.Cancel  no       no      no       no
+
+followed by the percentage of passed checks per controller, or per package
+for chi handlers. Annotated handlers that no router registers are listed
+separately and do not count.
+
+    -min: fail with exit code 1 when the total coverage is below this percentage
+`,
+}
+
+var docsMin docValue
+
+func init() {
+	cmdDocs.Run = runDocsCoverage
+	cmdDocs.Flag.Var(&docsMin, "min", "minimum total coverage in percent")
+}
+
+func runDocsCoverage(cmd *Command, args []string) int {
+	ShowShortVersionBanner()
+
+	if len(args) < 1 || args[0] != "coverage" {
+		ColorLog("[ERRO] Unknown docs command\n")
+		ColorLog("[HINT] Usage: bee %s\n", cmd.UsageLine)
+		return 2
+	}
+	cmd.Flag.Parse(args[1:])
+
+	min := 0.0
+	if docsMin != "" {
+		var err error
+		if min, err = strconv.ParseFloat(docsMin.String(), 64); err != nil {
+			ColorLog("[ERRO] Invalid -min %q\n", docsMin)
+			return 2
+		}
+	}
+
+	if err := loadConfig(); err != nil {
+		ColorLog("[ERRO] Fail to parse bee.json[ %s ]\n", err)
+	}
+	currpath, _ := os.Getwd()
+	cov, diagnostics, err := docgen.New(docsOptions(currpath)).Coverage(context.Background())
+	for _, d := range diagnostics {
+		if d.Severity == docgen.SeverityWarning {
+			ColorLog("[WARN] %s\n", d.Message)
+		}
+	}
+	if err != nil {
+		ColorLog("[ERRO] %s\n", err)
+		return 2
+	}
+
+	printCoverage(cov)
+
+	total := cov.Percent("")
+	if total < min {
+		ColorLog("[ERRO] Documentation coverage %.1f%% is below %.1f%%\n", total, min)
+		return 1
+	}
+	ColorLog("[SUCC] Documentation coverage %.1f%%\n", total)
+	return 0
+}
+
+func printCoverage(cov *docgen.Coverage) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "METHOD\tPATH\tHANDLER\tSUMMARY\tPARAMS\tSUCCESS\tFAILURE")
+	for _, r := range cov.Routes {
+		if r.Registered {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", r.Method, r.Path, r.Handler, r.Summary, r.Params, r.Success, r.Failure)
+		}
+	}
+	w.Flush()
+
+	fmt.Println()
+	w = tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "GROUP\tCOVERAGE")
+	for _, group := range cov.Groups() {
+		fmt.Fprintf(w, "%s\t%.1f%%\n", group, cov.Percent(group))
+	}
+	fmt.Fprintf(w, "total\t%.1f%%\n", cov.Percent(""))
+	w.Flush()
+
+	if unregistered := cov.Unregistered(); len(unregistered) > 0 {
+		fmt.Println()
+		ColorLog("[WARN] Documented but not registered by any router:\n")
+		for _, r := range unregistered {
+			fmt.Printf("    %s %s (%s)\n", r.Method, r.Path, r.Handler)
+		}
+	}
+}
@@ -15,6 +15,7 @@
 package main
 
 import (
+	"flag"
 	"os"
 	"strings"
 )
@@ -47,8 +48,31 @@ bee generate migration [migrationfile] [-fields=""]
 bee generate docs
     generate swagger doc file
 
-bee generate postman
-    generate postman collection file
+bee generate postman [-sync=true]
+    generate postman collection file, and the environments of the postman section of the Beefile
+    -sync: update the existing collection. Saved responses, auth and scripts are kept and removed operations are archived
+
+bee generate requests [-format=postman]
+    generate the requests of the spec for an HTTP client, configured by the postman section of the Beefile
+    -format: [postman | http | insomnia | bruno]. http writes swagger/requests/<tag>.http, insomnia
+             swagger/insomnia.json and bruno a collection in swagger/bruno
+
+bee generate client [-lang=go] [-pkg=client]
+    generate a client of the annotated operations
+    -lang: [go | ts], the default is go. ts writes the module of docs.typescript to api.ts
+    -pkg:  the package directory, the default is client
+
+bee generate validators [-framework=beego] [-pkg=validation]
+    generate request validation from the swagger annotations
+    -framework: [beego | chi], a beego filter or a net/http middleware for chi, the default is beego
+    -pkg:       the package directory, the default is validation
+
+bee generate handlers -from=collection.json [-framework=beego]
+    generate annotated handler stubs, the models of their bodies and responses and their routes
+    from a Postman collection, or a swagger or OpenAPI 3 document. Existing handlers are kept
+    -from:      the Postman collection or the spec, JSON or YAML
+    -framework: [beego | chi], controllers registered in routers/router.go, or handler packages in
+                pkg/handlers registered in pkg/router/routes.go. The default is beego
 
 bee generate test [routerfile]
     generate testcase
@@ -79,6 +103,12 @@ func init() {
 	cmdGenerate.Flag.Var(&fields, "fields", "specify the fields want to generate.")
 }
 
+// subcommandFlags returns the flags of bee generate name, for the
+// subcommands whose flags are not shared with the others.
+func subcommandFlags(name string) *flag.FlagSet {
+	return flag.NewFlagSet("generate "+name, flag.ExitOnError)
+}
+
 func generateCode(cmd *Command, args []string) int {
 	ShowShortVersionBanner()
 
@@ -129,11 +159,63 @@ func generateCode(cmd *Command, args []string) int {
 		sname := args[1]
 		generateScaffold(sname, fields.String(), currpath, driver.String(), conn.String())
 	case "docs":
+		err := loadConfig()
+		if err != nil {
+			ColorLog("[ERRO] Fail to parse bee.json[ %s ]\n", err)
+		}
 		generateDocs(currpath)
-	case "postman":
-		err := generatePostman(currpath)
+	case "postman", "requests":
+		err := loadConfig()
+		if err != nil {
+			ColorLog("[ERRO] Fail to parse bee.json[ %s ]\n", err)
+		}
+		flags := subcommandFlags(gcmd)
+		format := flags.String("format", "postman", "format of the generated requests: postman, http, insomnia or bruno")
+		sync := flags.Bool("sync", false, "update the existing postman collection instead of replacing it")
+		flags.Parse(args[1:])
+		err = generateRequests(currpath, *format, conf.Postman, *sync)
 		if err != nil {
-			ColorLogS("[ERR] Could not generate postman: %s", err)
+			ColorLog("[ERRO] Could not generate requests: %s\n", err)
+			os.Exit(2)
+		}
+	case "client":
+		err := loadConfig()
+		if err != nil {
+			ColorLog("[ERRO] Fail to parse bee.json[ %s ]\n", err)
+		}
+		flags := subcommandFlags(gcmd)
+		lang := flags.String("lang", "go", "language of the generated client: go or ts")
+		pkg := flags.String("pkg", "client", "package directory of the generated client")
+		flags.Parse(args[1:])
+		if err := generateClient(currpath, *lang, *pkg); err != nil {
+			ColorLog("[ERRO] Could not generate client: %s\n", err)
+			os.Exit(2)
+		}
+	case "validators":
+		err := loadConfig()
+		if err != nil {
+			ColorLog("[ERRO] Fail to parse bee.json[ %s ]\n", err)
+		}
+		flags := subcommandFlags(gcmd)
+		framework := flags.String("framework", "beego", "web framework of the generated code: beego or chi")
+		pkg := flags.String("pkg", "validation", "package directory of the generated code")
+		flags.Parse(args[1:])
+		if err := generateValidators(currpath, *framework, *pkg); err != nil {
+			ColorLog("[ERRO] Could not generate validators: %s\n", err)
+			os.Exit(2)
+		}
+	case "handlers":
+		err := loadConfig()
+		if err != nil {
+			ColorLog("[ERRO] Fail to parse bee.json[ %s ]\n", err)
+		}
+		flags := subcommandFlags(gcmd)
+		from := flags.String("from", "", "postman collection or spec the handlers are generated from")
+		framework := flags.String("framework", "beego", "web framework of the generated code: beego or chi")
+		flags.Parse(args[1:])
+		if err := generateHandlers(currpath, *from, *framework); err != nil {
+			ColorLog("[ERRO] Could not generate handlers: %s\n", err)
+			os.Exit(2)
 		}
 	case "appcode":
 		// load config
@@ -0,0 +1,1060 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/zalora/bee/docgen"
+)
+
+// generateClient writes a client package for the operations of the app.
+func generateClient(currpath, lang, pkg string) error {
+	if lang != "go" && lang != "ts" {
+		return fmt.Errorf("unknown language %q, expected go or ts", lang)
+	}
+
+	spec, diagnostics, err := docgen.New(docsOptions(currpath)).Generate(context.Background())
+	for _, d := range diagnostics {
+		if d.Severity == docgen.SeverityWarning {
+			ColorLog("[WARN] %s\n", d.Message)
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	dir := path.Join(currpath, pkg)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	if lang == "ts" {
+		return writeTypeScript(path.Join(dir, "api.ts"), spec)
+	}
+
+	data := newGoClientGen(spec).data(path.Base(pkg))
+	for name, tpl := range map[string]string{
+		"client.go":     goClientTpl,
+		"models.go":     goClientModelsTpl,
+		"operations.go": goClientOperationsTpl,
+	} {
+		if err := writeTemplate(path.Join(dir, name), tpl, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeTypeScript(fpath string, spec *docgen.Spec) error {
+	src, err := spec.TypeScript()
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(fpath, src, 0644); err != nil {
+		return err
+	}
+	fmt.Fprintf(NewColorWriter(os.Stdout), "\t%s%screate%s\t %s%s\n", "\x1b[32m", "\x1b[1m", "\x1b[21m", fpath, "\x1b[0m")
+	return nil
+}
+
+// goClientReserved are the identifiers of client.go that models can not
+// take.
+var goClientReserved = map[string]bool{
+	"Client": true, "Option": true, "Codec": true, "JSONCodec": true,
+	"Error": true, "File": true, "NewClient": true,
+	"WithHTTPClient": true, "WithHeader": true, "WithCodec": true,
+}
+
+type goClientGen struct {
+	spec  *docgen.Spec
+	names map[string]string // definition name -> Go type name
+	taken map[string]bool
+}
+
+type goClientData struct {
+	Package          string
+	Title            string
+	Models           []goClientModel
+	ModelImports     []string
+	Operations       []goClientOperation
+	OperationImports []string
+}
+
+type goClientModel struct {
+	Name   string
+	Doc    []string
+	Type   string // underlying type when the model is not a struct
+	Fields []goClientField
+	Enum   []goClientConst
+}
+
+type goClientField struct {
+	Name string
+	Type string
+	Tag  string
+	Doc  []string
+}
+
+type goClientConst struct {
+	Name  string
+	Value string
+}
+
+type goClientOperation struct {
+	Name       string
+	Doc        []string
+	Method     string
+	Path       string // Go expression of the request path
+	Params     string // name of the parameter struct, empty without parameters
+	Fields     []goClientField
+	Statements []string
+	Consumes   string
+	Produces   string
+	Failures   []goClientFailure
+	Result     string
+}
+
+type goClientFailure struct {
+	Status      string
+	Description string
+	Model       string // Go type of the failure model, empty without one
+}
+
+func newGoClientGen(spec *docgen.Spec) *goClientGen {
+	return newGoTypesGen(spec, goClientReserved)
+}
+
+// newGoTypesGen names the definitions of spec, avoiding the reserved
+// identifiers.
+func newGoTypesGen(spec *docgen.Spec, reserved map[string]bool) *goClientGen {
+	g := &goClientGen{
+		spec:  spec,
+		names: make(map[string]string),
+		taken: make(map[string]bool),
+	}
+	for name := range reserved {
+		g.taken[name] = true
+	}
+
+	// Short names first, the package qualified name on collisions.
+	short := make(map[string]int)
+	for _, name := range spec.Definitions() {
+		short[goIdentifier(name[strings.LastIndex(name, ".")+1:])]++
+	}
+	for _, name := range spec.Definitions() {
+		ident := goIdentifier(name[strings.LastIndex(name, ".")+1:])
+		if short[ident] > 1 {
+			ident = goIdentifier(name)
+		}
+		g.names[name] = g.unique(ident, "Model")
+	}
+	return g
+}
+
+// unique returns ident, or ident with suffix when it is already taken.
+func (g *goClientGen) unique(ident, suffix string) string {
+	name := ident
+	for i := 2; g.taken[name]; i++ {
+		name = ident + suffix
+		if i > 2 {
+			name += strconv.Itoa(i - 1)
+		}
+	}
+	g.taken[name] = true
+	return name
+}
+
+func (g *goClientGen) data(pkg string) goClientData {
+	data := goClientData{Package: pkg, Title: "the API"}
+	if info, ok := g.spec.Document["info"].(map[string]interface{}); ok {
+		if title, ok := info["title"].(string); ok && title != "" {
+			data.Title = title
+		}
+	}
+	for _, name := range g.spec.Definitions() {
+		data.Models = append(data.Models, g.model(name))
+	}
+	for _, op := range g.spec.Operations() {
+		data.Operations = append(data.Operations, g.operation(op))
+	}
+
+	var modelTypes, opTypes []string
+	for _, m := range data.Models {
+		modelTypes = append(modelTypes, m.Type)
+		for _, f := range m.Fields {
+			modelTypes = append(modelTypes, f.Type)
+		}
+	}
+	for _, o := range data.Operations {
+		opTypes = append(opTypes, o.Result, o.Path)
+		for _, f := range o.Fields {
+			opTypes = append(opTypes, f.Type)
+		}
+		for _, f := range o.Failures {
+			opTypes = append(opTypes, f.Model)
+		}
+	}
+	data.ModelImports = goImports(modelTypes)
+	data.OperationImports = append([]string{"context"}, goImports(opTypes)...)
+	return data
+}
+
+// goImports returns the packages used by the Go expressions.
+func goImports(exprs []string) []string {
+	var imports []string
+	for _, pkg := range []string{"encoding/json", "net/url", "time"} {
+		for _, expr := range exprs {
+			if strings.Contains(expr, path.Base(pkg)+".") {
+				imports = append(imports, pkg)
+				break
+			}
+		}
+	}
+	return imports
+}
+
+func (g *goClientGen) model(name string) goClientModel {
+	def := g.spec.Definition(name)
+	m := goClientModel{Name: g.names[name]}
+	m.Doc = []string{fmt.Sprintf("%s is the %s definition.", m.Name, name)}
+	if desc, ok := def["description"].(string); ok {
+		m.Doc = append(append(m.Doc, ""), commentLines(desc)...)
+	}
+
+	if oneOf := g.implementations(def); len(oneOf) > 0 {
+		m.Doc = append(m.Doc, "", fmt.Sprintf("It is one of %s. Fields of this type are decoded as json.RawMessage,", strings.Join(oneOf, ", ")),
+			fmt.Sprintf("unmarshal them into the implementation named by %s.", g.discriminatorField(def)))
+	}
+
+	if !isObjectSchema(def) {
+		m.Type = g.goType(def, false)
+		if enum, ok := def["enum"].([]interface{}); ok && m.Type == "string" {
+			for _, v := range enum {
+				value := fmt.Sprint(v)
+				m.Enum = append(m.Enum, goClientConst{
+					Name:  g.unique(m.Name+goIdentifier(value), "Value"),
+					Value: strconv.Quote(value),
+				})
+			}
+		}
+		return m
+	}
+
+	m.Fields = g.fields(def)
+	return m
+}
+
+// implementations returns the Go names of the x-oneOf implementations.
+func (g *goClientGen) implementations(schema map[string]interface{}) []string {
+	list, _ := schema["x-oneOf"].([]interface{})
+	var names []string
+	for _, item := range list {
+		impl, _ := item.(map[string]interface{})
+		if name, ok := g.names[docgen.RefName(impl)]; ok {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+func (g *goClientGen) discriminatorField(schema map[string]interface{}) string {
+	if d, ok := schema["discriminator"].(string); ok {
+		return strconv.Quote(d)
+	}
+	return "the payload"
+}
+
+// fields returns the struct fields of an object schema, the properties of
+// its allOf parts included.
+func (g *goClientGen) fields(schema map[string]interface{}) []goClientField {
+	props := make(map[string]map[string]interface{})
+	required := make(map[string]bool)
+	g.collectProperties(schema, props, required, make(map[string]bool))
+
+	names := make([]string, 0, len(props))
+	for name := range props {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	taken := make(map[string]bool)
+	var fields []goClientField
+	for _, name := range names {
+		prop := props[name]
+		ident := goIdentifier(name)
+		for taken[ident] {
+			ident += "_"
+		}
+		taken[ident] = true
+
+		f := goClientField{Name: ident, Type: g.goType(prop, true)}
+		tag := name
+		if !required[name] {
+			tag += ",omitempty"
+		}
+		f.Tag = fmt.Sprintf("`json:%q`", tag)
+		if desc, ok := prop["description"].(string); ok {
+			text := lowerFirst(strings.TrimSuffix(desc, "."))
+			if !strings.HasPrefix(text, "the ") {
+				text = "the " + text
+			}
+			f.Doc = commentLines(ident + " is " + text + ".")
+		}
+		if enum, ok := prop["enum"].([]interface{}); ok {
+			values := make([]string, len(enum))
+			for i, v := range enum {
+				values[i] = fmt.Sprint(v)
+			}
+			f.Doc = append(f.Doc, fmt.Sprintf("One of %s.", strings.Join(values, ", ")))
+		}
+		fields = append(fields, f)
+	}
+	return fields
+}
+
+func (g *goClientGen) collectProperties(schema map[string]interface{}, props map[string]map[string]interface{}, required, seen map[string]bool) {
+	if name := docgen.RefName(schema); name != "" {
+		if seen[name] {
+			return
+		}
+		seen[name] = true
+		schema = g.spec.Definition(name)
+	}
+	parts, _ := schema["allOf"].([]interface{})
+	for _, part := range parts {
+		if p, ok := part.(map[string]interface{}); ok {
+			g.collectProperties(p, props, required, seen)
+		}
+	}
+	own, _ := schema["properties"].(map[string]interface{})
+	for name, prop := range own {
+		if p, ok := prop.(map[string]interface{}); ok {
+			props[name] = p
+		}
+	}
+	list, _ := schema["required"].([]interface{})
+	for _, name := range list {
+		required[fmt.Sprint(name)] = true
+	}
+}
+
+func isObjectSchema(schema map[string]interface{}) bool {
+	_, props := schema["properties"]
+	_, allOf := schema["allOf"]
+	_, additional := schema["additionalProperties"]
+	return props || allOf || (schema["type"] == "object" && !additional)
+}
+
+// goType returns the Go type of schema. References to structs are
+// pointers when ptr is set, so that optional and recursive fields work.
+func (g *goClientGen) goType(schema map[string]interface{}, ptr bool) string {
+	if schema == nil {
+		return "interface{}"
+	}
+	if name := docgen.RefName(schema); name != "" {
+		def := g.spec.Definition(name)
+		ident, ok := g.names[name]
+		switch {
+		case !ok:
+			return "json.RawMessage"
+		case len(g.implementations(def)) > 0:
+			return "json.RawMessage"
+		case ptr && isObjectSchema(def):
+			return "*" + ident
+		}
+		return ident
+	}
+	if _, ok := schema["x-oneOf"]; ok {
+		return "json.RawMessage"
+	}
+
+	format, _ := schema["format"].(string)
+	typ, _ := schema["type"].(string)
+	switch typ {
+	case "array":
+		items, _ := schema["items"].(map[string]interface{})
+		return "[]" + g.goType(items, false)
+	case "object":
+		if additional, ok := schema["additionalProperties"].(map[string]interface{}); ok {
+			return "map[string]" + g.goType(additional, false)
+		}
+		return "map[string]interface{}"
+	case "string":
+		switch format {
+		case "date-time", "datetime":
+			return "time.Time"
+		case "byte", "binary":
+			return "[]byte"
+		}
+		return "string"
+	case "integer":
+		if format == "int32" {
+			return "int32"
+		}
+		return "int64"
+	case "number":
+		if format == "float" {
+			return "float32"
+		}
+		return "float64"
+	case "boolean":
+		return "bool"
+	case "file":
+		return "[]byte"
+	}
+	if _, ok := schema["properties"]; ok {
+		return "map[string]interface{}"
+	}
+	return "interface{}"
+}
+
+func (g *goClientGen) operation(op docgen.Operation) goClientOperation {
+	o := goClientOperation{
+		Name:     g.unique(goOperationName(op), "Op"),
+		Method:   op.Method,
+		Consumes: goStringSlice(g.spec.Consumes(op)),
+		Produces: goStringSlice(g.spec.Produces(op)),
+	}
+	rt := g.spec.BasePath() + op.Path
+
+	o.Doc = []string{fmt.Sprintf("%s calls %s %s.", o.Name, op.Method, rt)}
+	for _, key := range []string{"summary", "description"} {
+		if text, ok := op.Op[key].(string); ok && text != "" {
+			o.Doc = append(append(o.Doc, ""), commentLines(text)...)
+		}
+	}
+	if deprecated, _ := op.Op["deprecated"].(bool); deprecated {
+		note := "Deprecated:"
+		if since, ok := op.Op["x-deprecated-since"].(string); ok {
+			note += " since " + since + "."
+		}
+		if sunset, ok := op.Op["x-sunset"].(string); ok {
+			note += " It will be removed on " + sunset + "."
+		}
+		if replacement, ok := op.Op["x-replaced-by"].(string); ok {
+			note += " Use " + replacement + " instead."
+		}
+		if note == "Deprecated:" {
+			note += " the operation will be removed."
+		}
+		o.Doc = append(o.Doc, "", note)
+	}
+
+	g.parameters(&o, op, rt)
+
+	responses := op.Responses()
+	if resp, ok := responses[defaultMockStatus(responses)]; ok {
+		if schema, ok := resp["schema"].(map[string]interface{}); ok {
+			o.Result = g.goType(schema, true)
+		}
+	}
+
+	statuses := make([]string, 0, len(responses))
+	for status := range responses {
+		if !strings.HasPrefix(status, "2") {
+			statuses = append(statuses, status)
+		}
+	}
+	sort.Strings(statuses)
+	for _, status := range statuses {
+		resp := responses[status]
+		f := goClientFailure{Status: strconv.Quote(status)}
+		desc, _ := resp["description"].(string)
+		f.Description = strconv.Quote(desc)
+		if schema, ok := resp["schema"].(map[string]interface{}); ok {
+			f.Model = g.goType(schema, false)
+		}
+		o.Failures = append(o.Failures, f)
+	}
+	return o
+}
+
+// parameters fills the parameter struct of o and the statements that put
+// its fields into the request.
+func (g *goClientGen) parameters(o *goClientOperation, op docgen.Operation, rt string) {
+	taken := map[string]bool{}
+	field := func(name, in string) string {
+		ident := goIdentifier(name)
+		if taken[ident] {
+			ident += goIdentifier(in)
+		}
+		for taken[ident] {
+			ident += "_"
+		}
+		taken[ident] = true
+		return ident
+	}
+	add := func(param map[string]interface{}, ident, typ string) {
+		f := goClientField{Name: ident, Type: typ}
+		if desc, ok := param["description"].(string); ok {
+			f.Doc = commentLines(ident + " is " + lowerFirst(strings.TrimSuffix(desc, ".")) + ".")
+		}
+		o.Fields = append(o.Fields, f)
+	}
+
+	// Path parameters follow the route, those that are not part of it can
+	// not be sent.
+	var path []string
+	last := 0
+	for _, loc := range docgen.PathParamRegexp.FindAllStringSubmatchIndex(rt, -1) {
+		if literal := rt[last:loc[0]]; literal != "" {
+			path = append(path, strconv.Quote(literal))
+		}
+		name := rt[loc[2]:loc[3]]
+		param := op.Parameter("path", name)
+		if param == nil {
+			param = map[string]interface{}{"name": name, "type": "string"}
+		}
+		ident := field(name, "path")
+		add(param, ident, g.paramType(param, true))
+		if name == "splat" {
+			path = append(path, "formatValue(params."+ident+")")
+		} else {
+			path = append(path, "url.PathEscape(formatValue(params."+ident+"))")
+		}
+		last = loc[1]
+	}
+	if literal := rt[last:]; literal != "" || len(path) == 0 {
+		path = append(path, strconv.Quote(literal))
+	}
+	o.Path = strings.Join(path, " + ")
+
+	for _, param := range op.Parameters() {
+		name, _ := param["name"].(string)
+		in, _ := param["in"].(string)
+		required, _ := param["required"].(bool)
+		var target string
+		switch in {
+		case "query":
+			target = "r.query"
+		case "header":
+			target = "r.header"
+		case "formData":
+			target = "r.form"
+		case "body":
+			schema, _ := param["schema"].(map[string]interface{})
+			add(param, field("body", in), g.goType(schema, true))
+			o.Statements = append(o.Statements, "r.body = params."+o.Fields[len(o.Fields)-1].Name)
+			continue
+		default:
+			continue
+		}
+
+		ident := field(name, in)
+		if param["type"] == "file" {
+			add(param, ident, "*File")
+			o.Statements = append(o.Statements, fmt.Sprintf("if params.%s != nil {\nr.files[%q] = params.%s\n}", ident, name, ident))
+			continue
+		}
+
+		typ := g.paramType(param, required)
+		add(param, ident, typ)
+		value := "params." + ident
+		switch {
+		case strings.HasPrefix(typ, "[]") && param["collectionFormat"] == "multi":
+			o.Statements = append(o.Statements, fmt.Sprintf("for _, v := range %s {\n%s.Add(%q, formatValue(v))\n}", value, target, name))
+		case strings.HasPrefix(typ, "[]"):
+			sep := map[interface{}]string{"ssv": " ", "tsv": "\t", "pipes": "|"}[param["collectionFormat"]]
+			if sep == "" {
+				sep = ","
+			}
+			o.Statements = append(o.Statements, fmt.Sprintf("if len(%s) > 0 {\n%s.Set(%q, formatList(%s, %q))\n}", value, target, name, value, sep))
+		case strings.HasPrefix(typ, "*"):
+			o.Statements = append(o.Statements, fmt.Sprintf("if %s != nil {\n%s.Set(%q, formatValue(*%s))\n}", value, target, name, value))
+		default:
+			o.Statements = append(o.Statements, fmt.Sprintf("%s.Set(%q, formatValue(%s))", target, name, value))
+		}
+	}
+
+	if len(o.Fields) > 0 {
+		o.Params = g.unique(o.Name+"Params", "_")
+	}
+}
+
+// paramType returns the Go type of a non-body parameter. Optional scalars
+// are pointers so that zero values can be sent.
+func (g *goClientGen) paramType(param map[string]interface{}, required bool) string {
+	typ := g.goType(param, false)
+	if required || strings.HasPrefix(typ, "[]") || strings.HasPrefix(typ, "map[") || typ == "interface{}" {
+		return typ
+	}
+	return "*" + typ
+}
+
+// goOperationName turns OrderController.Get into OrderGet, operations
+// without an operationId are named after their method and path.
+func goOperationName(op docgen.Operation) string {
+	if id, ok := op.Op["operationId"].(string); ok && id != "" {
+		parts := strings.Split(id, ".")
+		for i, part := range parts {
+			parts[i] = goIdentifier(strings.TrimSuffix(part, "Controller"))
+		}
+		return strings.Join(parts, "")
+	}
+	return goIdentifier(strings.ToLower(op.Method) + " " + op.Path)
+}
+
+// goInitialisms are written in upper case in identifiers.
+var goInitialisms = map[string]bool{
+	"api": true, "id": true, "ids": true, "http": true, "ip": true,
+	"json": true, "sku": true, "url": true, "uri": true, "uuid": true,
+}
+
+// goIdentifier turns a name into an exported Go identifier, e.g.
+// order_id -> OrderID.
+func goIdentifier(name string) string {
+	words := strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	var b strings.Builder
+	for _, word := range words {
+		switch lower := strings.ToLower(word); {
+		case lower == "ids":
+			b.WriteString("IDs")
+		case goInitialisms[lower]:
+			b.WriteString(strings.ToUpper(word))
+		default:
+			runes := []rune(word)
+			runes[0] = unicode.ToUpper(runes[0])
+			b.WriteString(string(runes))
+		}
+	}
+	ident := b.String()
+	if ident == "" || unicode.IsDigit([]rune(ident)[0]) {
+		ident = "X" + ident
+	}
+	return ident
+}
+
+func goStringSlice(values []string) string {
+	if len(values) == 0 {
+		return "nil"
+	}
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		quoted[i] = strconv.Quote(v)
+	}
+	return "[]string{" + strings.Join(quoted, ", ") + "}"
+}
+
+func lowerFirst(s string) string {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return s
+	}
+	runes := []rune(s)
+	// Keep acronyms such as ID as they are.
+	if len(runes) > 1 && unicode.IsUpper(runes[1]) {
+		return s
+	}
+	runes[0] = unicode.ToLower(runes[0])
+	return string(runes)
+}
+
+// commentLines splits a description into comment lines.
+func commentLines(s string) []string {
+	return strings.Split(strings.TrimSpace(s), "\n")
+}
+
+const goClientTpl = `// Code generated by bee generate client. DO NOT EDIT.
+
+// Package {{.Package}} is a client of {{.Title}}.
+package {{.Package}}
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Content types of the thrift codecs. The client only encodes JSON, set
+// a codec for the thrift content types with WithCodec.
+const (
+	ContentTypeJSON                     = "application/json"
+	ContentTypeThriftBinary             = "application/vnd.apache.thrift.binary"
+	ContentTypeThriftJSON               = "application/vnd.apache.thrift.json"
+	ContentTypeThriftBinaryWebContentV1 = "application/vnd.zalora.webcontent.v1+thrift.binary"
+	ContentTypeThriftJSONWebContentV1   = "application/vnd.zalora.webcontent.v1+thrift.json"
+)
+
+// Codec encodes and decodes the bodies of a content type.
+type Codec interface {
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+}
+
+// JSONCodec encodes bodies as JSON.
+type JSONCodec struct{}
+
+// Marshal implements Codec.
+func (JSONCodec) Marshal(v interface{}) ([]byte, error) { return json.Marshal(v) }
+
+// Unmarshal implements Codec.
+func (JSONCodec) Unmarshal(data []byte, v interface{}) error { return json.Unmarshal(data, v) }
+
+// Client calls the operations of the API.
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+	header     http.Header
+	codecs     map[string]Codec
+	accept     []string
+}
+
+// Option configures a Client.
+type Option func(*Client)
+
+// WithHTTPClient sets the client that sends the requests, e.g. to set a
+// timeout or a transport. The default is http.DefaultClient.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithHeader adds a header to every request.
+func WithHeader(key, value string) Option {
+	return func(c *Client) {
+		c.header.Add(key, value)
+	}
+}
+
+// WithCodec sets the codec of a content type. Requests prefer the content
+// types in the order their codecs were set, JSON comes last.
+func WithCodec(contentType string, codec Codec) Option {
+	return func(c *Client) {
+		c.codecs[contentType] = codec
+		c.accept = append([]string{contentType}, c.accept...)
+	}
+}
+
+// NewClient returns a client of the API at baseURL, e.g.
+// http://localhost:8080.
+func NewClient(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		httpClient: http.DefaultClient,
+		header:     make(http.Header),
+		codecs:     map[string]Codec{ContentTypeJSON: JSONCodec{} },
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	c.accept = append(c.accept, ContentTypeJSON)
+	return c
+}
+
+// File is a file parameter.
+type File struct {
+	Name    string
+	Content io.Reader
+}
+
+// Error is returned for the responses with a status outside of 2xx.
+type Error struct {
+	StatusCode int
+	// Message is the description of the documented failure.
+	Message string
+	Body    []byte
+	// Model is the decoded failure model of the status, e.g. *ErrorModel.
+	// It is nil when the status has no model.
+	Model interface{}
+}
+
+func (e *Error) Error() string {
+	msg := e.Message
+	if msg == "" {
+		msg = http.StatusText(e.StatusCode)
+	}
+	if body := bytes.TrimSpace(e.Body); len(body) > 0 {
+		return fmt.Sprintf("%d %s: %s", e.StatusCode, msg, body)
+	}
+	return fmt.Sprintf("%d %s", e.StatusCode, msg)
+}
+
+// failure is a documented failure of an operation.
+type failure struct {
+	description string
+	model       func() interface{}
+}
+
+type request struct {
+	method   string
+	path     string
+	query    url.Values
+	header   http.Header
+	form     url.Values
+	files    map[string]*File
+	body     interface{}
+	consumes []string
+	produces []string
+	failures map[string]failure
+}
+
+func newRequest(method, path string, consumes, produces []string, failures map[string]failure) *request {
+	return &request{
+		method:   method,
+		path:     path,
+		query:    make(url.Values),
+		header:   make(http.Header),
+		form:     make(url.Values),
+		files:    make(map[string]*File),
+		consumes: consumes,
+		produces: produces,
+		failures: failures,
+	}
+}
+
+// codec returns the preferred content type of offered that has a codec.
+func (c *Client) codec(offered []string) (string, Codec, error) {
+	if len(offered) == 0 {
+		return ContentTypeJSON, c.codecs[ContentTypeJSON], nil
+	}
+	for _, ct := range c.accept {
+		for _, o := range offered {
+			if o == ct {
+				return ct, c.codecs[ct], nil
+			}
+		}
+	}
+	return "", nil, fmt.Errorf("no codec for %s, set one with WithCodec", strings.Join(offered, ", "))
+}
+
+func (c *Client) do(ctx context.Context, r *request, out interface{}) error {
+	var body io.Reader
+	header := make(http.Header)
+	for k, v := range c.header {
+		header[k] = v
+	}
+	for k, v := range r.header {
+		header[k] = v
+	}
+
+	switch {
+	case len(r.files) > 0:
+		var buf bytes.Buffer
+		w := multipart.NewWriter(&buf)
+		for name, values := range r.form {
+			for _, v := range values {
+				w.WriteField(name, v)
+			}
+		}
+		for name, f := range r.files {
+			part, err := w.CreateFormFile(name, f.Name)
+			if err != nil {
+				return err
+			}
+			if _, err := io.Copy(part, f.Content); err != nil {
+				return err
+			}
+		}
+		if err := w.Close(); err != nil {
+			return err
+		}
+		body = &buf
+		header.Set("Content-Type", w.FormDataContentType())
+	case len(r.form) > 0:
+		body = strings.NewReader(r.form.Encode())
+		header.Set("Content-Type", "application/x-www-form-urlencoded")
+	case !isNil(r.body):
+		ct, codec, err := c.codec(r.consumes)
+		if err != nil {
+			return err
+		}
+		data, err := codec.Marshal(r.body)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+		header.Set("Content-Type", ct)
+	}
+
+	if out != nil && header.Get("Accept") == "" {
+		ct, _, err := c.codec(r.produces)
+		if err != nil {
+			return err
+		}
+		header.Set("Accept", ct)
+	}
+
+	u := c.baseURL + r.path
+	if len(r.query) > 0 {
+		u += "?" + r.query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, r.method, u, body)
+	if err != nil {
+		return err
+	}
+	req.Header = header
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		e := &Error{StatusCode: resp.StatusCode, Body: data}
+		f, ok := r.failures[strconv.Itoa(resp.StatusCode)]
+		if !ok {
+			f, ok = r.failures["default"]
+		}
+		if ok {
+			e.Message = f.description
+			if f.model != nil && len(data) > 0 {
+				model := f.model()
+				if c.decode(resp.Header.Get("Content-Type"), data, model) == nil {
+					e.Model = model
+				}
+			}
+		}
+		return e
+	}
+
+	if out == nil || len(data) == 0 {
+		return nil
+	}
+	return c.decode(resp.Header.Get("Content-Type"), data, out)
+}
+
+func (c *Client) decode(contentType string, data []byte, out interface{}) error {
+	ct, _, _ := mime.ParseMediaType(contentType)
+	if ct == "" {
+		ct = ContentTypeJSON
+	}
+	codec, ok := c.codecs[ct]
+	if !ok {
+		if !strings.HasSuffix(ct, "+json") {
+			return fmt.Errorf("no codec for %s, set one with WithCodec", ct)
+		}
+		codec = c.codecs[ContentTypeJSON]
+	}
+	return codec.Unmarshal(data, out)
+}
+
+// isNil reports whether v is nil or a nil pointer, map or slice. Zero
+// values such as false or an empty struct are bodies like any other.
+func isNil(v interface{}) bool {
+	if v == nil {
+		return true
+	}
+	switch rv := reflect.ValueOf(v); rv.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Interface:
+		return rv.IsNil()
+	}
+	return false
+}
+
+func formatValue(v interface{}) string {
+	switch t := v.(type) {
+	case string:
+		return t
+	case time.Time:
+		return t.Format(time.RFC3339)
+	case []byte:
+		return string(t)
+	}
+	return fmt.Sprint(v)
+}
+
+func formatList(list interface{}, sep string) string {
+	v := reflect.ValueOf(list)
+	values := make([]string, v.Len())
+	for i := range values {
+		values[i] = formatValue(v.Index(i).Interface())
+	}
+	return strings.Join(values, sep)
+}
+`
+
+const goClientModelsTpl = `// Code generated by bee generate client. DO NOT EDIT.
+
+package {{.Package}}
+
+{{with .ModelImports}}
+import (
+{{- range .}}
+	"{{.}}"
+{{- end}}
+)
+{{end}}
+{{range .Models}}
+{{range .Doc}}//{{if .}} {{.}}{{end}}
+{{end -}}
+{{if .Type}}type {{.Name}} {{.Type}}
+{{if .Enum}}
+// Values of {{.Name}}.
+const (
+{{- $name := .Name}}
+{{- range .Enum}}
+	{{.Name}} {{$name}} = {{.Value}}
+{{- end}}
+)
+{{end}}{{else}}type {{.Name}} struct {
+{{- range .Fields}}
+{{range .Doc}}	// {{.}}
+{{end -}}
+	{{.Name}} {{.Type}} {{.Tag}}
+{{- end}}
+}
+{{end}}{{end}}`
+
+const goClientOperationsTpl = `// Code generated by bee generate client. DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+{{- range .OperationImports}}
+	"{{.}}"
+{{- end}}
+)
+{{range .Operations}}{{if .Params}}
+// {{.Params}} are the parameters of {{.Name}}.
+type {{.Params}} struct {
+{{- range .Fields}}
+{{range .Doc}}	// {{.}}
+{{end -}}
+	{{.Name}} {{.Type}}
+{{- end}}
+}
+{{end}}
+{{range .Doc}}//{{if .}} {{.}}{{end}}
+{{end -}}
+func (c *Client) {{.Name}}(ctx context.Context{{if .Params}}, params {{.Params}}{{end}}) {{if .Result}}({{.Result}}, error){{else}}error{{end}} {
+	r := newRequest("{{.Method}}", {{.Path}}, {{.Consumes}}, {{.Produces}}, map[string]failure{
+{{- range .Failures}}
+		{{.Status}}: { {{.Description}}, {{if .Model}}func() interface{} { return new({{.Model}}) }{{else}}nil{{end}} },
+{{- end}}
+	})
+{{- range .Statements}}
+	{{.}}
+{{- end}}
+{{- if .Result}}
+	var out {{.Result}}
+	err := c.do(ctx, r, &out)
+	return out, err
+{{- else}}
+	return c.do(ctx, r, nil)
+{{- end}}
+}
+{{end}}`
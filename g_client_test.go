@@ -0,0 +1,157 @@
+package main
+
+import (
+	"encoding/json"
+	"go/parser"
+	"go/token"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zalora/bee/docgen"
+)
+
+const clientTestSpec = `{
+	"basePath": "/v1",
+	"paths": {
+		"/orders": {
+			"get": {
+				"operationId": "OrderController.List",
+				"produces": ["application/vnd.apache.thrift.binary", "application/json"],
+				"parameters": [
+					{"in": "query", "name": "ids", "type": "array", "items": {"type": "integer"}, "collectionFormat": "multi"},
+					{"in": "query", "name": "limit", "type": "integer", "format": "int32"},
+					{"in": "header", "name": "X-Request-Id", "required": true, "type": "string"}
+				],
+				"responses": {
+					"200": {"schema": {"type": "array", "items": {"$ref": "#/definitions/models.Order"}}},
+					"400": {"description": "invalid filter", "schema": {"$ref": "#/definitions/models.Error"}}
+				}
+			}
+		}
+	},
+	"definitions": {
+		"models.Order": {
+			"type": "object",
+			"required": ["id"],
+			"properties": {
+				"id": {"type": "integer", "format": "int64"},
+				"parent": {"$ref": "#/definitions/models.Order"},
+				"status": {"$ref": "#/definitions/models.Status"}
+			}
+		},
+		"models.Status": {"type": "string", "enum": ["open", "closed"]},
+		"models.Error": {"type": "object", "properties": {"message": {"type": "string"}}}
+	}
+}`
+
+func TestGoIdentifier(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected string
+	}{
+		{"id", "ID"},
+		{"order_id", "OrderID"},
+		{"X-Request-Id", "XRequestID"},
+		{"ids", "IDs"},
+		{"createdAt", "CreatedAt"},
+		{"2fa", "X2fa"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, goIdentifier(tt.name))
+		})
+	}
+}
+
+func TestGoClientData(t *testing.T) {
+	spec := &docgen.Spec{}
+	require.NoError(t, json.Unmarshal([]byte(clientTestSpec), &spec.Document))
+	data := newGoClientGen(spec).data("shop")
+
+	require.Len(t, data.Models, 3)
+	// Error is taken by the client.
+	assert.Equal(t, "ErrorModel", data.Models[0].Name)
+	assert.Equal(t, []goClientField{
+		{Name: "ID", Type: "int64", Tag: "`json:\"id\"`"},
+		{Name: "Parent", Type: "*Order", Tag: "`json:\"parent,omitempty\"`"},
+		{Name: "Status", Type: "Status", Tag: "`json:\"status,omitempty\"`"},
+	}, data.Models[1].Fields)
+	assert.Equal(t, "string", data.Models[2].Type)
+	assert.Equal(t, []goClientConst{{"StatusOpen", `"open"`}, {"StatusClosed", `"closed"`}}, data.Models[2].Enum)
+
+	require.Len(t, data.Operations, 1)
+	op := data.Operations[0]
+	assert.Equal(t, "OrderList", op.Name)
+	assert.Equal(t, "OrderListParams", op.Params)
+	assert.Equal(t, "[]Order", op.Result)
+	assert.Equal(t, `[]string{"application/vnd.apache.thrift.binary", "application/json"}`, op.Produces)
+	assert.Equal(t, []goClientField{
+		{Name: "IDs", Type: "[]int64"},
+		{Name: "Limit", Type: "*int32"},
+		{Name: "XRequestID", Type: "string"},
+	}, op.Fields)
+	assert.Equal(t, []string{
+		"for _, v := range params.IDs {\nr.query.Add(\"ids\", formatValue(v))\n}",
+		"if params.Limit != nil {\nr.query.Set(\"limit\", formatValue(*params.Limit))\n}",
+		"r.header.Set(\"X-Request-Id\", formatValue(params.XRequestID))",
+	}, op.Statements)
+	assert.Equal(t, []goClientFailure{{Status: `"400"`, Description: `"invalid filter"`, Model: "ErrorModel"}}, op.Failures)
+
+	dir := t.TempDir()
+	for name, tpl := range map[string]string{
+		"client.go":     goClientTpl,
+		"models.go":     goClientModelsTpl,
+		"operations.go": goClientOperationsTpl,
+	} {
+		fpath := filepath.Join(dir, name)
+		require.NoError(t, writeTemplate(fpath, tpl, data))
+		_, err := parser.ParseFile(token.NewFileSet(), fpath, nil, parser.AllErrors)
+		assert.NoError(t, err, name)
+	}
+}
+
+func TestGoClientZeroBody(t *testing.T) {
+	spec := &docgen.Spec{}
+	require.NoError(t, json.Unmarshal([]byte(clientTestSpec), &spec.Document))
+	data := newGoClientGen(spec).data("shop")
+
+	dir := t.TempDir()
+	for name, tpl := range map[string]string{
+		"client.go":     goClientTpl,
+		"models.go":     goClientModelsTpl,
+		"operations.go": goClientOperationsTpl,
+	} {
+		require.NoError(t, writeTemplate(filepath.Join(dir, name), tpl, data))
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/shop\n\ngo 1.16\n"), 0644))
+	// Only nil bodies are left out: false, 0 and empty structs are sent.
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "body_test.go"), []byte(`package shop
+
+import "testing"
+
+func TestIsNil(t *testing.T) {
+	var order *Order
+	var list []Order
+	for _, v := range []interface{}{nil, order, list} {
+		if !isNil(v) {
+			t.Errorf("isNil(%#v) = false", v)
+		}
+	}
+	for _, v := range []interface{}{false, 0, "", Order{}, []Order{}} {
+		if isNil(v) {
+			t.Errorf("isNil(%#v) = true", v)
+		}
+	}
+}
+`), 0644))
+	cmd := exec.Command("go", "test", ".")
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GOFLAGS=-mod=mod", "GOPROXY=off")
+	out, err := cmd.CombinedOutput()
+	assert.NoError(t, err, string(out))
+}
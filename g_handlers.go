@@ -0,0 +1,1277 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/ast"
+	goformat "go/format"
+	"go/parser"
+	"go/token"
+	"net/http"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"github.com/zalora/bee/docgen"
+	"golang.org/x/mod/modfile"
+)
+
+// handlersChiDir is the directory of the generated chi handler packages.
+const handlersChiDir = "pkg/handlers"
+
+// generateHandlers writes annotated handler stubs for the operations of a
+// Postman collection, or of a swagger or OpenAPI 3 document, with the
+// models of their bodies and responses and their route registrations.
+// Existing handlers, models and routes are left alone.
+func generateHandlers(currpath, from, framework string) error {
+	if framework != "beego" && framework != "chi" {
+		return fmt.Errorf("unknown framework %q, expected beego or chi", framework)
+	}
+	if from == "" {
+		return errors.New("-from is missing, pass a Postman collection or an OpenAPI document")
+	}
+
+	spec, err := loadHandlersSpec(from)
+	if err != nil {
+		return err
+	}
+	module, err := modulePath(currpath)
+	if err != nil {
+		return err
+	}
+
+	h := newHandlersGen(spec, framework)
+	if err := h.writeModels(path.Join(currpath, "models")); err != nil {
+		return err
+	}
+	if framework == "beego" {
+		for _, g := range h.groups {
+			fpath := path.Join(currpath, "controllers", handlersFileName(g.Tag)+".go")
+			if err := h.writeGroup(fpath, g, beegoHandlersTpl, beegoStubsTpl); err != nil {
+				return err
+			}
+		}
+		return h.writeBeegoRouter(path.Join(currpath, "routers", "router.go"), module)
+	}
+	for _, g := range h.groups {
+		fpath := path.Join(currpath, handlersChiDir, g.Name, g.Name+".go")
+		if err := h.writeGroup(fpath, g, chiHandlersTpl, chiStubsTpl); err != nil {
+			return err
+		}
+	}
+	return h.writeChiRoutes(path.Join(currpath, docgen.DefaultChiRoutes), module)
+}
+
+// modulePath returns the module path of the go.mod in dir.
+func modulePath(dir string) (string, error) {
+	data, err := os.ReadFile(path.Join(dir, "go.mod"))
+	if err != nil {
+		return "", fmt.Errorf("could not read the module path: %v", err)
+	}
+	module := modfile.ModulePath(data)
+	if module == "" {
+		return "", fmt.Errorf("no module path in %s", path.Join(dir, "go.mod"))
+	}
+	return module, nil
+}
+
+// loadHandlersSpec reads a Postman collection, or a swagger or OpenAPI 3
+// document.
+func loadHandlersSpec(from string) (*docgen.Spec, error) {
+	data, err := os.ReadFile(from)
+	if err != nil {
+		return nil, err
+	}
+	var doc map[string]interface{}
+	if json.Unmarshal(data, &doc) == nil && isPostmanCollection(doc) {
+		return postmanSpec(doc)
+	}
+	return docgen.LoadSpec(from)
+}
+
+type handlersGen struct {
+	spec      *docgen.Spec
+	framework string
+	models    *goClientGen
+	groups    []*handlerGroup
+	taken     map[string]bool // definition names
+}
+
+// handlerGroup is a beego controller or a chi handler package, one per tag.
+type handlerGroup struct {
+	Tag         string
+	Description string
+	// Name is the controller type or the package name.
+	Name string
+	// Prefix is the beego namespace of the controller, relative to the base
+	// path, e.g. /orders.
+	Prefix string
+	Stubs  []handlerStub
+}
+
+type handlerStub struct {
+	Name string
+	// Doc has the comment lines of the stub, annotations included.
+	Doc []string
+	op  docgen.Operation
+}
+
+func newHandlersGen(spec *docgen.Spec, framework string) *handlersGen {
+	h := &handlersGen{spec: spec, framework: framework, taken: make(map[string]bool)}
+	for _, name := range spec.Definitions() {
+		h.taken[name] = true
+	}
+
+	index := make(map[string]*handlerGroup)
+	for _, op := range spec.Operations() {
+		tag := handlerTag(op)
+		g, ok := index[tag]
+		if !ok {
+			g = &handlerGroup{Tag: tag, Name: handlerPackage(tag)}
+			if framework == "beego" {
+				g.Name = goIdentifier(tag) + "Controller"
+			}
+			index[tag] = g
+			h.groups = append(h.groups, g)
+		}
+		g.Stubs = append(g.Stubs, handlerStub{op: op})
+	}
+	sort.Slice(h.groups, func(i, j int) bool { return h.groups[i].Tag < h.groups[j].Tag })
+
+	tags, _ := spec.Document["tags"].([]interface{})
+	for _, g := range h.groups {
+		for _, t := range tags {
+			if tag, _ := t.(map[string]interface{}); tag["name"] == g.Tag {
+				g.Description, _ = tag["description"].(string)
+			}
+		}
+
+		var paths []string
+		for _, s := range g.Stubs {
+			paths = append(paths, s.op.Path)
+		}
+		g.Prefix = staticPrefix(paths)
+
+		names := make(map[string]bool)
+		for i := range g.Stubs {
+			s := &g.Stubs[i]
+			name := handlerName(s.op, g.Prefix)
+			s.Name = name
+			for n := 2; names[s.Name]; n++ {
+				s.Name = name + strconv.Itoa(n)
+			}
+			names[s.Name] = true
+			h.nameSchemas(s.op, goIdentifier(g.Tag)+s.Name)
+		}
+	}
+
+	// The inline schemas are definitions now, name their Go types.
+	h.models = newGoTypesGen(spec, nil)
+	for _, g := range h.groups {
+		for i := range g.Stubs {
+			g.Stubs[i].Doc = h.doc(g, g.Stubs[i])
+		}
+	}
+	return h
+}
+
+// handlerTag returns the first tag of op, or the first path segment that
+// is not a parameter or a version.
+func handlerTag(op docgen.Operation) string {
+	if tags := op.Tags(); len(tags) > 0 {
+		return tags[0]
+	}
+	for _, seg := range strings.Split(op.Path, "/") {
+		if seg != "" && !strings.Contains(seg, "{") && !versionRegexp.MatchString(seg) {
+			return seg
+		}
+	}
+	return "default"
+}
+
+var versionRegexp = regexp.MustCompile(`^v[0-9]+$`)
+
+// handlerPackage turns a tag into a package name.
+func handlerPackage(tag string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(tag) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		}
+	}
+	name := b.String()
+	if name == "" || unicode.IsDigit([]rune(name)[0]) {
+		name = "handlers" + name
+	}
+	return name
+}
+
+// staticPrefix returns the literal segments all the paths start with.
+func staticPrefix(paths []string) string {
+	if len(paths) == 0 {
+		return ""
+	}
+	prefix := strings.Split(paths[0], "/")
+	for _, p := range paths[1:] {
+		segs := strings.Split(p, "/")
+		n := 0
+		for n < len(prefix) && n < len(segs) && prefix[n] == segs[n] {
+			n++
+		}
+		prefix = prefix[:n]
+	}
+	for i, seg := range prefix {
+		if strings.Contains(seg, "{") {
+			prefix = prefix[:i]
+			break
+		}
+	}
+	return strings.Join(prefix, "/")
+}
+
+// handlerName returns the name of the handler of op: the operationId, or
+// the method and the path after the prefix, e.g. GET /orders/{id}/lines is
+// GetLinesByID.
+func handlerName(op docgen.Operation, prefix string) string {
+	if id, ok := op.Op["operationId"].(string); ok && id != "" {
+		return goIdentifier(id[strings.LastIndex(id, ".")+1:])
+	}
+	words := []string{strings.ToLower(op.Method)}
+	var by []string
+	for _, seg := range strings.Split(strings.TrimPrefix(op.Path, prefix), "/") {
+		if m := docgen.PathParamRegexp.FindStringSubmatch(seg); m != nil {
+			by = append(by, m[1])
+		} else if seg != "" {
+			words = append(words, seg)
+		}
+	}
+	name := goIdentifier(strings.Join(words, " "))
+	if len(by) > 0 {
+		name += "By" + goIdentifier(strings.Join(by, " and "))
+	}
+	return name
+}
+
+// nameSchemas moves the inline object schemas of the body and the
+// responses of op to definitions, so that they get a model.
+func (h *handlersGen) nameSchemas(op docgen.Operation, base string) {
+	for _, param := range op.Parameters() {
+		if param["in"] == "body" {
+			schema, _ := param["schema"].(map[string]interface{})
+			param["schema"] = h.named(schema, base+"Request")
+		}
+	}
+	for code, resp := range op.Responses() {
+		name := base + "Response"
+		if !strings.HasPrefix(code, "2") {
+			status, _ := strconv.Atoi(code)
+			name = base + goIdentifier(http.StatusText(status)+" "+code) + "Response"
+		}
+		schema, _ := resp["schema"].(map[string]interface{})
+		if schema != nil {
+			resp["schema"] = h.named(schema, name)
+		}
+	}
+}
+
+func (h *handlersGen) named(schema map[string]interface{}, name string) map[string]interface{} {
+	if schema == nil || docgen.RefName(schema) != "" {
+		return schema
+	}
+	if schema["type"] == "array" {
+		if items, ok := schema["items"].(map[string]interface{}); ok {
+			schema["items"] = h.named(items, name+"Item")
+		}
+		return schema
+	}
+	if !isObjectSchema(schema) {
+		return schema
+	}
+
+	definitions, _ := h.spec.Document["definitions"].(map[string]interface{})
+	if definitions == nil {
+		definitions = make(map[string]interface{})
+		h.spec.Document["definitions"] = definitions
+	}
+	unique := name
+	for i := 2; h.taken[unique]; i++ {
+		unique = name + strconv.Itoa(i)
+	}
+	h.taken[unique] = true
+	definitions[unique] = schema
+	return map[string]interface{}{"$ref": "#/definitions/" + unique}
+}
+
+// doc returns the comment of the stub of s: the description of the
+// operation followed by its annotations.
+func (h *handlersGen) doc(g *handlerGroup, s handlerStub) []string {
+	op := s.op
+	var lines []string
+	if desc, ok := op.Op["description"].(string); ok && desc != "" {
+		lines = append(lines, commentLines(desc)...)
+	}
+	if h.framework == "beego" {
+		lines = append(lines, "@Title "+s.Name)
+	}
+	if summary, ok := op.Op["summary"].(string); ok && summary != "" {
+		lines = append(lines, "@Summary "+strings.Join(strings.Fields(summary), " "))
+	}
+	if deprecated, _ := op.Op["deprecated"].(bool); deprecated {
+		lines = append(lines, "@Deprecated true")
+	}
+
+	var accepts []string
+	hasBody := false
+	for _, param := range op.Parameters() {
+		hasBody = hasBody || param["in"] == "body" || param["in"] == "formData"
+	}
+	if hasBody {
+		for _, ct := range h.spec.Consumes(op) {
+			if accept, ok := handlerAccepts[ct]; ok && !containsString(accepts, accept) {
+				accepts = append(accepts, accept)
+			}
+		}
+	}
+	if len(accepts) > 0 {
+		lines = append(lines, "@Accept "+strings.Join(accepts, ","))
+	}
+
+	for _, param := range op.Parameters() {
+		lines = append(lines, h.paramAnnotation(param))
+	}
+
+	responses := op.Responses()
+	codes := make([]string, 0, len(responses))
+	for code := range responses {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	for _, code := range codes {
+		resp := responses[code]
+		desc, _ := resp["description"].(string)
+		desc = strings.Join(strings.Fields(desc), " ")
+		if desc == "" {
+			status, _ := strconv.Atoi(code)
+			desc = strings.ToLower(http.StatusText(status))
+		}
+		if !strings.HasPrefix(code, "2") {
+			lines = append(lines, strings.TrimSpace("@Failure "+code+" "+desc))
+			continue
+		}
+		schema, _ := resp["schema"].(map[string]interface{})
+		if typ := h.responseType(schema); typ != "" {
+			lines = append(lines, "@Success "+code+" "+typ)
+		} else {
+			lines = append(lines, strings.TrimSpace("@Success "+code+" "+desc))
+		}
+	}
+
+	rt := h.spec.BasePath() + op.Path
+	if h.framework == "beego" {
+		rt = beegoRoute(strings.TrimPrefix(op.Path, g.Prefix))
+		if rt == "" {
+			rt = "/"
+		}
+	}
+	return append(lines, fmt.Sprintf("@router %s [%s]", rt, strings.ToLower(op.Method)))
+}
+
+// handlerAccepts are the @Accept values of the content types.
+var handlerAccepts = map[string]string{
+	"application/json":                  "json",
+	"application/xml":                   "xml",
+	"text/plain":                        "plain",
+	"text/html":                         "html",
+	"multipart/form-data":               "form",
+	"application/x-www-form-urlencoded": "application/x-www-form-urlencoded",
+}
+
+func (h *handlersGen) paramAnnotation(param map[string]interface{}) string {
+	name, _ := param["name"].(string)
+	in, _ := param["in"].(string)
+	required, _ := param["required"].(bool)
+	desc, _ := param["description"].(string)
+	desc = strings.Join(strings.Fields(desc), " ")
+	if desc == "" {
+		desc = "The " + name
+	}
+
+	typ := h.paramType(param)
+	if in == "body" {
+		schema, _ := param["schema"].(map[string]interface{})
+		if items, ok := schema["items"].(map[string]interface{}); ok {
+			schema = items
+		}
+		typ = "string"
+		if model := h.model(schema); model != "" {
+			typ = model
+		}
+	}
+	values := ""
+	if typ == "enum" {
+		enum, _ := param["enum"].([]interface{})
+		list := make([]string, len(enum))
+		for i, v := range enum {
+			list[i] = strings.ReplaceAll(fmt.Sprint(v), " ", "")
+		}
+		values = strings.Join(list, ",") + "\t"
+	}
+	return fmt.Sprintf("@Param\t%s\t%s\t%s\t%t\t%s\"%s\"", name, in, typ, required, values, strings.ReplaceAll(desc, `"`, `'`))
+}
+
+// paramType returns the @Param type of a non-body parameter.
+func (h *handlersGen) paramType(param map[string]interface{}) string {
+	if enum, ok := param["enum"].([]interface{}); ok && len(enum) > 0 {
+		return "enum"
+	}
+	format, _ := param["format"].(string)
+	switch param["type"] {
+	case "array":
+		items, _ := param["items"].(map[string]interface{})
+		if items == nil {
+			return "[]string"
+		}
+		if _, ok := items["enum"]; ok {
+			delete(items, "enum")
+		}
+		return "[]" + h.paramType(items)
+	case "integer":
+		if format == "int32" || format == "int64" {
+			return format
+		}
+		return "integer"
+	case "number":
+		switch format {
+		case "float":
+			return "float32"
+		case "double":
+			return "float64"
+		}
+		return "number"
+	case "boolean", "file":
+		return param["type"].(string)
+	}
+	return "string"
+}
+
+// model returns the models type of a reference, or "" for other schemas.
+func (h *handlersGen) model(schema map[string]interface{}) string {
+	if ident, ok := h.models.names[docgen.RefName(schema)]; ok {
+		return "models." + ident
+	}
+	return ""
+}
+
+// responseType returns the @Success type of a response schema, e.g.
+// {array} models.Order, or "" when the schema has no model.
+func (h *handlersGen) responseType(schema map[string]interface{}) string {
+	kind := "{object}"
+	if schema["type"] == "array" {
+		kind = "{array}"
+		schema, _ = schema["items"].(map[string]interface{})
+	}
+	if schema == nil {
+		return ""
+	}
+	if model := h.model(schema); model != "" {
+		return kind + " " + model
+	}
+	switch typ := h.models.goType(schema, false); typ {
+	case "string", "int32", "int64", "float32", "float64", "bool", "time.Time":
+		return kind + " " + typ
+	}
+	return ""
+}
+
+// writeModels writes a file per definition to dir, the models that exist
+// already are skipped.
+func (h *handlersGen) writeModels(dir string) error {
+	existing, err := declaredNames(dir)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	for _, name := range h.spec.Definitions() {
+		m := h.models.model(name)
+		if existing[m.Name] {
+			ColorLog("[WARN] models.%s already exists, skipping\n", m.Name)
+			continue
+		}
+		types := []string{m.Type}
+		for _, f := range m.Fields {
+			types = append(types, f.Type)
+		}
+		data := goClientData{Package: "models", Models: []goClientModel{m}, ModelImports: goImports(types)}
+		if err := writeTemplate(path.Join(dir, handlersFileName(m.Name)+".go"), handlersModelTpl, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// declaredNames returns the types and functions of the package in dir,
+// methods as Type.Method.
+func declaredNames(dir string) (map[string]bool, error) {
+	names := make(map[string]bool)
+	pkgs, err := parser.ParseDir(token.NewFileSet(), dir, nil, 0)
+	if os.IsNotExist(err) {
+		return names, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	for _, pkg := range pkgs {
+		for _, f := range pkg.Files {
+			for _, decl := range f.Decls {
+				switch d := decl.(type) {
+				case *ast.GenDecl:
+					for _, spec := range d.Specs {
+						if ts, ok := spec.(*ast.TypeSpec); ok {
+							names[ts.Name.Name] = true
+						}
+					}
+				case *ast.FuncDecl:
+					name := d.Name.Name
+					if d.Recv != nil && len(d.Recv.List) > 0 {
+						typ := d.Recv.List[0].Type
+						if star, ok := typ.(*ast.StarExpr); ok {
+							typ = star.X
+						}
+						if ident, ok := typ.(*ast.Ident); ok {
+							name = ident.Name + "." + name
+						}
+					}
+					names[name] = true
+				}
+			}
+		}
+	}
+	return names, nil
+}
+
+// handlersFileName turns an identifier into a file name, e.g. OrderID is
+// order_id.
+func handlersFileName(ident string) string {
+	runes := []rune(goIdentifier(ident))
+	var b strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) && i > 0 &&
+			(!unicode.IsUpper(runes[i-1]) || (i+1 < len(runes) && unicode.IsLower(runes[i+1]))) {
+			b.WriteRune('_')
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return b.String()
+}
+
+// writeGroup writes the stubs of g to fpath. When the file exists the
+// missing stubs are appended to it.
+func (h *handlersGen) writeGroup(fpath string, g *handlerGroup, fileTpl, stubsTpl string) error {
+	existing, err := declaredNames(path.Dir(fpath))
+	if err != nil {
+		return err
+	}
+	missing := *g
+	missing.Stubs = nil
+	for _, s := range g.Stubs {
+		name := s.Name
+		if h.framework == "beego" {
+			name = g.Name + "." + s.Name
+		}
+		if existing[name] {
+			ColorLog("[WARN] %s already exists, skipping\n", name)
+			continue
+		}
+		missing.Stubs = append(missing.Stubs, s)
+	}
+
+	src, err := os.ReadFile(fpath)
+	if os.IsNotExist(err) {
+		if err := os.MkdirAll(path.Dir(fpath), 0755); err != nil {
+			return err
+		}
+		if h.framework == "beego" && existing[g.Name] {
+			// The controller is declared in another file.
+			fileTpl = "package controllers\n\nimport \"net/http\"\n" + stubsTpl
+		}
+		return writeTemplate(fpath, fileTpl, missing)
+	}
+	if err != nil || len(missing.Stubs) == 0 {
+		return err
+	}
+
+	t, err := template.New(path.Base(fpath)).Parse(stubsTpl)
+	if err != nil {
+		return err
+	}
+	buf := bytes.NewBuffer(src)
+	if err := t.Execute(buf, missing); err != nil {
+		return err
+	}
+	out := addImports(buf.Bytes(), "net/http")
+	return writeSource(fpath, out)
+}
+
+// addImports adds an import declaration after the package clause for the
+// paths src does not import yet.
+func addImports(src []byte, paths ...string) []byte {
+	f, err := parser.ParseFile(token.NewFileSet(), "", src, parser.ImportsOnly)
+	if err != nil {
+		return src
+	}
+	var decls []string
+	for _, p := range paths {
+		imported := false
+		for _, im := range f.Imports {
+			imported = imported || im.Path.Value == strconv.Quote(p)
+		}
+		if !imported {
+			decls = append(decls, "import "+strconv.Quote(p)+"\n")
+		}
+	}
+	if len(decls) == 0 {
+		return src
+	}
+	end := int(f.Name.End()) - 1
+	return []byte(string(src[:end]) + "\n\n" + strings.Join(decls, "") + string(src[end:]))
+}
+
+// writeSource formats src and writes it to the existing file fpath.
+func writeSource(fpath string, src []byte) error {
+	out, err := goformat.Source(src)
+	if err != nil {
+		return fmt.Errorf("could not format %s: %v", fpath, err)
+	}
+	if err := os.WriteFile(fpath, out, 0644); err != nil {
+		return err
+	}
+	fmt.Fprintf(NewColorWriter(os.Stdout), "\t%s%supdate%s\t %s%s\n", "\x1b[32m", "\x1b[1m", "\x1b[21m", fpath, "\x1b[0m")
+	return nil
+}
+
+// namespace returns the beego.NSNamespace or beego.NSInclude expression of
+// the controller of g.
+func (g *handlerGroup) namespace() string {
+	include := fmt.Sprintf("beego.NSInclude(\n&controllers.%s{},\n)", g.Name)
+	if g.Prefix == "" {
+		return include
+	}
+	return fmt.Sprintf("beego.NSNamespace(%q,\n%s,\n)", g.Prefix, include)
+}
+
+// writeBeegoRouter adds the controllers to the namespace of the router file,
+// or creates it.
+func (h *handlersGen) writeBeegoRouter(fpath, module string) error {
+	src, err := os.ReadFile(fpath)
+	if os.IsNotExist(err) {
+		if err := os.MkdirAll(path.Dir(fpath), 0755); err != nil {
+			return err
+		}
+		data := struct {
+			Module     string
+			BasePath   string
+			Info       map[string]interface{}
+			Namespaces []string
+		}{Module: module, BasePath: h.spec.BasePath()}
+		data.Info, _ = h.spec.Document["info"].(map[string]interface{})
+		for _, g := range h.groups {
+			data.Namespaces = append(data.Namespaces, g.namespace())
+		}
+		return writeTemplate(fpath, beegoRouterTpl, data)
+	}
+	if err != nil {
+		return err
+	}
+
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, fpath, src, 0)
+	if err != nil {
+		return err
+	}
+	var call *ast.CallExpr
+	ast.Inspect(f, func(n ast.Node) bool {
+		if c, ok := n.(*ast.CallExpr); ok && call == nil {
+			if sel, ok := c.Fun.(*ast.SelectorExpr); ok && sel.Sel.Name == "NewNamespace" {
+				call = c
+			}
+		}
+		return call == nil
+	})
+
+	var add []string
+	for _, g := range h.groups {
+		if !bytes.Contains(src, []byte("controllers."+g.Name+"{")) {
+			add = append(add, g.namespace())
+		}
+	}
+	if len(add) == 0 {
+		return nil
+	}
+	if call == nil || len(call.Args) == 0 {
+		for _, g := range h.groups {
+			ColorLog("[WARN] No beego.NewNamespace in %s, register controllers.%s yourself\n", fpath, g.Name)
+		}
+		return nil
+	}
+
+	end := fset.Position(call.Args[len(call.Args)-1].End()).Offset
+	rparen := fset.Position(call.Rparen).Offset
+	insert := ",\n" + strings.Join(add, ",\n") + ",\n"
+	if strings.Contains(string(src[end:rparen]), ",") {
+		end = end + strings.Index(string(src[end:rparen]), ",") + 1
+		insert = "\n" + strings.Join(add, ",\n") + ",\n"
+	}
+	out := []byte(string(src[:end]) + insert + string(src[end:]))
+	return writeSource(fpath, addImports(out, module+"/controllers"))
+}
+
+// writeChiRoutes registers the handlers before the return of the function
+// creating the chi router, or creates the routes file.
+func (h *handlersGen) writeChiRoutes(fpath, module string) error {
+	var imports []string
+	for _, g := range h.groups {
+		imports = append(imports, module+"/"+handlersChiDir+"/"+g.Name)
+	}
+
+	src, err := os.ReadFile(fpath)
+	if os.IsNotExist(err) {
+		if err := os.MkdirAll(path.Dir(fpath), 0755); err != nil {
+			return err
+		}
+		data := struct {
+			Package string
+			Imports []string
+			Routes  []string
+		}{Package: path.Base(path.Dir(fpath)), Imports: imports, Routes: h.chiRoutes("mux", nil)}
+		return writeTemplate(fpath, chiRoutesTpl, data)
+	}
+	if err != nil {
+		return err
+	}
+
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, fpath, src, 0)
+	if err != nil {
+		return err
+	}
+	var mux string
+	var ret *ast.ReturnStmt
+	for _, decl := range f.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Body == nil {
+			continue
+		}
+		for _, stmt := range fn.Body.List {
+			switch s := stmt.(type) {
+			case *ast.AssignStmt:
+				if call, ok := s.Rhs[0].(*ast.CallExpr); ok && len(s.Lhs) == 1 {
+					if sel, ok := call.Fun.(*ast.SelectorExpr); ok && sel.Sel.Name == "NewRouter" {
+						mux = s.Lhs[0].(*ast.Ident).Name
+					}
+				}
+			case *ast.ReturnStmt:
+				if mux != "" && ret == nil {
+					ret = s
+				}
+			}
+		}
+	}
+	if ret == nil {
+		ColorLog("[WARN] No chi.NewRouter in %s, register the handlers yourself\n", fpath)
+		return nil
+	}
+
+	routes := h.chiRoutes(mux, src)
+	if len(routes) == 0 {
+		return nil
+	}
+	at := fset.Position(ret.Pos()).Offset
+	out := []byte(string(src[:at]) + strings.Join(routes, "\n") + "\n" + string(src[at:]))
+	return writeSource(fpath, addImports(out, imports...))
+}
+
+// chiRoutes returns the registrations of the handlers that src does not
+// reference yet.
+func (h *handlersGen) chiRoutes(mux string, src []byte) []string {
+	var routes []string
+	for _, g := range h.groups {
+		for _, s := range g.Stubs {
+			handler := g.Name + "." + s.Name
+			if bytes.Contains(src, []byte(handler+")")) {
+				continue
+			}
+			routes = append(routes, fmt.Sprintf("%s.%s(%q, %s)", mux,
+				goIdentifier(strings.ToLower(s.op.Method)), h.spec.BasePath()+s.op.Path, handler))
+		}
+	}
+	return routes
+}
+
+// isPostmanCollection tells whether doc is a Postman collection.
+func isPostmanCollection(doc map[string]interface{}) bool {
+	info, _ := doc["info"].(map[string]interface{})
+	schema, _ := info["schema"].(string)
+	_, items := doc["item"].([]interface{})
+	return items && strings.Contains(schema, "getpostman.com")
+}
+
+// postmanSpec converts a Postman collection into a swagger document. The
+// top folders are the tags, and the schemas of the bodies and of the saved
+// responses are inferred from their JSON.
+func postmanSpec(collection map[string]interface{}) (*docgen.Spec, error) {
+	c := &postmanConverter{
+		paths:       make(map[string]interface{}),
+		definitions: make(map[string]interface{}),
+		taken:       make(map[string]bool),
+	}
+	items, _ := collection["item"].([]interface{})
+	c.walk(items, "")
+
+	info, _ := collection["info"].(map[string]interface{})
+	doc := map[string]interface{}{
+		"swagger": "2.0",
+		"info": map[string]interface{}{
+			"title":       info["name"],
+			"description": postmanText(info["description"]),
+			"version":     "1.0.0",
+		},
+		"paths":       c.paths,
+		"definitions": c.definitions,
+	}
+
+	// A version all the paths start with is the base path.
+	var version string
+	for rt := range c.paths {
+		seg := strings.SplitN(strings.TrimPrefix(rt, "/"), "/", 2)[0]
+		if !versionRegexp.MatchString(seg) || (version != "" && seg != version) {
+			version = ""
+			break
+		}
+		version = seg
+	}
+	if version != "" {
+		paths := make(map[string]interface{})
+		for rt, item := range c.paths {
+			rt = strings.TrimPrefix(rt, "/"+version)
+			if rt == "" {
+				rt = "/"
+			}
+			paths[rt] = item
+		}
+		doc["paths"] = paths
+		doc["basePath"] = "/" + version
+	}
+
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	spec := &docgen.Spec{}
+	if err := json.Unmarshal(data, &spec.Document); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &spec.Swagger); err != nil {
+		return nil, err
+	}
+	return spec, nil
+}
+
+type postmanConverter struct {
+	paths       map[string]interface{}
+	definitions map[string]interface{}
+	taken       map[string]bool
+}
+
+func (c *postmanConverter) walk(items []interface{}, folder string) {
+	for _, it := range items {
+		item, _ := it.(map[string]interface{})
+		name, _ := item["name"].(string)
+		if children, ok := item["item"].([]interface{}); ok {
+			if folder == "" {
+				c.walk(children, name)
+			} else {
+				c.walk(children, folder)
+			}
+			continue
+		}
+		if _, ok := item["request"]; ok {
+			c.request(item, folder)
+		}
+	}
+}
+
+// postmanStandardHeaders are not documented as parameters.
+var postmanStandardHeaders = map[string]bool{
+	"accept": true, "content-type": true, "content-length": true,
+	"user-agent": true, "host": true, "connection": true, "authorization": true,
+}
+
+func (c *postmanConverter) request(item map[string]interface{}, folder string) {
+	name, _ := item["name"].(string)
+	req, ok := item["request"].(map[string]interface{})
+	if !ok {
+		// A request can be its URL only.
+		req = map[string]interface{}{"url": item["request"]}
+	}
+	method, _ := req["method"].(string)
+	if method == "" {
+		method = "GET"
+	}
+	method = strings.ToLower(method)
+
+	segments, variables, query := postmanURL(req["url"])
+	op := map[string]interface{}{}
+	if name != "" && !strings.HasPrefix(strings.ToUpper(name), strings.ToUpper(method)+" /") {
+		op["summary"] = name
+		op["operationId"] = goIdentifier(name)
+	}
+	base := goIdentifier(name)
+	if op["operationId"] == nil {
+		words := []string{method}
+		for _, seg := range segments {
+			if !versionRegexp.MatchString(seg) {
+				words = append(words, seg)
+			}
+		}
+		base = goIdentifier(strings.Join(words, " "))
+	}
+	if desc := postmanText(req["description"]); desc != "" {
+		op["description"] = desc
+	}
+	if folder != "" {
+		op["tags"] = []interface{}{folder}
+	}
+
+	var params []interface{}
+	for i, seg := range segments {
+		var param string
+		switch {
+		case strings.HasPrefix(seg, ":"):
+			param = seg[1:]
+		case strings.HasPrefix(seg, "{{") && strings.HasSuffix(seg, "}}"):
+			param = strings.Trim(seg, "{}")
+		default:
+			continue
+		}
+		segments[i] = "{" + param + "}"
+		p := map[string]interface{}{"name": param, "in": "path", "required": true, "type": "string"}
+		if v, ok := variables[param]; ok {
+			p["type"] = postmanValueType(postmanText(v["value"]))
+			if desc := postmanText(v["description"]); desc != "" {
+				p["description"] = desc
+			}
+		}
+		params = append(params, p)
+	}
+	for _, q := range query {
+		if disabled, _ := q["disabled"].(bool); disabled {
+			continue
+		}
+		key, _ := q["key"].(string)
+		p := map[string]interface{}{"name": key, "in": "query", "required": false, "type": postmanValueType(postmanText(q["value"]))}
+		if desc := postmanText(q["description"]); desc != "" {
+			p["description"] = desc
+		}
+		params = append(params, p)
+	}
+	headers, _ := req["header"].([]interface{})
+	for _, hd := range headers {
+		header, _ := hd.(map[string]interface{})
+		key, _ := header["key"].(string)
+		if disabled, _ := header["disabled"].(bool); disabled || key == "" || postmanStandardHeaders[strings.ToLower(key)] {
+			continue
+		}
+		p := map[string]interface{}{"name": key, "in": "header", "required": false, "type": "string"}
+		if desc := postmanText(header["description"]); desc != "" {
+			p["description"] = desc
+		}
+		params = append(params, p)
+	}
+
+	if body, ok := req["body"].(map[string]interface{}); ok {
+		switch body["mode"] {
+		case "raw":
+			var v interface{}
+			if raw, _ := body["raw"].(string); json.Unmarshal([]byte(raw), &v) == nil {
+				op["consumes"] = []interface{}{"application/json"}
+				params = append(params, map[string]interface{}{
+					"name": "body", "in": "body", "required": true,
+					"schema": c.schema(v, base+"Request"),
+				})
+			}
+		case "urlencoded", "formdata":
+			contentType := "application/x-www-form-urlencoded"
+			if body["mode"] == "formdata" {
+				contentType = "multipart/form-data"
+			}
+			op["consumes"] = []interface{}{contentType}
+			fields, _ := body[body["mode"].(string)].([]interface{})
+			for _, fd := range fields {
+				field, _ := fd.(map[string]interface{})
+				key, _ := field["key"].(string)
+				if disabled, _ := field["disabled"].(bool); disabled || key == "" {
+					continue
+				}
+				p := map[string]interface{}{"name": key, "in": "formData", "required": false, "type": postmanValueType(postmanText(field["value"]))}
+				if field["type"] == "file" {
+					p["type"] = "file"
+				}
+				if desc := postmanText(field["description"]); desc != "" {
+					p["description"] = desc
+				}
+				params = append(params, p)
+			}
+		}
+	}
+	if len(params) > 0 {
+		op["parameters"] = params
+	}
+
+	responses := make(map[string]interface{})
+	saved, _ := item["response"].([]interface{})
+	for _, r := range saved {
+		resp, _ := r.(map[string]interface{})
+		code, _ := resp["code"].(float64)
+		if code == 0 {
+			code = http.StatusOK
+		}
+		key := strconv.Itoa(int(code))
+		if _, ok := responses[key]; ok {
+			continue
+		}
+		out := map[string]interface{}{"description": strings.ToLower(http.StatusText(int(code)))}
+		var v interface{}
+		if body, _ := resp["body"].(string); json.Unmarshal([]byte(body), &v) == nil {
+			name := base + "Response"
+			if code >= 300 {
+				name = base + goIdentifier(http.StatusText(int(code))) + "Response"
+			}
+			out["schema"] = c.schema(v, name)
+		}
+		responses[key] = out
+	}
+	if len(responses) == 0 {
+		responses["200"] = map[string]interface{}{"description": "ok"}
+	}
+	op["responses"] = responses
+
+	rt := "/" + strings.Join(segments, "/")
+	pathItem, ok := c.paths[rt].(map[string]interface{})
+	if !ok {
+		pathItem = make(map[string]interface{})
+		c.paths[rt] = pathItem
+	}
+	if _, ok := pathItem[method]; !ok {
+		pathItem[method] = op
+	}
+}
+
+// schema infers the schema of a JSON value. Objects become definitions
+// named after their place in the document.
+func (c *postmanConverter) schema(v interface{}, name string) map[string]interface{} {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		props := make(map[string]interface{}, len(t))
+		for k, val := range t {
+			props[k] = c.schema(val, name+goIdentifier(k))
+		}
+		unique := name
+		for i := 2; c.taken[unique]; i++ {
+			unique = name + strconv.Itoa(i)
+		}
+		c.taken[unique] = true
+		c.definitions[unique] = map[string]interface{}{"type": "object", "properties": props}
+		return map[string]interface{}{"$ref": "#/definitions/" + unique}
+	case []interface{}:
+		items := map[string]interface{}{}
+		if len(t) > 0 {
+			items = c.schema(t[0], name+"Item")
+		}
+		return map[string]interface{}{"type": "array", "items": items}
+	case string:
+		return map[string]interface{}{"type": "string"}
+	case float64:
+		if t == float64(int64(t)) {
+			return map[string]interface{}{"type": "integer", "format": "int64"}
+		}
+		return map[string]interface{}{"type": "number", "format": "double"}
+	case bool:
+		return map[string]interface{}{"type": "boolean"}
+	}
+	return map[string]interface{}{}
+}
+
+// postmanURL returns the path segments, the variables by key and the query
+// of a Postman URL, given as a string or an object.
+func postmanURL(v interface{}) (segments []string, variables map[string]map[string]interface{}, query []map[string]interface{}) {
+	variables = make(map[string]map[string]interface{})
+	url, _ := v.(map[string]interface{})
+	raw, _ := v.(string)
+	if url != nil {
+		raw, _ = url["raw"].(string)
+	}
+
+	switch p := url["path"].(type) {
+	case []interface{}:
+		for _, seg := range p {
+			segments = append(segments, postmanText(seg))
+		}
+	case string:
+		segments = strings.Split(strings.Trim(p, "/"), "/")
+	default:
+		rt := raw
+		if i := strings.IndexAny(rt, "?#"); i >= 0 {
+			rt = rt[:i]
+		}
+		if i := strings.Index(rt, "://"); i >= 0 {
+			rt = rt[i+3:]
+		}
+		if i := strings.Index(rt, "/"); i >= 0 {
+			rt = rt[i:]
+		} else {
+			rt = ""
+		}
+		segments = strings.Split(strings.Trim(rt, "/"), "/")
+	}
+	var clean []string
+	for _, seg := range segments {
+		if seg != "" {
+			clean = append(clean, seg)
+		}
+	}
+
+	list, _ := url["variable"].([]interface{})
+	for _, it := range list {
+		if variable, ok := it.(map[string]interface{}); ok {
+			key, _ := variable["key"].(string)
+			variables[key] = variable
+		}
+	}
+	list, _ = url["query"].([]interface{})
+	for _, it := range list {
+		if q, ok := it.(map[string]interface{}); ok {
+			query = append(query, q)
+		}
+	}
+	return clean, variables, query
+}
+
+// postmanText returns a string, or the content of a Postman description
+// object.
+func postmanText(v interface{}) string {
+	switch t := v.(type) {
+	case string:
+		return t
+	case map[string]interface{}:
+		s, _ := t["content"].(string)
+		return s
+	case nil:
+		return ""
+	}
+	return fmt.Sprint(v)
+}
+
+// postmanValueType returns the parameter type of a sample value.
+func postmanValueType(value string) string {
+	if _, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return "integer"
+	}
+	if _, err := strconv.ParseFloat(value, 64); err == nil {
+		return "number"
+	}
+	if value == "true" || value == "false" {
+		return "boolean"
+	}
+	return "string"
+}
+
+var handlersModelTpl = strings.TrimPrefix(goClientModelsTpl, "// Code generated by bee generate client. DO NOT EDIT.\n\n")
+
+const beegoStubsTpl = `{{range .Stubs}}
+{{range .Doc}}//{{if .}} {{.}}{{end}}
+{{end -}}
+func (c *{{$.Name}}) {{.Name}}() {
+	c.CustomAbort(http.StatusNotImplemented, "not implemented")
+}
+{{end}}`
+
+const beegoHandlersTpl = `package controllers
+
+import (
+	"net/http"
+
+	"github.com/astaxie/beego"
+)
+
+// Operations about {{.Tag}}
+{{- with .Description}}
+// @Tag {{$.Tag}} "{{.}}"{{end}}
+type {{.Name}} struct {
+	beego.Controller
+}
+` + beegoStubsTpl
+
+const chiStubsTpl = `{{range .Stubs}}
+{{range .Doc}}//{{if .}} {{.}}{{end}}
+{{end -}}
+func {{.Name}}(w http.ResponseWriter, r *http.Request) {
+	http.Error(w, "not implemented", http.StatusNotImplemented)
+}
+{{end}}`
+
+const chiHandlersTpl = `// Package {{.Name}} handles the {{.Tag}} operations.
+package {{.Name}}
+
+import "net/http"
+` + chiStubsTpl
+
+const beegoRouterTpl = `// @APIVersion {{with .Info.version}}{{.}}{{else}}1.0.0{{end}}
+{{- with .Info.title}}
+// @Title {{.}}{{end}}
+{{- with .Info.description}}
+// @Description {{.}}{{end}}
+package routers
+
+import (
+	"{{.Module}}/controllers"
+
+	"github.com/astaxie/beego"
+)
+
+func init() {
+	ns := beego.NewNamespace({{printf "%q" .BasePath}},
+	{{- range .Namespaces}}
+		{{.}},
+	{{- end}}
+	)
+	beego.AddNamespace(ns)
+}
+`
+
+const chiRoutesTpl = `package {{.Package}}
+
+import (
+	"github.com/go-chi/chi"
+{{range .Imports}}
+	"{{.}}"
+{{- end}}
+)
+
+// New returns the router of the API.
+func New() chi.Router {
+	mux := chi.NewRouter()
+{{- range .Routes}}
+	{{.}}
+{{- end}}
+	return mux
+}
+`
@@ -0,0 +1,207 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zalora/bee/docgen"
+)
+
+const handlersTestCollection = `{
+	"info": {"name": "Shop", "schema": "https://schema.getpostman.com/json/collection/v2.1.0/collection.json"},
+	"item": [
+		{"name": "orders", "item": [
+			{"name": "Get order", "request": {
+				"method": "GET",
+				"header": [{"key": "Accept", "value": "application/json"}, {"key": "X-Store", "value": "sg", "description": "The store"}],
+				"url": {"raw": "{{BASE_URL}}/v1/orders/:id?expand=lines", "path": ["v1", "orders", ":id"],
+					"query": [{"key": "expand", "value": "lines"}],
+					"variable": [{"key": "id", "value": "42", "description": "The order id"}]}
+			}, "response": [
+				{"code": 200, "body": "{\"id\": 42, \"total\": 1.5, \"lines\": [{\"sku\": \"A-1\"}]}"},
+				{"code": 404, "body": "not found"}
+			]},
+			{"name": "POST /v1/orders", "request": {
+				"method": "POST",
+				"url": "{{BASE_URL}}/v1/orders",
+				"body": {"mode": "raw", "raw": "{\"total\": 2}"}
+			}}
+		]},
+		{"name": "Note", "request": {
+			"method": "POST",
+			"url": "{{BASE_URL}}/v1/carts/{{cartID}}/note",
+			"body": {"mode": "urlencoded", "urlencoded": [{"key": "note", "value": "gift"}]}
+		}}
+	]
+}`
+
+func handlersTestSpec(t *testing.T) *docgen.Spec {
+	var doc map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(handlersTestCollection), &doc))
+	require.True(t, isPostmanCollection(doc))
+	spec, err := postmanSpec(doc)
+	require.NoError(t, err)
+	return spec
+}
+
+func TestPostmanSpec(t *testing.T) {
+	spec := handlersTestSpec(t)
+	assert.Equal(t, "/v1", spec.BasePath())
+
+	var ops []string
+	for _, op := range spec.Operations() {
+		ops = append(ops, op.Method+" "+op.Path)
+	}
+	assert.Equal(t, []string{"POST /carts/{cartID}/note", "POST /orders", "GET /orders/{id}"}, ops)
+
+	get := spec.Operations()[2]
+	assert.Equal(t, "GetOrder", get.Op["operationId"])
+	assert.Equal(t, []string{"orders"}, get.Tags())
+	assert.Equal(t, map[string]interface{}{
+		"name": "id", "in": "path", "required": true, "type": "integer", "description": "The order id",
+	}, get.Parameter("path", "id"))
+	assert.Equal(t, "string", get.Parameter("query", "expand")["type"])
+	assert.NotNil(t, get.Parameter("header", "X-Store"))
+	assert.Nil(t, get.Parameter("header", "Accept"))
+	assert.Equal(t, map[string]interface{}{"$ref": "#/definitions/GetOrderResponse"}, get.Responses()["200"]["schema"])
+	assert.Nil(t, get.Responses()["404"]["schema"])
+
+	assert.Equal(t, []string{"GetOrderResponse", "GetOrderResponseLinesItem", "PostOrdersRequest"}, spec.Definitions())
+	assert.Equal(t, map[string]interface{}{
+		"type":  "array",
+		"items": map[string]interface{}{"$ref": "#/definitions/GetOrderResponseLinesItem"},
+	}, spec.Definition("GetOrderResponse")["properties"].(map[string]interface{})["lines"])
+
+	post := spec.Operations()[1]
+	assert.Nil(t, post.Op["operationId"])
+	assert.Equal(t, map[string]interface{}{"$ref": "#/definitions/PostOrdersRequest"}, post.Parameter("body", "body")["schema"])
+
+	note := spec.Operations()[0]
+	assert.Equal(t, []interface{}{"application/x-www-form-urlencoded"}, note.Op["consumes"])
+	assert.Equal(t, "formData", note.Parameter("formData", "note")["in"])
+}
+
+func TestHandlersGen(t *testing.T) {
+	h := newHandlersGen(handlersTestSpec(t), "beego")
+	require.Len(t, h.groups, 2)
+	assert.Equal(t, "CartsController", h.groups[0].Name)
+	assert.Equal(t, "/carts", h.groups[0].Prefix)
+
+	orders := h.groups[1]
+	assert.Equal(t, "OrdersController", orders.Name)
+	assert.Equal(t, "/orders", orders.Prefix)
+	assert.Equal(t, "Post", orders.Stubs[0].Name)
+	assert.Equal(t, []string{
+		"@Title GetOrder",
+		"@Summary Get order",
+		"@Param\tid\tpath\tinteger\ttrue\t\"The order id\"",
+		"@Param\texpand\tquery\tstring\tfalse\t\"The expand\"",
+		"@Param\tX-Store\theader\tstring\tfalse\t\"The store\"",
+		"@Success 200 {object} models.GetOrderResponse",
+		"@Failure 404 not found",
+		"@router /:id [get]",
+	}, orders.Stubs[1].Doc)
+
+	h = newHandlersGen(handlersTestSpec(t), "chi")
+	assert.Equal(t, "carts", h.groups[0].Name)
+	assert.Equal(t, []string{
+		"@Summary Note",
+		"@Accept application/x-www-form-urlencoded",
+		"@Param\tcartID\tpath\tstring\ttrue\t\"The cartID\"",
+		"@Param\tnote\tformData\tstring\tfalse\t\"The note\"",
+		"@Success 200 ok",
+		"@router /v1/carts/{cartID}/note [post]",
+	}, h.groups[0].Stubs[0].Doc)
+}
+
+func TestHandlerName(t *testing.T) {
+	tests := []struct {
+		method   string
+		path     string
+		id       string
+		expected string
+	}{
+		{"GET", "/orders", "", "Get"},
+		{"GET", "/orders/{id}/lines/{sku}", "", "GetLinesByIDAndSKU"},
+		{"POST", "/orders/{id}/cancel", "", "PostCancelByID"},
+		{"GET", "/orders/{id}", "OrderController.GetOne", "GetOne"},
+		{"GET", "/orders/{id}", "getOrder", "GetOrder"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method+tt.path, func(t *testing.T) {
+			op := docgen.Operation{Method: tt.method, Path: tt.path, Op: map[string]interface{}{}}
+			if tt.id != "" {
+				op.Op["operationId"] = tt.id
+			}
+			assert.Equal(t, tt.expected, handlerName(op, "/orders"))
+		})
+	}
+}
+
+func TestHandlersFileName(t *testing.T) {
+	assert.Equal(t, "order_id", handlersFileName("OrderID"))
+	assert.Equal(t, "get_order_response", handlersFileName("GetOrderResponse"))
+	assert.Equal(t, "api_key", handlersFileName("APIKey"))
+	assert.Equal(t, "orders", handlersFileName("orders"))
+}
+
+func TestGenerateHandlers(t *testing.T) {
+	from := filepath.Join(t.TempDir(), "collection.json")
+	require.NoError(t, os.WriteFile(from, []byte(handlersTestCollection), 0644))
+	read := func(elem ...string) string {
+		b, err := os.ReadFile(filepath.Join(elem...))
+		require.NoError(t, err)
+		return string(b)
+	}
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module github.com/acme/shop\n"), 0644))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "routers"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "routers", "router.go"), []byte(`package routers
+
+import "github.com/astaxie/beego"
+
+func init() {
+	ns := beego.NewNamespace("/v1",
+		beego.NSNamespace("/orders",
+			beego.NSInclude(
+				&controllers.OrdersController{},
+			),
+		),
+	)
+	beego.AddNamespace(ns)
+}
+`), 0644))
+
+	require.NoError(t, generateHandlers(dir, from, "beego"))
+	router := read(dir, "routers", "router.go")
+	assert.Contains(t, router, "import \"github.com/acme/shop/controllers\"\n")
+	assert.Contains(t, router, "\t\t),\n\t\tbeego.NSNamespace(\"/carts\",\n\t\t\tbeego.NSInclude(\n\t\t\t\t&controllers.CartsController{},\n")
+	assert.Contains(t, read(dir, "controllers", "orders.go"), "func (c *OrdersController) GetOrder() {")
+	assert.Contains(t, read(dir, "models", "get_order_response.go"), "type GetOrderResponse struct {")
+
+	// Existing handlers are kept, missing ones are appended.
+	orders := filepath.Join(dir, "controllers", "orders.go")
+	require.NoError(t, os.WriteFile(orders, []byte("package controllers\n\nimport \"github.com/astaxie/beego\"\n\ntype OrdersController struct {\n\tbeego.Controller\n}\n\nfunc (c *OrdersController) GetOrder() {}\n"), 0644))
+	require.NoError(t, generateHandlers(dir, from, "beego"))
+	src := read(orders)
+	assert.Contains(t, src, "func (c *OrdersController) GetOrder() {}\n")
+	assert.Contains(t, src, "import \"net/http\"\n")
+	assert.Contains(t, src, "func (c *OrdersController) Post() {")
+	assert.Equal(t, router, read(dir, "routers", "router.go"))
+
+	dir = t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module github.com/acme/shop\n"), 0644))
+	require.NoError(t, generateHandlers(dir, from, "chi"))
+	routes := read(dir, docgen.DefaultChiRoutes)
+	assert.Contains(t, routes, "\t\"github.com/acme/shop/pkg/handlers/orders\"\n")
+	assert.Contains(t, routes, "\tmux.Get(\"/v1/orders/{id}\", orders.GetOrder)\n\treturn mux\n")
+	assert.Contains(t, read(dir, "pkg", "handlers", "carts", "carts.go"), "// @router /v1/carts/{cartID}/note [post]\nfunc Note(")
+
+	assert.Error(t, generateHandlers(dir, from, "gin"))
+}
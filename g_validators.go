@@ -0,0 +1,341 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	goformat "go/format"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/zalora/bee/docgen"
+)
+
+// generateValidators writes a package that rejects the requests which do
+// not match the annotations of their route, with a beego filter or a
+// net/http middleware for chi.
+func generateValidators(currpath, framework, pkg string) error {
+	if framework != "beego" && framework != "chi" {
+		return fmt.Errorf("unknown framework %q, expected beego or chi", framework)
+	}
+
+	spec, diagnostics, err := docgen.New(docsOptions(currpath)).Generate(context.Background())
+	for _, d := range diagnostics {
+		if d.Severity == docgen.SeverityWarning {
+			ColorLog("[WARN] %s\n", d.Message)
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	data, err := validatorsData(spec, pkg)
+	if err != nil {
+		return err
+	}
+
+	dir := path.Join(currpath, pkg)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	if err := writeValidationSchema(path.Join(dir, "schema.go"), data.Package); err != nil {
+		return err
+	}
+	if err := writeTemplate(path.Join(dir, "validation.go"), validationTpl, data); err != nil {
+		return err
+	}
+	if framework == "beego" {
+		return writeTemplate(path.Join(dir, "filter.go"), validationBeegoTpl, data)
+	}
+	return writeTemplate(path.Join(dir, "middleware.go"), validationChiTpl, data)
+}
+
+type validatorRoute struct {
+	Method    string
+	Path      string // swagger path template including the base path
+	BeegoPath string
+}
+
+type validatorsTplData struct {
+	Package string
+	Routes  []validatorRoute
+	Paths   []validatorRoute // one route per path, for the beego filters
+	// Spec is the JSON document the requests are validated against,
+	// without the fields that play no part in validation.
+	Spec string
+}
+
+// validationSchemaSrc is the validation code of docgen, copied into the
+// generated package so that it only depends on the standard library.
+//
+//go:embed docgen/schema/schema.go
+var validationSchemaSrc string
+
+// writeValidationSchema writes the validation code of docgen as a file of
+// the package pkg.
+func writeValidationSchema(fpath, pkg string) error {
+	src := strings.Replace(validationSchemaSrc, "package schema\n",
+		"// Code generated by bee generate validators from github.com/zalora/bee/docgen/schema. DO NOT EDIT.\n\npackage "+pkg+"\n", 1)
+	if err := os.WriteFile(fpath, []byte(src), 0644); err != nil {
+		return err
+	}
+	fmt.Fprintf(NewColorWriter(os.Stdout), "\t%s%screate%s\t %s%s\n", "\x1b[32m", "\x1b[1m", "\x1b[21m", fpath, "\x1b[0m")
+	return nil
+}
+
+func validatorsData(spec *docgen.Spec, pkg string) (validatorsTplData, error) {
+	data := validatorsTplData{Package: path.Base(pkg)}
+
+	seen := make(map[string]bool)
+	for _, r := range spec.Routes() {
+		route := validatorRoute{Method: r.Method, Path: r.Template, BeegoPath: beegoRoute(r.Template)}
+		data.Routes = append(data.Routes, route)
+		if !seen[r.Template] {
+			seen[r.Template] = true
+			data.Paths = append(data.Paths, route)
+		}
+	}
+
+	paths := make(map[string]interface{})
+	for _, op := range spec.Operations() {
+		item, ok := paths[op.Path].(map[string]interface{})
+		if !ok {
+			item = make(map[string]interface{})
+			paths[op.Path] = item
+		}
+		params := []interface{}{}
+		for _, p := range op.Parameters() {
+			params = append(params, stripDocFields(p))
+		}
+		item[strings.ToLower(op.Method)] = map[string]interface{}{"parameters": params}
+	}
+	definitions := make(map[string]interface{})
+	for _, name := range spec.Definitions() {
+		definitions[name] = stripDocFields(spec.Definition(name))
+	}
+
+	doc, err := json.MarshalIndent(map[string]interface{}{
+		"basePath":    spec.BasePath(),
+		"paths":       paths,
+		"definitions": definitions,
+	}, "", "\t")
+	if err != nil {
+		return data, err
+	}
+	data.Spec = goString(string(doc))
+	return data, nil
+}
+
+// stripDocFields drops the fields of a schema that play no part in
+// validation.
+func stripDocFields(v interface{}) interface{} {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(t))
+		for k, val := range t {
+			switch k {
+			case "description", "title", "example":
+				continue
+			case "properties":
+				// Keyed by property names, which may well be "title".
+				props := make(map[string]interface{})
+				m, _ := val.(map[string]interface{})
+				for name, prop := range m {
+					props[name] = stripDocFields(prop)
+				}
+				out[k] = props
+				continue
+			}
+			out[k] = stripDocFields(val)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(t))
+		for i, val := range t {
+			out[i] = stripDocFields(val)
+		}
+		return out
+	}
+	return v
+}
+
+// beegoRoute turns a swagger path template back into a beego route.
+func beegoRoute(rt string) string {
+	segments := strings.Split(rt, "/")
+	for i, seg := range segments {
+		switch seg {
+		case "{splat}":
+			segments[i] = "*"
+		case "{path}.{ext}":
+			segments[i] = "*.*"
+		default:
+			segments[i] = docgen.PathParamRegexp.ReplaceAllString(seg, ":$1")
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// goString quotes s as a raw string literal when possible.
+func goString(s string) string {
+	if strings.Contains(s, "`") {
+		return strconv.Quote(s)
+	}
+	return "`" + s + "`"
+}
+
+// writeTemplate executes tpl with data and writes the gofmt'ed result.
+func writeTemplate(fpath, tpl string, data interface{}) error {
+	t, err := template.New(path.Base(fpath)).Parse(tpl)
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, data); err != nil {
+		return err
+	}
+	src, err := goformat.Source(buf.Bytes())
+	if err != nil {
+		return fmt.Errorf("could not format %s: %v", fpath, err)
+	}
+	if err := os.WriteFile(fpath, src, 0644); err != nil {
+		return err
+	}
+	fmt.Fprintf(NewColorWriter(os.Stdout), "\t%s%screate%s\t %s%s\n", "\x1b[32m", "\x1b[1m", "\x1b[21m", fpath, "\x1b[0m")
+	return nil
+}
+
+const validationTpl = `// Code generated by bee generate validators. DO NOT EDIT.
+
+// Package {{.Package}} rejects the requests that do not match the swagger
+// annotations of their route.
+package {{.Package}}
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// Error is a request value that does not match the spec.
+type Error = ValidationError
+
+// Payload is the body of the responses to invalid requests.
+type Payload struct {
+	Message string  ` + "`json:\"message\"`" + `
+	Errors  []Error ` + "`json:\"errors\"`" + `
+}
+
+// specJSON holds the parameters and definitions of the spec.
+const specJSON = {{.Spec}}
+
+var (
+	spec Document
+	// routes are sorted so that literal segments win over parameters.
+	routes []Route
+)
+
+func init() {
+	if err := json.Unmarshal([]byte(specJSON), &spec); err != nil {
+		panic(err)
+	}
+	routes = spec.Routes()
+}
+
+// match returns the route of req and its path parameters.
+func match(req *http.Request) (*Route, map[string]string) {
+	for i := range routes {
+		r := &routes[i]
+		if r.Method != req.Method {
+			continue
+		}
+		if params, ok := r.Match(req.URL.Path); ok {
+			return r, params
+		}
+	}
+	return nil, nil
+}
+
+// Validate checks req against the spec of its route. Requests to
+// undocumented routes are not checked.
+func Validate(req *http.Request) []Error {
+	r, pathParams := match(req)
+	if r == nil {
+		return nil
+	}
+	return spec.ValidateRequest(r.Op, req, pathParams)
+}
+
+// WriteError writes the payload of an invalid request.
+func WriteError(w http.ResponseWriter, errs []Error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusBadRequest)
+	json.NewEncoder(w).Encode(Payload{Message: "invalid request", Errors: errs})
+}
+`
+
+const validationBeegoTpl = `// Code generated by bee generate validators. DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+	"github.com/astaxie/beego"
+	"github.com/astaxie/beego/context"
+)
+
+// InsertFilters registers the validation filter of every documented route.
+// Call it before beego.Run.
+func InsertFilters() {
+{{- range .Paths}}
+	beego.InsertFilter("{{.BeegoPath}}", beego.BeforeRouter, PathFilter("{{.Path}}"))
+{{- end}}
+}
+
+// PathFilter returns the filter validating the operations of a swagger
+// path. Requests that belong to a more specific route are left to the
+// filter of that route.
+func PathFilter(path string) beego.FilterFunc {
+	return func(ctx *context.Context) {
+		r, pathParams := match(ctx.Request)
+		if r == nil || r.Template != path {
+			return
+		}
+		if errs := spec.ValidateRequest(r.Op, ctx.Request, pathParams); len(errs) > 0 {
+			WriteError(ctx.ResponseWriter, errs)
+		}
+	}
+}
+
+// Filter validates the requests of every documented route, for
+// beego.InsertFilter("*", beego.BeforeRouter, Filter).
+func Filter(ctx *context.Context) {
+	if errs := Validate(ctx.Request); len(errs) > 0 {
+		WriteError(ctx.ResponseWriter, errs)
+	}
+}
+`
+
+const validationChiTpl = `// Code generated by bee generate validators. DO NOT EDIT.
+
+package {{.Package}}
+
+import "net/http"
+
+// Middleware rejects the requests that do not match the spec of their
+// route:
+//
+//	r := chi.NewRouter()
+//	r.Use({{.Package}}.Middleware)
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if errs := Validate(req); len(errs) > 0 {
+			WriteError(w, errs)
+			return
+		}
+		next.ServeHTTP(w, req)
+	})
+}
+`
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"encoding/json"
+	"go/parser"
+	"go/token"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zalora/bee/docgen"
+)
+
+func TestBeegoRoute(t *testing.T) {
+	tests := []struct {
+		path     string
+		expected string
+	}{
+		{"/v1/orders", "/v1/orders"},
+		{"/v1/orders/{id}", "/v1/orders/:id"},
+		{"/v1/orders/{id}/lines/{sku}", "/v1/orders/:id/lines/:sku"},
+		{"/v1/files/{splat}", "/v1/files/*"},
+		{"/v1/files/{path}.{ext}", "/v1/files/*.*"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			assert.Equal(t, tt.expected, beegoRoute(tt.path))
+		})
+	}
+}
+
+func TestValidatorsData(t *testing.T) {
+	data, err := validatorsData(newTestMockServer(t).spec, "internal/validation")
+	require.NoError(t, err)
+
+	assert.Equal(t, "validation", data.Package)
+	var paths []string
+	for _, r := range data.Routes {
+		paths = append(paths, r.Method+" "+r.Path)
+	}
+	// Literal routes come first so that /orders/files is not taken for
+	// /orders/{id}.
+	assert.Equal(t, []string{
+		"GET /v1/orders/files",
+		"GET /v1/orders/{id}",
+		"POST /v1/orders",
+	}, paths)
+	assert.Equal(t, "/v1/orders/:id", data.Routes[1].BeegoPath)
+
+	// The requests are validated against the parameters and definitions
+	// of the spec, without their documentation.
+	var doc map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(strings.Trim(data.Spec, "`")), &doc))
+	spec := &docgen.Spec{Document: doc}
+	require.Len(t, spec.Routes(), 3)
+	assert.Equal(t, "/v1/orders/files", spec.Routes()[0].Template)
+	assert.NotContains(t, data.Spec, "description")
+	assert.NotContains(t, data.Spec, "responses")
+}
+
+func TestStripDocFields(t *testing.T) {
+	schema := map[string]interface{}{
+		"title":       "Book",
+		"description": "a book",
+		"properties": map[string]interface{}{
+			"title": map[string]interface{}{"type": "string", "description": "its title", "example": "Dune"},
+		},
+	}
+	assert.Equal(t, map[string]interface{}{
+		"properties": map[string]interface{}{
+			"title": map[string]interface{}{"type": "string"},
+		},
+	}, stripDocFields(schema))
+}
+
+func TestValidatorsTemplates(t *testing.T) {
+	data, err := validatorsData(newTestMockServer(t).spec, "validation")
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	require.NoError(t, writeValidationSchema(filepath.Join(dir, "schema.go"), data.Package))
+	for name, tpl := range map[string]string{
+		"validation.go": validationTpl,
+		"filter.go":     validationBeegoTpl,
+		"middleware.go": validationChiTpl,
+	} {
+		fpath := filepath.Join(dir, name)
+		require.NoError(t, writeTemplate(fpath, tpl, data))
+		_, err := parser.ParseFile(token.NewFileSet(), fpath, nil, parser.AllErrors)
+		assert.NoError(t, err, name)
+	}
+
+	// Without the beego filter, the package builds with the standard
+	// library alone.
+	f, err := parser.ParseFile(token.NewFileSet(), filepath.Join(dir, "schema.go"), nil, parser.ImportsOnly)
+	require.NoError(t, err)
+	assert.Equal(t, "validation", f.Name.Name)
+	for _, imp := range f.Imports {
+		assert.NotContains(t, strings.SplitN(strings.Trim(imp.Path.Value, `"`), "/", 2)[0], ".", imp.Path.Value)
+	}
+	require.NoError(t, os.Remove(filepath.Join(dir, "filter.go")))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/validation\n\ngo 1.16\n"), 0644))
+	cmd := exec.Command("go", "vet", ".")
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GOFLAGS=-mod=mod", "GOPROXY=off")
+	out, err := cmd.CombinedOutput()
+	assert.NoError(t, err, string(out))
+}
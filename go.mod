@@ -6,8 +6,8 @@ replace github.com/astaxie/beego => github.com/zalora/beego v1.7.3
 
 require (
 	github.com/astaxie/beego v1.12.2
+	github.com/fsnotify/fsnotify v1.5.4
 	github.com/go-sql-driver/mysql v1.5.0
-	github.com/howeyc/fsnotify v0.9.0
 	github.com/lib/pq v1.7.0
 	github.com/rbretecher/go-postman-collection v0.9.0
 	github.com/smartystreets/goconvey v1.6.4
@@ -0,0 +1,194 @@
+// Copyright 2013 bee authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// healthCheck tells when the app started by bee run is ready.
+type healthCheck struct {
+	// URL answering with a 2xx or 3xx status once the app is ready.
+	URL string
+	// Port of localhost accepting TCP connections once the app is ready,
+	// when there is no URL.
+	Port int
+	// Time the app has to get ready, e.g. 1m. Defaults to 30s.
+	Timeout string
+}
+
+const healthInterval = 200 * time.Millisecond
+
+var (
+	errAppExited = errors.New("the app exited")
+	healthClient = &http.Client{Timeout: time.Second}
+)
+
+// appHealth returns the health check of the Beefile, or a connection to
+// the app behind the proxy.
+func appHealth() healthCheck {
+	if conf.Run.Health.enabled() || runProxy == nil {
+		return conf.Run.Health
+	}
+	h := healthCheck{Timeout: conf.Run.Health.Timeout}
+	h.Port, _ = strconv.Atoi(runProxy.target.Port())
+	return h
+}
+
+func (h healthCheck) enabled() bool {
+	return h.URL != "" || h.Port != 0
+}
+
+// timeout returns the time the app has to get ready, the default when it
+// is not valid.
+func (h healthCheck) timeout() (time.Duration, error) {
+	timeout := 30 * time.Second
+	if h.Timeout == "" {
+		return timeout, nil
+	}
+	d, err := time.ParseDuration(h.Timeout)
+	if err != nil {
+		return timeout, fmt.Errorf("invalid health timeout %q, using %s", h.Timeout, timeout)
+	}
+	return d, nil
+}
+
+// probe returns nil when the app is ready.
+func (h healthCheck) probe() error {
+	if h.URL != "" {
+		resp, err := healthClient.Get(h.URL)
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		if resp.StatusCode >= 400 {
+			return fmt.Errorf("%s answered %s", h.URL, resp.Status)
+		}
+		return nil
+	}
+
+	conn, err := net.DialTimeout("tcp", net.JoinHostPort("localhost", strconv.Itoa(h.Port)), time.Second)
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
+
+// wait probes the app until it is ready, timeout expires or exited is
+// closed.
+func (h healthCheck) wait(exited <-chan struct{}, timeout time.Duration) error {
+	deadline := time.After(timeout)
+	ticker := time.NewTicker(healthInterval)
+	defer ticker.Stop()
+	for {
+		err := h.probe()
+		if err == nil {
+			return nil
+		}
+		select {
+		case <-exited:
+			return errAppExited
+		case <-deadline:
+			return fmt.Errorf("not ready after %s: %s", timeout, err)
+		case <-ticker.C:
+		}
+	}
+}
+
+// checkHealth reports whether the app started with c is ready, with the
+// last lines of its stderr when it is not.
+func checkHealth(appname string, c *exec.Cmd, exited <-chan struct{}, stderr *tailWriter) {
+	h := appHealth()
+	timeout, err := h.timeout()
+	if err != nil {
+		ColorLog("[WARN] %s\n", err)
+	}
+	start := time.Now()
+	err = h.wait(exited, timeout)
+
+	state.Lock()
+	current := cmd == c
+	state.Unlock()
+	if !current {
+		// Restarted or stopped in the meantime.
+		return
+	}
+
+	if err == nil {
+		ColorLog("[SUCC] %s is ready in %s\n", appname, time.Since(start).Round(time.Millisecond))
+		runProxy.ready()
+		return
+	}
+	if err == errAppExited {
+		err = fmt.Errorf("%s", c.ProcessState)
+	}
+	ColorLog("[ERRO] %s failed to get ready: %s\n", appname, err)
+	lines := stderr.Lines()
+	runProxy.failed("The app failed to get ready", strings.Join(append([]string{err.Error(), ""}, lines...), "\n"))
+	if len(lines) > 0 {
+		ColorLog("[INFO] Last lines of stderr:\n")
+		for _, line := range lines {
+			fmt.Fprintln(os.Stderr, "\t"+line)
+		}
+	}
+}
+
+// tailWriter keeps the last lines written to it.
+type tailWriter struct {
+	max int
+
+	mu      sync.Mutex
+	lines   []string
+	partial string
+}
+
+func (w *tailWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	lines := strings.Split(w.partial+string(p), "\n")
+	w.partial = lines[len(lines)-1]
+	for _, line := range lines[:len(lines)-1] {
+		w.lines = append(w.lines, strings.TrimSuffix(line, "\r"))
+	}
+	if len(w.lines) > w.max {
+		w.lines = append([]string(nil), w.lines[len(w.lines)-w.max:]...)
+	}
+	return len(p), nil
+}
+
+// Lines returns the last lines written, with the unterminated one.
+func (w *tailWriter) Lines() []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	lines := append([]string(nil), w.lines...)
+	if w.partial != "" {
+		lines = append(lines, w.partial)
+	}
+	if len(lines) > w.max {
+		lines = lines[len(lines)-w.max:]
+	}
+	return lines
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHealthCheckWait(t *testing.T) {
+	ready := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-ready:
+		default:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer srv.Close()
+
+	h := healthCheck{URL: srv.URL + "/health"}
+	err := h.wait(nil, 300*time.Millisecond)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not ready after 300ms: "+srv.URL+"/health answered 503 Service Unavailable")
+
+	time.AfterFunc(100*time.Millisecond, func() { close(ready) })
+	assert.NoError(t, h.wait(nil, 5*time.Second))
+
+	l, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	port := l.Addr().(*net.TCPAddr).Port
+	assert.NoError(t, healthCheck{Port: port}.wait(nil, time.Second))
+	l.Close()
+
+	exited := make(chan struct{})
+	close(exited)
+	assert.Equal(t, errAppExited, healthCheck{Port: port}.wait(exited, 5*time.Second))
+}
+
+func TestHealthCheckTimeout(t *testing.T) {
+	timeout, err := healthCheck{}.timeout()
+	assert.NoError(t, err)
+	assert.Equal(t, 30*time.Second, timeout)
+
+	timeout, err = healthCheck{Timeout: "1m"}.timeout()
+	assert.NoError(t, err)
+	assert.Equal(t, time.Minute, timeout)
+
+	timeout, err = healthCheck{Timeout: "later"}.timeout()
+	assert.Error(t, err)
+	assert.Equal(t, 30*time.Second, timeout)
+}
+
+func TestTailWriter(t *testing.T) {
+	w := &tailWriter{max: 3}
+	assert.Empty(t, w.Lines())
+
+	for i := 1; i <= 4; i++ {
+		fmt.Fprintf(w, "line %d\n", i)
+	}
+	w.Write([]byte("panic: boom\r\ngoroutine 1"))
+	w.Write([]byte(" [running]"))
+	assert.Equal(t, []string{"line 4", "panic: boom", "goroutine 1 [running]"}, w.Lines())
+
+	w.Write([]byte(":\n" + strings.Repeat("x\n", 5)))
+	assert.Equal(t, []string{"x", "x", "x"}, w.Lines())
+}
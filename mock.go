@@ -0,0 +1,283 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/zalora/bee/docgen"
+)
+
+var cmdMock = &Command{
+	UsageLine: "mock [-spec=swagger/swagger.json] [-port=8090]",
+	Short:     "serve a mock of the API described by the generated spec",
+	Long: `
+Mock serves every path of the spec. Requests are validated against the
+declared parameters and body schemas, invalid ones get a 400 response.
+Responses are built from the @Success schema of the operation and the
+examples, defaults and enums of its definitions.
+
+Select another documented response, e.g. a @Failure, with the
+X-Mock-Status header or the __status query parameter:
+
+    curl -H 'X-Mock-Status: 404' http://localhost:8090/v1/orders/1
+
+The spec is reloaded when it changes, so mock can run next to
+'bee run -gendoc=true'.
+`,
+}
+
+var (
+	mockSpecFile docValue
+	mockPort     docValue
+)
+
+const (
+	mockStatusHeader = "X-Mock-Status"
+	mockStatusQuery  = "__status"
+)
+
+func init() {
+	cmdMock.Run = runMock
+	cmdMock.Flag.Var(&mockSpecFile, "spec", "spec file, default is swagger/swagger.json")
+	cmdMock.Flag.Var(&mockPort, "port", "mock server port, default is 8090")
+}
+
+func runMock(cmd *Command, args []string) int {
+	ShowShortVersionBanner()
+
+	if mockSpecFile == "" {
+		mockSpecFile = docValue(filepath.Join("swagger", "swagger.json"))
+	}
+	if mockPort == "" {
+		mockPort = "8090"
+	}
+
+	server := &mockServer{}
+	if err := server.load(string(mockSpecFile)); err != nil {
+		ColorLog("[ERRO] %s\n", err)
+		return 2
+	}
+	if err := server.watch(string(mockSpecFile)); err != nil {
+		ColorLog("[WARN] Spec changes will not be reloaded: %s\n", err)
+	}
+
+	ColorLog("[INFO] Mocking %s on http://127.0.0.1:%s\n", mockSpecFile, mockPort)
+	if err := http.ListenAndServe(":"+string(mockPort), server); err != nil {
+		ColorLog("[ERRO] %s\n", err)
+		return 2
+	}
+	return 0
+}
+
+// mockServer serves the operations of a spec.
+type mockServer struct {
+	mu     sync.RWMutex
+	spec   *docgen.Spec
+	routes []docgen.Route
+}
+
+// load reads the spec and replaces the served routes.
+func (m *mockServer) load(filename string) error {
+	spec, err := docgen.LoadSpec(filename)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	m.spec = spec
+	m.routes = spec.Routes()
+	m.mu.Unlock()
+	return nil
+}
+
+// watch reloads the spec whenever it is written.
+func (m *mockServer) watch(filename string) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	// Watch the directory, the generators recreate the file.
+	if err := watcher.Add(filepath.Dir(filename)); err != nil {
+		return err
+	}
+
+	go func() {
+		var reload <-chan time.Time
+		for {
+			select {
+			case e := <-watcher.Events:
+				if filepath.Clean(e.Name) == filepath.Clean(filename) && e.Op&fsnotify.Remove == 0 {
+					// Wait for the writes to settle.
+					reload = time.After(200 * time.Millisecond)
+				}
+			case <-reload:
+				if err := m.load(filename); err != nil {
+					ColorLog("[WARN] Keeping the previous spec: %s\n", err)
+					continue
+				}
+				ColorLog("[INFO] Reloaded %s\n", filename)
+			case err := <-watcher.Errors:
+				ColorLog("[WARN] %s\n", err)
+			}
+		}
+	}()
+	return nil
+}
+
+// match returns the route of r and its path parameters.
+func (m *mockServer) match(r *http.Request) (*docgen.Spec, *docgen.Route, map[string]string, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	pathFound := false
+	for i := range m.routes {
+		route := &m.routes[i]
+		params, ok := route.Match(r.URL.Path)
+		if !ok {
+			continue
+		}
+		pathFound = true
+		if route.Method != r.Method {
+			continue
+		}
+		return m.spec, route, params, true
+	}
+	return m.spec, nil, nil, pathFound
+}
+
+func (m *mockServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+
+	spec, route, params, pathFound := m.match(r)
+	if route == nil {
+		switch {
+		case pathFound && r.Method == http.MethodOptions:
+			w.Header().Set("Access-Control-Allow-Methods", "GET, PUT, POST, PATCH, DELETE, HEAD, OPTIONS")
+			w.Header().Set("Access-Control-Allow-Headers", "*")
+			w.WriteHeader(http.StatusNoContent)
+		case pathFound:
+			writeMockJSON(w, http.StatusMethodNotAllowed, mockError{Message: "method not allowed"})
+		default:
+			writeMockJSON(w, http.StatusNotFound, mockError{Message: "no operation for " + r.URL.Path})
+		}
+		ColorLog("[WARN] %s %s is not part of the spec\n", r.Method, r.URL.Path)
+		return
+	}
+
+	if errs := spec.ValidateRequest(route.Operation, r, params); len(errs) > 0 {
+		writeMockJSON(w, http.StatusBadRequest, mockError{Message: "invalid request", Errors: errs})
+		ColorLog("[WARN] %s %s: %d\n", r.Method, r.URL.Path, http.StatusBadRequest)
+		for _, err := range errs {
+			ColorLog("[WARN]   %s\n", err)
+		}
+		return
+	}
+
+	status := r.Header.Get(mockStatusHeader)
+	if status == "" {
+		status = r.URL.Query().Get(mockStatusQuery)
+	}
+	code := writeMockResponse(w, spec, route.Operation, status)
+	ColorLog("[INFO] %s %s: %d\n", r.Method, r.URL.Path, code)
+}
+
+// mockError is the payload of the errors of the mock server.
+type mockError struct {
+	Message string                   `json:"message"`
+	Errors  []docgen.ValidationError `json:"errors,omitempty"`
+}
+
+// writeMockResponse writes the response of op with the given status, or
+// the first success response when status is empty. It returns the status
+// code written.
+func writeMockResponse(w http.ResponseWriter, spec *docgen.Spec, op docgen.Operation, status string) int {
+	responses := op.Responses()
+	if status == "" {
+		status = defaultMockStatus(responses)
+	}
+
+	resp, ok := responses[status]
+	if !ok && status != "" && status != "200" {
+		writeMockJSON(w, http.StatusNotImplemented, mockError{
+			Message: fmt.Sprintf("response %s is not documented for %s", status, op.ID()),
+		})
+		return http.StatusNotImplemented
+	}
+
+	code := http.StatusOK
+	fmt.Sscan(status, &code)
+
+	contentType := mockContentType(spec.Produces(op))
+	var body interface{}
+	if examples, ok := resp["examples"].(map[string]interface{}); ok && examples[contentType] != nil {
+		body = examples[contentType]
+	} else if schema, ok := resp["schema"].(map[string]interface{}); ok {
+		body = spec.Example(schema)
+	} else if code >= 400 {
+		desc, _ := resp["description"].(string)
+		body = mockError{Message: desc}
+	}
+
+	if body == nil {
+		w.WriteHeader(code)
+		return code
+	}
+	w.Header().Set("Content-Type", contentType)
+	writeMockJSON(w, code, body)
+	return code
+}
+
+// defaultMockStatus returns the lowest documented 2xx status, then
+// "default".
+func defaultMockStatus(responses map[string]map[string]interface{}) string {
+	var codes []string
+	for code := range responses {
+		if strings.HasPrefix(code, "2") {
+			codes = append(codes, code)
+		}
+	}
+	sort.Strings(codes)
+	if len(codes) > 0 {
+		return codes[0]
+	}
+	if _, ok := responses["default"]; ok {
+		return "default"
+	}
+	return "200"
+}
+
+// mockContentType prefers JSON among the produced content types, the
+// mock bodies are always encoded as JSON.
+func mockContentType(produces []string) string {
+	for _, ct := range produces {
+		if ct == "application/json" {
+			return ct
+		}
+	}
+	for _, ct := range produces {
+		if strings.Contains(ct, "json") {
+			return ct
+		}
+	}
+	return "application/json"
+}
+
+func writeMockJSON(w http.ResponseWriter, code int, v interface{}) {
+	if w.Header().Get("Content-Type") == "" {
+		w.Header().Set("Content-Type", "application/json")
+	}
+	w.WriteHeader(code)
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+	}
+}
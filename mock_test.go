@@ -0,0 +1,174 @@
+package main
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const mockTestSpec = `{
+	"basePath": "/v1",
+	"paths": {
+		"/orders/{id}": {
+			"get": {
+				"operationId": "OrderController.Get",
+				"parameters": [
+					{"in": "path", "name": "id", "required": true, "type": "integer"},
+					{"in": "query", "name": "status", "type": "string", "enum": ["open", "closed"]}
+				],
+				"responses": {
+					"200": {"description": "models.Order", "schema": {"$ref": "#/definitions/models.Order"}},
+					"404": {"description": "order not found"}
+				}
+			}
+		},
+		"/orders/files": {
+			"get": {
+				"operationId": "OrderController.Files",
+				"responses": {"204": {"description": "no content"}}
+			}
+		},
+		"/orders": {
+			"post": {
+				"operationId": "OrderController.Post",
+				"parameters": [
+					{"in": "body", "name": "body", "required": true, "schema": {"$ref": "#/definitions/models.Order"}}
+				],
+				"responses": {"201": {"description": "models.Order", "schema": {"$ref": "#/definitions/models.Order"}}}
+			}
+		}
+	},
+	"definitions": {
+		"models.Order": {
+			"type": "object",
+			"required": ["id"],
+			"properties": {
+				"id": {"type": "integer", "example": "42"},
+				"status": {"type": "string", "enum": ["open", "closed"]}
+			}
+		}
+	}
+}`
+
+func newTestMockServer(t *testing.T) *mockServer {
+	filename := filepath.Join(t.TempDir(), "swagger.json")
+	require.NoError(t, os.WriteFile(filename, []byte(mockTestSpec), 0644))
+	server := &mockServer{}
+	require.NoError(t, server.load(filename))
+	return server
+}
+
+func TestMockServer(t *testing.T) {
+	server := newTestMockServer(t)
+
+	tests := []struct {
+		desc         string
+		method       string
+		url          string
+		header       http.Header
+		body         string
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			desc:         "success response from the schema examples",
+			method:       http.MethodGet,
+			url:          "/v1/orders/1",
+			expectedCode: http.StatusOK,
+			expectedBody: `{"id":42,"status":"open"}`,
+		},
+		{
+			desc:         "literal path wins over parameters",
+			method:       http.MethodGet,
+			url:          "/v1/orders/files",
+			expectedCode: http.StatusNoContent,
+		},
+		{
+			desc:         "failure selected by header",
+			method:       http.MethodGet,
+			url:          "/v1/orders/1",
+			header:       http.Header{mockStatusHeader: []string{"404"}},
+			expectedCode: http.StatusNotFound,
+			expectedBody: `{"message":"order not found"}`,
+		},
+		{
+			desc:         "failure selected by query",
+			method:       http.MethodGet,
+			url:          "/v1/orders/1?__status=404",
+			expectedCode: http.StatusNotFound,
+			expectedBody: `{"message":"order not found"}`,
+		},
+		{
+			desc:         "undocumented status",
+			method:       http.MethodGet,
+			url:          "/v1/orders/1?__status=500",
+			expectedCode: http.StatusNotImplemented,
+			expectedBody: `{"message":"response 500 is not documented for OrderController.Get"}`,
+		},
+		{
+			desc:         "invalid parameters",
+			method:       http.MethodGet,
+			url:          "/v1/orders/abc?status=lost",
+			expectedCode: http.StatusBadRequest,
+			expectedBody: `{"message":"invalid request","errors":[` +
+				`{"field":"path.id","message":"must be an integer"},` +
+				`{"field":"query.status","message":"must be one of [open, closed]"}]}`,
+		},
+		{
+			desc:         "valid body",
+			method:       http.MethodPost,
+			url:          "/v1/orders",
+			body:         `{"id": 1}`,
+			expectedCode: http.StatusCreated,
+			expectedBody: `{"id":42,"status":"open"}`,
+		},
+		{
+			desc:         "invalid body",
+			method:       http.MethodPost,
+			url:          "/v1/orders",
+			body:         `{"status": "open"}`,
+			expectedCode: http.StatusBadRequest,
+			expectedBody: `{"message":"invalid request","errors":[{"field":"body.id","message":"is required"}]}`,
+		},
+		{
+			desc:         "unknown method",
+			method:       http.MethodDelete,
+			url:          "/v1/orders/1",
+			expectedCode: http.StatusMethodNotAllowed,
+			expectedBody: `{"message":"method not allowed"}`,
+		},
+		{
+			desc:         "unknown path",
+			method:       http.MethodGet,
+			url:          "/v2/orders",
+			expectedCode: http.StatusNotFound,
+			expectedBody: `{"message":"no operation for /v2/orders"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			r := httptest.NewRequest(tt.method, tt.url, strings.NewReader(tt.body))
+			for k, v := range tt.header {
+				r.Header[k] = v
+			}
+			w := httptest.NewRecorder()
+			server.ServeHTTP(w, r)
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+			body, _ := ioutil.ReadAll(w.Body)
+			if tt.expectedBody == "" {
+				assert.Empty(t, body)
+			} else {
+				assert.JSONEq(t, tt.expectedBody, string(body))
+			}
+		})
+	}
+}
@@ -1,167 +1,663 @@
 package main
 
 import (
+	"bytes"
+	"crypto/sha1"
 	"encoding/json"
+	"fmt"
+	"net/url"
 	"os"
 	"path"
+	"regexp"
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/astaxie/beego/swagger"
 	"github.com/rbretecher/go-postman-collection"
+	"github.com/zalora/bee/docgen"
 )
 
-var (
-	description = `# DORAEMON POSTMAN COLLECTION\n## Usage\nPut ` + "`{{DOR_BASE_URL}}`" + `as environment. For more context, refer to: https://learning.postman.com/docs/sending-requests/variables/.`
+// postmanOptions configures the collection written by bee generate postman.
+type postmanOptions struct {
+	// Name of the collection, the title of the spec by default.
+	Name        string
+	Description string
+	// Prefix of the collection variables, e.g. DOR for {{DOR_BASE_URL}}.
+	Prefix string
+	// Headers sent with every request. Variables in their values are
+	// written without the prefix: {{CONTENT_LANGUAGE}}.
+	Headers []postmanHeader
+	// Folders listed first, in this order. The others follow by name.
+	Folders []string
+	// FolderBy is how requests are put in folders: tag (the default),
+	// path for nested folders of the path segments, or controller.
+	FolderBy string `json:"folder_by" yaml:"folder_by"`
+	// MultiTag is where operations with several tags go when the folders
+	// are by tag: first (the default) or all of their folders.
+	MultiTag string `json:"multi_tag" yaml:"multi_tag"`
+	// Environments written to swagger/<name>.postman_environment.json.
+	Environments []postmanEnvironment
+	// CodeSamples appends cURL, Go and JavaScript snippets to the
+	// descriptions of the requests.
+	CodeSamples bool `json:"code_samples" yaml:"code_samples"`
+}
 
-	baseHeaders = []*postman.Header{
-		{
-			Key:   "Accept",
-			Value: "application/json",
-		},
-		{
-			Key:   "Content-Language",
-			Value: "{{DOR_CONTENT_LANGUAGE}}",
-		},
-		{
-			Key:   "User-Agent",
-			Value: "{{DOR_USER_AGENT}}",
-		},
+type postmanHeader struct {
+	Key   string
+	Value string
+}
+
+type postmanEnvironment struct {
+	Name    string
+	BaseURL string `json:"base_url" yaml:"base_url"`
+	// Values of the other variables, keyed by their name without the
+	// prefix.
+	Variables map[string]string
+}
+
+// postmanEnvironmentFile is the Postman environment format.
+type postmanEnvironmentFile struct {
+	ID     string                    `json:"id"`
+	Name   string                    `json:"name"`
+	Values []postmanEnvironmentValue `json:"values"`
+	Scope  string                    `json:"_postman_variable_scope"`
+}
+
+type postmanEnvironmentValue struct {
+	Key     string `json:"key"`
+	Value   string `json:"value"`
+	Type    string `json:"type"`
+	Enabled bool   `json:"enabled"`
+}
+
+// postmanTestMarker starts the generated test scripts, which are replaced
+// when the collection is synced.
+const postmanTestMarker = "// Generated by bee generate postman, edits are overwritten."
+
+var postmanVariableRegexp = regexp.MustCompile(`\{\{([A-Za-z_][A-Za-z0-9_]*)\}\}`)
+
+// variable returns the name of the collection variable name.
+func (o postmanOptions) variable(name string) string {
+	if o.Prefix == "" {
+		return name
 	}
-)
+	return o.Prefix + "_" + name
+}
 
-func generatePostman(curpath string) error {
-	fd, err := os.ReadFile(path.Join(curpath, "swagger", "swagger.json"))
-	if err != nil {
+// headers returns the base headers with their variables prefixed.
+func (o postmanOptions) headers() []*postman.Header {
+	if len(o.Headers) == 0 {
+		return []*postman.Header{{Key: "Accept", Value: "application/json"}}
+	}
+
+	headers := make([]*postman.Header, 0, len(o.Headers))
+	for _, h := range o.Headers {
+		headers = append(headers, &postman.Header{
+			Key:   h.Key,
+			Value: postmanVariableRegexp.ReplaceAllString(h.Value, "{{"+o.variable("$1")+"}}"),
+		})
+	}
+	return headers
+}
+
+func (o postmanOptions) description(name string) string {
+	if o.Description != "" {
+		return o.Description
+	}
+	return "# " + name + "\n\n## Usage\n\nPut `{{" + o.variable("BASE_URL") + "}}` as environment. " +
+		"For more context, refer to: https://learning.postman.com/docs/sending-requests/variables/."
+}
+
+// versionSegmentRegexp matches the API versions in paths, which are left out
+// of the folders by path.
+var versionSegmentRegexp = regexp.MustCompile(`^v[0-9]+$`)
+
+// folders returns the folder paths of op, nil for the root. The op path is
+// relative to the base path.
+func (o postmanOptions) folders(op docgen.Operation) [][]string {
+	switch o.FolderBy {
+	case "path":
+		var folder []string
+		for _, segment := range strings.Split(op.Path, "/") {
+			if segment == "" || strings.Contains(segment, "{") || versionSegmentRegexp.MatchString(segment) {
+				continue
+			}
+			folder = append(folder, segment)
+		}
+		return [][]string{folder}
+	case "controller":
+		id, _ := op.Op["operationId"].(string)
+		if i := strings.LastIndex(id, "."); i > 0 {
+			return [][]string{{id[:i]}}
+		}
+		return [][]string{nil}
+	}
+
+	tags := op.Tags()
+	if len(tags) == 0 {
+		return [][]string{nil}
+	}
+	if o.MultiTag != "all" {
+		tags = tags[:1]
+	}
+	folders := make([][]string, 0, len(tags))
+	for _, tag := range tags {
+		folders = append(folders, []string{tag})
+	}
+	return folders
+}
+
+// pathLess orders folder paths with folderLess segment by segment, parents
+// before their children.
+func (o postmanOptions) pathLess(a, b []string) bool {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] != b[i] {
+			return o.folderLess(a[i], b[i])
+		}
+	}
+	return len(a) < len(b)
+}
+
+// folderLess orders the folders listed in the options first.
+func (o postmanOptions) folderLess(a, b string) bool {
+	ra, rb := len(o.Folders), len(o.Folders)
+	for i, f := range o.Folders {
+		if strings.EqualFold(f, a) && ra == len(o.Folders) {
+			ra = i
+		}
+		if strings.EqualFold(f, b) && rb == len(o.Folders) {
+			rb = i
+		}
+	}
+	if ra != rb {
+		return ra < rb
+	}
+	return a < b
+}
+
+// writePostman writes the collection of spec and its environments to dir.
+// With sync, an existing collection is updated instead of replaced.
+func writePostman(spec *docgen.Spec, dir string, opts postmanOptions, sync bool) error {
+	p := postmanCollection(spec, opts)
+	// Write ignores the marshalling errors.
+	if _, err := json.Marshal(p); err != nil {
 		return err
 	}
 
-	var sAPIs swagger.Swagger
-	err = json.Unmarshal(fd, &sAPIs)
-	if err != nil {
+	var buf bytes.Buffer
+	if err := p.Write(&buf, postman.V210); err != nil {
+		return err
+	}
+	fpath := path.Join(dir, "postman-collection.json")
+	data := buf.Bytes()
+	if sync {
+		var err error
+		if data, err = syncPostmanFile(fpath, data); err != nil {
+			return err
+		}
+	}
+	if err := os.WriteFile(fpath, data, 0644); err != nil {
 		return err
 	}
 
-	p := postman.CreateCollection(sAPIs.Infos.Title, description)
+	for _, env := range opts.Environments {
+		b, err := json.MarshalIndent(postmanEnvironmentData(p.Info.Name, env, opts), "", "    ")
+		if err != nil {
+			return err
+		}
+		fpath := path.Join(dir, env.Name+".postman_environment.json")
+		if err := os.WriteFile(fpath, b, 0644); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// syncPostmanFile merges the generated collection into the collection in
+// fpath, if there is one.
+func syncPostmanFile(fpath string, generated []byte) ([]byte, error) {
+	b, err := os.ReadFile(fpath)
+	if os.IsNotExist(err) {
+		return generated, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var existing, gen map[string]interface{}
+	if err := json.Unmarshal(b, &existing); err != nil {
+		return nil, fmt.Errorf("could not parse %s: %v", fpath, err)
+	}
+	if err := json.Unmarshal(generated, &gen); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "    ")
+	if err := enc.Encode(postmanSync(existing, gen)); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// postmanCollection builds the collection of the spec. Operations are put in
+// the folder of their first tag, or at the root when they have none.
+func postmanCollection(spec *docgen.Spec, opts postmanOptions) *postman.Collection {
+	sAPIs := spec.Swagger
+	name := opts.Name
+	if name == "" {
+		name = sAPIs.Infos.Title
+	}
+	p := postman.CreateCollection(name, opts.description(name))
+	// The library writes a description without a type as a raw string, so
+	// the markdown would need to be JSON-escaped by hand.
+	p.Info.Description.Type = "text/markdown"
 	collection := make(map[string]*postman.Items)
+	headers := opts.headers()
+	host := "{{" + opts.variable("BASE_URL") + "}}"
 
-	for sURL, sItem := range sAPIs.Paths {
-		sURL = sAPIs.BasePath + sURL
+	routes := make([]string, 0, len(sAPIs.Paths))
+	for rt := range sAPIs.Paths {
+		routes = append(routes, rt)
+	}
+	sort.Strings(routes)
+
+	for _, rt := range routes {
+		sItem := sAPIs.Paths[rt]
+		sURL := sAPIs.BasePath + rt
 
 		// Postman uses `:pathVariable` instead of swagger's `{pathVariable}`
 		// format.
 		sURL = strings.ReplaceAll(sURL, "{", ":")
 		sURL = strings.ReplaceAll(sURL, "}", "")
 
-		if get := sItem.Get; get != nil {
-			c := upsertNewCollection(p, collection, get.Tags[0])
-			addItemToCollection(sURL, c, get, postman.Get)
+		for _, m := range []struct {
+			op     *swagger.Operation
+			method postman.Method
+		}{
+			{sItem.Get, postman.Get},
+			{sItem.Put, postman.Put},
+			{sItem.Post, postman.Post},
+			{sItem.Delete, postman.Delete},
+			{sItem.Options, postman.Options},
+			{sItem.Head, postman.Head},
+			{sItem.Patch, postman.Patch},
+		} {
+			if m.op == nil {
+				continue
+			}
+			rawOp := docgen.Operation{
+				Method: string(m.method),
+				Path:   rt,
+				Op:     rawOperation(spec.Document, rt, strings.ToLower(string(m.method))),
+			}
+			item := postmanItem(spec, sURL, host, headers, m.op, rawOp, m.method)
+			if opts.CodeSamples {
+				item.Description = strings.TrimSpace(item.Description + "\n\n" + codeSamplesMarkdown(spec, rawOp, opts))
+			}
+			for i, folder := range opts.folders(rawOp) {
+				placed := item
+				if i > 0 {
+					// Copies in the other folders of the operation need
+					// their own ID.
+					c := *item
+					c.ID = postmanID(item.ID, strings.Join(folder, "/"))
+					placed = &c
+				}
+				if len(folder) == 0 {
+					p.AddItem(placed)
+					continue
+				}
+				upsertNewCollection(p, collection, folder).AddItem(placed)
+			}
 		}
+	}
 
-		if put := sItem.Put; put != nil {
-			c := upsertNewCollection(p, collection, put.Tags[0])
-			addItemToCollection(sURL, c, put, postman.Put)
-		}
+	p.Items = sortPostmanItems(p.Items, opts)
+	return p
+}
 
-		if post := sItem.Post; post != nil {
-			c := upsertNewCollection(p, collection, post.Tags[0])
-			addItemToCollection(sURL, c, post, postman.Post)
+// sortPostmanItems puts the folders first, then the requests.
+func sortPostmanItems(items []*postman.Items, opts postmanOptions) []*postman.Items {
+	sort.SliceStable(items, func(i, j int) bool {
+		a, b := items[i], items[j]
+		if a.IsGroup() != b.IsGroup() {
+			return a.IsGroup()
 		}
-
-		if del := sItem.Delete; del != nil {
-			c := upsertNewCollection(p, collection, del.Tags[0])
-			addItemToCollection(sURL, c, del, postman.Delete)
+		if a.IsGroup() {
+			return opts.folderLess(a.Name, b.Name)
 		}
-
-		if options := sItem.Options; options != nil {
-			c := upsertNewCollection(p, collection, options.Tags[0])
-			addItemToCollection(sURL, c, options, postman.Options)
+		return false
+	})
+	for _, item := range items {
+		if item.IsGroup() {
+			item.Items = sortPostmanItems(item.Items, opts)
 		}
+	}
+	return items
+}
 
-		if head := sItem.Head; head != nil {
-			c := upsertNewCollection(p, collection, head.Tags[0])
-			addItemToCollection(sURL, c, head, postman.Head)
-		}
+// postmanEnvironmentData returns the Postman environment env of the
+// collection name.
+func postmanEnvironmentData(name string, env postmanEnvironment, opts postmanOptions) postmanEnvironmentFile {
+	data := postmanEnvironmentFile{
+		ID:    postmanID(name, env.Name),
+		Name:  name + " (" + env.Name + ")",
+		Scope: "environment",
+		Values: []postmanEnvironmentValue{{
+			Key:     opts.variable("BASE_URL"),
+			Value:   env.BaseURL,
+			Type:    "default",
+			Enabled: true,
+		}},
+	}
 
-		if patch := sItem.Patch; patch != nil {
-			c := upsertNewCollection(p, collection, patch.Tags[0])
-			addItemToCollection(sURL, c, patch, postman.Patch)
+	keys := make([]string, 0, len(env.Variables))
+	for k := range env.Variables {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		data.Values = append(data.Values, postmanEnvironmentValue{
+			Key:     opts.variable(k),
+			Value:   env.Variables[k],
+			Type:    "default",
+			Enabled: true,
+		})
+	}
+	return data
+}
+
+// postmanID returns a UUID derived from parts, so that Postman recognises
+// the same object across generations.
+func postmanID(parts ...string) string {
+	h := sha1.Sum([]byte(strings.Join(parts, "\x00")))
+	h[6] = h[6]&0x0f | 0x50
+	h[8] = h[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", h[0:4], h[4:6], h[6:8], h[8:10], h[10:16])
+}
+
+// upsertNewCollection returns the folder with the given path, creating it
+// and its parents when missing.
+func upsertNewCollection(p *postman.Collection, collection map[string]*postman.Items, folder []string) *postman.Items {
+	key := strings.Join(folder, "/")
+	if c, ok := collection[key]; ok {
+		return c
+	}
+
+	if len(folder) == 1 {
+		collection[key] = p.AddItemGroup(folder[0])
+	} else {
+		parent := upsertNewCollection(p, collection, folder[:len(folder)-1])
+		collection[key] = parent.AddItemGroup(folder[len(folder)-1])
+	}
+	return collection[key]
+}
+
+// rawOperation returns the generic form of the operation of path rt.
+func rawOperation(doc map[string]interface{}, rt, method string) map[string]interface{} {
+	paths, _ := doc["paths"].(map[string]interface{})
+	item, _ := paths[rt].(map[string]interface{})
+	op, _ := item[method].(map[string]interface{})
+	return op
+}
+
+// postmanTestEvent returns the test script of the operation: it checks that
+// the status code is documented and validates JSON bodies against the schema
+// of their response with the ajv bundled in Postman. Operations with a
+// default response accept any status.
+func postmanTestEvent(spec *docgen.Spec, op docgen.Operation) *postman.Event {
+	responses := op.Responses()
+	var codes []int
+	schemas := make(map[string]interface{})
+	for status, response := range responses {
+		code, err := strconv.Atoi(status)
+		if err != nil {
+			continue
 		}
+		codes = append(codes, code)
+		if schema, ok := response["schema"].(map[string]interface{}); ok {
+			schemas[status] = postmanSchema(spec, schema)
+		}
+	}
+	sort.Ints(codes)
+	if len(codes) == 0 {
+		return nil
+	}
+
+	var script []string
+	if _, ok := responses["default"]; !ok {
+		list, _ := json.Marshal(codes)
+		script = append(script,
+			`pm.test("Status code is documented", function () {`,
+			`    pm.expect(pm.response.code).to.be.oneOf(`+string(list)+`);`,
+			`});`,
+		)
 	}
+	if len(schemas) > 0 {
+		b, _ := json.Marshal(schemas)
+		script = append(script,
+			`var schemas = `+string(b)+`;`,
+			`var schema = schemas[pm.response.code];`,
+			`if (schema && /json/.test(pm.response.headers.get("Content-Type") || "")) {`,
+			`    pm.test("Body matches the documented schema", function () {`,
+			`        var Ajv = require("ajv");`,
+			`        var ajv = new Ajv({unknownFormats: "ignore"});`,
+			`        pm.expect(ajv.validate(schema, pm.response.json()), ajv.errorsText()).to.be.true;`,
+			`    });`,
+			`}`,
+		)
+	}
+	if len(script) == 0 {
+		return nil
+	}
+	return postman.CreateEvent(postman.Test, append([]string{postmanTestMarker}, script...))
+}
 
-	sort.Slice(p.Items, func(i, j int) bool {
-		if strings.EqualFold(p.Items[i].Name, "customers") {
-			return true
+// postmanSchema returns schema as a standalone JSON Schema with the
+// definitions it references. Optional properties also accept null, which
+// encoding/json writes for nil pointers, maps and slices.
+func postmanSchema(spec *docgen.Spec, schema map[string]interface{}) map[string]interface{} {
+	definitions := make(map[string]interface{})
+	var convert func(v interface{}) interface{}
+	convert = func(v interface{}) interface{} {
+		switch v := v.(type) {
+		case map[string]interface{}:
+			if name := docgen.RefName(v); name != "" {
+				if _, ok := definitions[name]; !ok {
+					// Reserve the name first, definitions can be recursive.
+					definitions[name] = nil
+					if definition := spec.Definition(name); definition != nil {
+						definitions[name] = convert(definition)
+					} else {
+						delete(definitions, name)
+					}
+				}
+				return v
+			}
+			out := make(map[string]interface{}, len(v))
+			for k, item := range v {
+				out[k] = convert(item)
+			}
+			props, _ := out["properties"].(map[string]interface{})
+			required := make(map[string]bool)
+			if list, ok := v["required"].([]interface{}); ok {
+				for _, name := range list {
+					if name, ok := name.(string); ok {
+						required[name] = true
+					}
+				}
+			}
+			for name, prop := range props {
+				if !required[name] {
+					props[name] = map[string]interface{}{
+						"anyOf": []interface{}{prop, map[string]interface{}{"type": "null"}},
+					}
+				}
+			}
+			return out
+		case []interface{}:
+			out := make([]interface{}, len(v))
+			for i, item := range v {
+				out[i] = convert(item)
+			}
+			return out
 		}
+		return v
+	}
 
-		return p.Items[i].Name < p.Items[j].Name
-	})
+	out, _ := convert(schema).(map[string]interface{})
+	if len(definitions) > 0 {
+		out["definitions"] = definitions
+	}
+	return out
+}
 
-	if _, err = json.Marshal(p); err != nil {
-		return err
+// postmanRawURL returns the URL as written in the Postman address bar. The
+// query parameters are escaped, as Postman keeps them in its params table.
+func postmanRawURL(base string, query []*postman.QueryParam) string {
+	for i, q := range query {
+		sep := "&"
+		if i == 0 {
+			sep = "?"
+		}
+		base += sep + q.Key + "=" + q.Value
 	}
+	return base
+}
 
-	pd, err := os.Create(path.Join(curpath, "swagger", "postman-collection.json"))
-	if err != nil {
-		return err
+// postmanContentType returns the first content type of consumes containing
+// kind, the first one when none does, or fallback when consumes is empty.
+func postmanContentType(consumes []string, kind, fallback string) string {
+	for _, c := range consumes {
+		if strings.Contains(c, kind) {
+			return c
+		}
 	}
-	defer pd.Close()
-	err = p.Write(pd, postman.V210)
-	if err != nil {
-		return err
+	if len(consumes) > 0 {
+		return consumes[0]
 	}
+	return fallback
+}
 
-	return nil
+// postmanValue formats a sample value of a path, query or form parameter.
+func postmanValue(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case []interface{}:
+		values := make([]string, 0, len(v))
+		for _, item := range v {
+			values = append(values, postmanValue(item))
+		}
+		return strings.Join(values, ",")
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return fmt.Sprint(v)
 }
 
-func upsertNewCollection(p *postman.Collection, collection map[string]*postman.Items, s string) *postman.Items {
-	if c, ok := collection[s]; ok {
-		return c
+// deprecationNote describes the deprecation lifecycle of an operation in
+// the generic form of the document.
+func deprecationNote(rawOp map[string]interface{}) string {
+	if deprecated, _ := rawOp["deprecated"].(bool); !deprecated {
+		return ""
 	}
 
-	collection[s] = p.AddItemGroup(s)
-	return collection[s]
+	note := "**Deprecated**"
+	if since, ok := rawOp["x-deprecated-since"].(string); ok {
+		note += " since " + since
+	}
+	note += "."
+	if sunset, ok := rawOp["x-sunset"].(string); ok {
+		if docgen.PastSunset(sunset, time.Now()) {
+			note += " It was due for removal on " + sunset + "."
+		} else {
+			note += " It will be removed on " + sunset + "."
+		}
+	}
+	if replacement, ok := rawOp["x-replaced-by"].(string); ok {
+		note += " Use `" + replacement + "` instead."
+	}
+	return note
 }
 
-func addItemToCollection(url string, collection *postman.Items, op *swagger.Operation, method postman.Method) {
-	var headers []*postman.Header
+// postmanItem returns the request of the operation op. Path variables,
+// query parameters and bodies are filled with sample values of their
+// schema.
+func postmanItem(spec *docgen.Spec, sURL, host string, baseHeaders []*postman.Header, op *swagger.Operation, rawOp docgen.Operation, method postman.Method) *postman.Items {
+	headers := append([]*postman.Header{}, baseHeaders...)
 	var variables []*postman.Variable
 	var queryParams []*postman.QueryParam
 	var body *postman.Body
 	var formData []*postman.Variable
+	hasFile := false
 	for _, param := range op.Parameters {
+		schema := rawOp.Parameter(param.In, param.Name)
 		switch param.In {
 		case "path":
 			variables = append(variables, &postman.Variable{
 				ID:          param.Name,
+				Key:         param.Name,
 				Type:        param.Type,
 				Name:        param.Name,
+				Value:       postmanValue(spec.Example(schema)),
 				Description: param.Description,
 			})
 		case "formData":
-			formData = append(formData, &postman.Variable{
+			v := &postman.Variable{
 				Key:         param.Name,
-				Type:        param.Type,
+				Type:        "text",
+				Value:       postmanValue(spec.Example(schema)),
 				Description: param.Description,
-			})
+			}
+			if param.Type == "file" {
+				v.Type = "file"
+				v.Value = ""
+				hasFile = true
+			}
+			formData = append(formData, v)
 		case "query":
 			description := param.Description
 			queryParams = append(queryParams, &postman.QueryParam{
-				Key:         param.Name,
+				Key:         url.QueryEscape(param.Name),
+				Value:       url.QueryEscape(postmanValue(spec.Example(schema))),
 				Description: &description,
 			})
+		case "body":
+			bodySchema, _ := schema["schema"].(map[string]interface{})
+			raw, _ := json.MarshalIndent(spec.Example(bodySchema), "", "    ")
+			body = &postman.Body{
+				Mode:    "raw",
+				Raw:     string(raw),
+				Options: &postman.BodyOptions{Raw: postman.BodyOptionsRaw{Language: postman.JSON}},
+			}
+			headers = append(headers, &postman.Header{
+				Key:   "Content-Type",
+				Value: postmanContentType(spec.Consumes(rawOp), "json", "application/json"),
+			})
 		}
 	}
 
 	if len(formData) > 0 {
-		body = &postman.Body{
-			Mode: "formdata",
+		contentType := postmanContentType(spec.Consumes(rawOp), "form", "multipart/form-data")
+		if hasFile {
+			contentType = "multipart/form-data"
+		}
+		body = &postman.Body{Mode: "formdata", FormData: formData}
+		if contentType == "application/x-www-form-urlencoded" {
+			body = &postman.Body{Mode: "urlencoded", URLEncoded: formData}
 		}
-		body.FormData = formData
 		headers = append(headers, &postman.Header{
 			Key:   "Content-Type",
-			Value: "multipart/form-data",
+			Value: contentType,
 		})
 	}
 
@@ -175,21 +671,43 @@ func addItemToCollection(url string, collection *postman.Items, op *swagger.Oper
 		})
 	}
 
-	collection.AddItem(postman.CreateItem(postman.Item{
-		Name:        string(method) + " " + url,
-		Description: op.Description,
-		ID:          op.OperationID,
+	name := string(method) + " " + sURL
+	description := op.Description
+	if note := deprecationNote(rawOp.Op); note != "" {
+		name = "[DEPRECATED] " + name
+		if description != "" {
+			description += "\n\n"
+		}
+		description += note
+	}
+
+	var events []*postman.Event
+	if test := postmanTestEvent(spec, rawOp); test != nil {
+		events = append(events, test)
+	}
+
+	id := op.OperationID
+	if id == "" {
+		id = postmanID(string(method), sURL)
+	}
+
+	return postman.CreateItem(postman.Item{
+		Name:        name,
+		Description: description,
+		Events:      events,
+		ID:          id,
 		Request: &postman.Request{
 			URL: &postman.URL{
-				Host:      []string{"{{DOR_BASE_URL}}"},
-				Path:      strings.Split(url, "/"),
+				Raw:       postmanRawURL(host+sURL, queryParams),
+				Host:      []string{host},
+				Path:      strings.Split(strings.TrimPrefix(sURL, "/"), "/"),
 				Query:     queryParams,
 				Variables: variables,
 			},
 			Method: method,
-			Header: append(baseHeaders, headers...),
+			Header: headers,
 			Body:   body,
 		},
 		Responses: responses,
-	}))
+	})
 }
@@ -0,0 +1,401 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+)
+
+// postmanArchive is the folder the requests of removed operations are moved
+// to when a collection is synced.
+const postmanArchive = "Archived"
+
+// postmanGeneratedName matches the names of generated requests. Unmatched
+// requests with such a name belong to removed operations, the others were
+// added in Postman.
+var postmanGeneratedName = regexp.MustCompile(`^(\[DEPRECATED\] )?(GET|PUT|POST|DELETE|OPTIONS|HEAD|PATCH) /`)
+
+// postmanEntry is a request of the existing collection.
+type postmanEntry struct {
+	item   map[string]interface{}
+	folder []string
+	used   bool
+}
+
+// postmanSync merges a generated collection into the existing one, both in
+// their generic form. Requests are matched by ID, which is the operationId,
+// or by method and path. Matched requests get the name, description, URL,
+// headers and test script of the generated one and keep the other fields,
+// such as their saved responses, auth and scripts. Requests of removed
+// operations are moved to the Archived folder and the requests added in
+// Postman stay in their folder.
+func postmanSync(existing, generated map[string]interface{}) map[string]interface{} {
+	s := &postmanSyncer{
+		byID:    make(map[string]*postmanEntry),
+		byRoute: make(map[string]*postmanEntry),
+		folders: make(map[string]map[string]interface{}),
+	}
+	s.collect(postmanChildren(existing), nil)
+
+	out := copyMap(existing)
+	info := copyMap(mapValue(existing["info"]))
+	for k, v := range mapValue(generated["info"]) {
+		info[k] = v
+	}
+	out["info"] = info
+	items := s.merge(postmanChildren(generated), nil)
+
+	archive := s.folder(postmanArchive)
+	for _, e := range s.entries {
+		if e.used {
+			continue
+		}
+		name, _ := e.item["name"].(string)
+		if postmanGeneratedName.MatchString(name) || (len(e.folder) > 0 && e.folder[0] == postmanArchive) {
+			archive["item"] = append(postmanChildren(archive), e.item)
+			continue
+		}
+		items = s.place(items, e.folder, e.item)
+	}
+	if len(postmanChildren(archive)) > 0 {
+		items = append(items, archive)
+	}
+
+	out["item"] = items
+	return out
+}
+
+type postmanSyncer struct {
+	entries []*postmanEntry
+	byID    map[string]*postmanEntry
+	byRoute map[string]*postmanEntry
+	// folders of the existing collection by their path.
+	folders map[string]map[string]interface{}
+}
+
+func (s *postmanSyncer) collect(items []interface{}, folder []string) {
+	for _, v := range items {
+		item := mapValue(v)
+		name, _ := item["name"].(string)
+		if _, ok := item["item"]; ok {
+			path := append(append([]string{}, folder...), name)
+			s.folders[strings.Join(path, "/")] = item
+			s.collect(postmanChildren(item), path)
+			continue
+		}
+
+		e := &postmanEntry{item: item, folder: folder}
+		s.entries = append(s.entries, e)
+		if id, _ := item["id"].(string); id != "" && s.byID[id] == nil {
+			s.byID[id] = e
+		}
+		if route := postmanRoute(item); route != "" && s.byRoute[route] == nil {
+			s.byRoute[route] = e
+		}
+	}
+}
+
+// merge returns the generated items merged with their existing
+// counterparts.
+func (s *postmanSyncer) merge(items []interface{}, folder []string) []interface{} {
+	out := make([]interface{}, 0, len(items))
+	for _, v := range items {
+		item := mapValue(v)
+		name, _ := item["name"].(string)
+		if _, ok := item["item"]; ok {
+			path := append(append([]string{}, folder...), name)
+			merged := s.folder(path...)
+			merged["item"] = s.merge(postmanChildren(item), path)
+			out = append(out, merged)
+			continue
+		}
+
+		e := s.match(item)
+		if e == nil {
+			out = append(out, item)
+			continue
+		}
+		e.used = true
+		out = append(out, postmanSyncItem(e.item, item))
+	}
+	return out
+}
+
+func (s *postmanSyncer) match(item map[string]interface{}) *postmanEntry {
+	if id, _ := item["id"].(string); id != "" {
+		if e := s.byID[id]; e != nil && !e.used {
+			return e
+		}
+	}
+	if e := s.byRoute[postmanRoute(item)]; e != nil && !e.used {
+		return e
+	}
+	return nil
+}
+
+// folder returns an empty copy of the existing folder with the given path,
+// or a new folder.
+func (s *postmanSyncer) folder(path ...string) map[string]interface{} {
+	folder := copyMap(s.folders[strings.Join(path, "/")])
+	folder["name"] = path[len(path)-1]
+	folder["item"] = []interface{}{}
+	return folder
+}
+
+// place adds item to the folder with the given path, creating the missing
+// folders.
+func (s *postmanSyncer) place(items []interface{}, folder []string, item map[string]interface{}) []interface{} {
+	return s.placeIn(items, nil, folder, item)
+}
+
+func (s *postmanSyncer) placeIn(items []interface{}, parent, folder []string, item map[string]interface{}) []interface{} {
+	if len(folder) == 0 {
+		return append(items, item)
+	}
+
+	path := append(append([]string{}, parent...), folder[0])
+	for _, v := range items {
+		f := mapValue(v)
+		if _, ok := f["item"]; ok && f["name"] == folder[0] {
+			f["item"] = s.placeIn(postmanChildren(f), path, folder[1:], item)
+			return items
+		}
+	}
+	f := s.folder(path...)
+	f["item"] = s.placeIn(nil, path, folder[1:], item)
+	return append(items, f)
+}
+
+// postmanSyncItem returns the existing request updated with the generated
+// one.
+func postmanSyncItem(existing, generated map[string]interface{}) map[string]interface{} {
+	item := copyMap(existing)
+	item["name"] = generated["name"]
+	if description, ok := generated["description"]; ok {
+		item["description"] = description
+	} else {
+		delete(item, "description")
+	}
+	if _, ok := item["id"]; !ok && generated["id"] != nil {
+		item["id"] = generated["id"]
+	}
+
+	request := copyMap(postmanRequest(existing))
+	genRequest := postmanRequest(generated)
+	request["method"] = genRequest["method"]
+	request["url"] = postmanSyncURL(request["url"], genRequest["url"])
+	if headers := postmanSyncHeaders(request["header"], genRequest["header"]); len(headers) > 0 {
+		request["header"] = headers
+	} else {
+		delete(request, "header")
+	}
+	if request["body"] == nil && genRequest["body"] != nil {
+		request["body"] = genRequest["body"]
+	}
+	item["request"] = request
+
+	if events := postmanSyncEvents(existing["event"], generated["event"]); len(events) > 0 {
+		item["event"] = events
+	} else {
+		delete(item, "event")
+	}
+	if responses, _ := existing["response"].([]interface{}); len(responses) == 0 && generated["response"] != nil {
+		item["response"] = generated["response"]
+	}
+	return item
+}
+
+// postmanSyncURL returns the generated URL with the values of the existing
+// query parameters and path variables.
+func postmanSyncURL(existing, generated interface{}) interface{} {
+	old := mapValue(existing)
+	url := copyMap(mapValue(generated))
+	values := func(list interface{}, key string) map[string]map[string]interface{} {
+		out := make(map[string]map[string]interface{})
+		for _, v := range listValue(list) {
+			m := mapValue(v)
+			if k, _ := m[key].(string); k != "" {
+				out[k] = m
+			}
+		}
+		return out
+	}
+
+	oldQuery := values(old["query"], "key")
+	var query []interface{}
+	for _, v := range listValue(url["query"]) {
+		q := copyMap(mapValue(v))
+		key, _ := q["key"].(string)
+		if o := oldQuery[key]; o != nil {
+			if value, _ := o["value"].(string); value != "" {
+				q["value"] = value
+			}
+			if disabled, ok := o["disabled"]; ok {
+				q["disabled"] = disabled
+			}
+		}
+		query = append(query, q)
+	}
+	if query != nil {
+		url["query"] = query
+	}
+
+	oldVariables := values(old["variable"], "key")
+	var variables []interface{}
+	for _, v := range listValue(url["variable"]) {
+		variable := copyMap(mapValue(v))
+		key, _ := variable["key"].(string)
+		if o := oldVariables[key]; o != nil {
+			if value, _ := o["value"].(string); value != "" {
+				variable["value"] = value
+			}
+		}
+		variables = append(variables, variable)
+	}
+	if variables != nil {
+		url["variable"] = variables
+	}
+
+	// The address bar shows the raw URL, it has to follow the values.
+	raw, _ := url["raw"].(string)
+	raw = strings.SplitN(raw, "?", 2)[0]
+	for _, v := range query {
+		q := mapValue(v)
+		if disabled, _ := q["disabled"].(bool); disabled {
+			continue
+		}
+		sep := "&"
+		if !strings.Contains(raw, "?") {
+			sep = "?"
+		}
+		key, _ := q["key"].(string)
+		value, _ := q["value"].(string)
+		raw += sep + key + "=" + value
+	}
+	url["raw"] = raw
+	return url
+}
+
+// postmanSyncHeaders returns the generated headers followed by the headers
+// added in Postman.
+func postmanSyncHeaders(existing, generated interface{}) []interface{} {
+	var headers []interface{}
+	keys := make(map[string]bool)
+	for _, v := range listValue(generated) {
+		h := mapValue(v)
+		key, _ := h["key"].(string)
+		keys[strings.ToLower(key)] = true
+		headers = append(headers, h)
+	}
+	for _, v := range listValue(existing) {
+		h := mapValue(v)
+		key, _ := h["key"].(string)
+		if !keys[strings.ToLower(key)] {
+			headers = append(headers, h)
+		}
+	}
+	return headers
+}
+
+// postmanSyncEvents replaces the generated test script. A test script
+// written in Postman is kept instead.
+func postmanSyncEvents(existing, generated interface{}) []interface{} {
+	var events []interface{}
+	custom := false
+	for _, v := range listValue(existing) {
+		e := mapValue(v)
+		if e["listen"] == "test" {
+			exec := listValue(mapValue(e["script"])["exec"])
+			if len(exec) > 0 && exec[0] == postmanTestMarker {
+				continue
+			}
+			custom = true
+		}
+		events = append(events, e)
+	}
+	for _, v := range listValue(generated) {
+		if e := mapValue(v); e["listen"] != "test" || !custom {
+			events = append(events, e)
+		}
+	}
+	return events
+}
+
+// postmanRoute returns the method and path of a request, e.g.
+// "GET /v1/orders/:id".
+func postmanRoute(item map[string]interface{}) string {
+	request := postmanRequest(item)
+	method, _ := request["method"].(string)
+	if method == "" {
+		method = "GET"
+	}
+
+	var path string
+	switch url := request["url"].(type) {
+	case string:
+		path = postmanRawPath(url)
+	case map[string]interface{}:
+		if segments := listValue(url["path"]); len(segments) > 0 {
+			parts := make([]string, 0, len(segments))
+			for _, s := range segments {
+				if s, ok := s.(string); ok && s != "" {
+					parts = append(parts, s)
+				}
+			}
+			path = "/" + strings.Join(parts, "/")
+		} else if raw, ok := url["raw"].(string); ok {
+			path = postmanRawPath(raw)
+		}
+	}
+	if path == "" {
+		return ""
+	}
+	return strings.ToUpper(method) + " " + path
+}
+
+// postmanRawPath returns the path of a raw URL such as
+// {{BASE_URL}}/v1/orders/:id?status=open.
+func postmanRawPath(raw string) string {
+	raw = strings.SplitN(raw, "?", 2)[0]
+	raw = strings.SplitN(raw, "#", 2)[0]
+	if i := strings.Index(raw, "://"); i >= 0 {
+		raw = raw[i+3:]
+	}
+	if i := strings.Index(raw, "/"); i >= 0 {
+		return "/" + strings.Trim(raw[i:], "/")
+	}
+	return ""
+}
+
+// postmanRequest returns the request of an item. Postman writes GET
+// requests without fields as their URL.
+func postmanRequest(item map[string]interface{}) map[string]interface{} {
+	switch request := item["request"].(type) {
+	case string:
+		return map[string]interface{}{"method": "GET", "url": request}
+	case map[string]interface{}:
+		return request
+	}
+	return nil
+}
+
+func postmanChildren(item map[string]interface{}) []interface{} {
+	return listValue(item["item"])
+}
+
+func copyMap(m map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		out[k] = v
+	}
+	return out
+}
+
+func mapValue(v interface{}) map[string]interface{} {
+	m, _ := v.(map[string]interface{})
+	return m
+}
+
+func listValue(v interface{}) []interface{} {
+	l, _ := v.([]interface{})
+	return l
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostmanSync(t *testing.T) {
+	existing := `{
+		"info": {"_postman_id": "c0ffee", "name": "old", "schema": "v2.1.0"},
+		"auth": {"type": "bearer"},
+		"item": [
+			{"name": "orders", "description": "Orders of a customer", "item": [
+				{
+					"name": "GET /v1/orders/:id",
+					"id": "OrderController.Get",
+					"request": {
+						"method": "GET",
+						"url": {
+							"raw": "{{BASE_URL}}/v1/orders/:id?expand=",
+							"host": ["{{BASE_URL}}"],
+							"path": ["v1", "orders", ":id"],
+							"query": [{"key": "expand", "value": ""}],
+							"variable": [{"key": "id", "value": "1234"}]
+						},
+						"header": [{"key": "Accept", "value": "text/plain"}, {"key": "X-Debug", "value": "1"}],
+						"auth": {"type": "noauth"}
+					},
+					"event": [
+						{"listen": "prerequest", "script": {"exec": ["console.log(1)"]}},
+						{"listen": "test", "script": {"exec": ["` + postmanTestMarker + `", "old"]}}
+					],
+					"response": [{"name": "saved example", "code": 200}]
+				},
+				{"name": "Try the search", "request": "{{BASE_URL}}/v1/orders/search?q=shoe"},
+				{"name": "DELETE /v1/orders/:id", "id": "OrderController.Delete", "request": {"method": "DELETE", "url": "{{BASE_URL}}/v1/orders/:id"}}
+			]},
+			{"name": "GET /v1/health", "id": "4a9e", "request": {"method": "GET", "url": {"raw": "{{BASE_URL}}//v1/health", "path": ["", "v1", "health"]}}},
+			{"name": "Archived", "item": [
+				{"name": "POST /v1/carts", "id": "CartController.Post", "request": {"method": "POST", "url": "{{BASE_URL}}/v1/carts"}}
+			]}
+		]
+	}`
+	generated := `{
+		"info": {"name": "shop", "schema": "v2.1.0"},
+		"item": [
+			{"name": "carts", "item": [
+				{"name": "POST /v1/carts", "id": "CartController.Post", "request": {"method": "POST", "url": {"raw": "{{BASE_URL}}/v1/carts", "path": ["v1", "carts"]}}}
+			]},
+			{"name": "orders", "item": [
+				{
+					"name": "GET /v1/orders/:id",
+					"id": "OrderController.Get",
+					"description": "Get an order",
+					"request": {
+						"method": "GET",
+						"url": {
+							"raw": "{{BASE_URL}}/v1/orders/:id?expand=lines",
+							"host": ["{{BASE_URL}}"],
+							"path": ["v1", "orders", ":id"],
+							"query": [{"key": "expand", "value": "lines"}],
+							"variable": [{"key": "id", "value": "0"}]
+						},
+						"header": [{"key": "Accept", "value": "application/json"}]
+					},
+					"event": [{"listen": "test", "script": {"exec": ["` + postmanTestMarker + `", "new"]}}],
+					"response": [{"name": "OK", "code": 200}]
+				}
+			]},
+			{"name": "GET /v1/health", "id": "HealthController.Get", "request": {"method": "GET", "url": {"raw": "{{BASE_URL}}/v1/health", "path": ["v1", "health"]}}}
+		]
+	}`
+
+	var old, gen map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(existing), &old))
+	require.NoError(t, json.Unmarshal([]byte(generated), &gen))
+
+	assert.JSONEq(t, `{
+		"info": {"_postman_id": "c0ffee", "name": "shop", "schema": "v2.1.0"},
+		"auth": {"type": "bearer"},
+		"item": [
+			{"name": "carts", "item": [
+				{"name": "POST /v1/carts", "id": "CartController.Post", "request": {"method": "POST", "url": {"raw": "{{BASE_URL}}/v1/carts", "path": ["v1", "carts"]}}}
+			]},
+			{"name": "orders", "description": "Orders of a customer", "item": [
+				{
+					"name": "GET /v1/orders/:id",
+					"id": "OrderController.Get",
+					"description": "Get an order",
+					"request": {
+						"method": "GET",
+						"url": {
+							"raw": "{{BASE_URL}}/v1/orders/:id?expand=lines",
+							"host": ["{{BASE_URL}}"],
+							"path": ["v1", "orders", ":id"],
+							"query": [{"key": "expand", "value": "lines"}],
+							"variable": [{"key": "id", "value": "1234"}]
+						},
+						"header": [{"key": "Accept", "value": "application/json"}, {"key": "X-Debug", "value": "1"}],
+						"auth": {"type": "noauth"}
+					},
+					"event": [
+						{"listen": "prerequest", "script": {"exec": ["console.log(1)"]}},
+						{"listen": "test", "script": {"exec": ["`+postmanTestMarker+`", "new"]}}
+					],
+					"response": [{"name": "saved example", "code": 200}]
+				},
+				{"name": "Try the search", "request": "{{BASE_URL}}/v1/orders/search?q=shoe"}
+			]},
+			{"name": "GET /v1/health", "id": "4a9e", "request": {"method": "GET", "url": {"raw": "{{BASE_URL}}/v1/health", "path": ["v1", "health"]}}},
+			{"name": "Archived", "item": [
+				{"name": "DELETE /v1/orders/:id", "id": "OrderController.Delete", "request": {"method": "DELETE", "url": "{{BASE_URL}}/v1/orders/:id"}}
+			]}
+		]
+	}`, mustJSON(t, postmanSync(old, gen)))
+}
+
+func TestPostmanSyncEvents(t *testing.T) {
+	custom := []interface{}{
+		map[string]interface{}{"listen": "test", "script": map[string]interface{}{"exec": []interface{}{"pm.test()"}}},
+	}
+	generated := []interface{}{
+		map[string]interface{}{"listen": "test", "script": map[string]interface{}{"exec": []interface{}{postmanTestMarker}}},
+	}
+	assert.Equal(t, custom, postmanSyncEvents(custom, generated))
+	assert.Equal(t, generated, postmanSyncEvents(nil, generated))
+}
+
+func TestPostmanRoute(t *testing.T) {
+	for _, tc := range []struct {
+		item  string
+		route string
+	}{
+		{`{"request": "{{BASE_URL}}/v1/orders?q=1"}`, "GET /v1/orders"},
+		{`{"request": {"method": "post", "url": "https://shop.example.com/v1/orders/:id/"}}`, "POST /v1/orders/:id"},
+		{`{"request": {"method": "PUT", "url": {"path": ["", "v1", "orders"]}}}`, "PUT /v1/orders"},
+		{`{"request": {"method": "PUT", "url": {"raw": "{{BASE_URL}}"}}}`, ""},
+		{`{"name": "no request"}`, ""},
+	} {
+		var item map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(tc.item), &item))
+		assert.Equal(t, tc.route, postmanRoute(item), tc.item)
+	}
+}
@@ -0,0 +1,331 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/rbretecher/go-postman-collection"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zalora/bee/docgen"
+)
+
+const postmanTestSpec = `{
+	"basePath": "/v1",
+	"info": {"title": "shop"},
+	"paths": {
+		"/health": {
+			"get": {"operationId": "HealthController.Get", "responses": {"200": {"description": "ok"}}}
+		},
+		"/orders/{id}": {
+			"get": {"tags": ["orders"], "operationId": "OrderController.Get", "responses": {"200": {"description": "ok"}}},
+			"put": {
+				"tags": ["orders"],
+				"operationId": "OrderController.Put",
+				"consumes": ["text/plain", "application/vnd.shop+json"],
+				"parameters": [
+					{"in": "path", "name": "id", "required": true, "type": "integer", "example": "42"},
+					{"in": "query", "name": "status", "type": "string", "enum": ["open", "closed"]},
+					{"in": "query", "name": "ids", "type": "array", "items": {"type": "integer"}, "default": "1,2"},
+					{"in": "body", "name": "body", "schema": {"$ref": "#/definitions/models.Order"}}
+				],
+				"responses": {
+					"200": {"description": "ok", "schema": {"$ref": "#/definitions/models.Order"}},
+					"404": {"description": "not found"}
+				}
+			}
+		},
+		"/carts/{id}/note": {
+			"post": {
+				"tags": ["carts"],
+				"operationId": "CartController.Note",
+				"consumes": ["application/x-www-form-urlencoded"],
+				"parameters": [
+					{"in": "path", "name": "id", "required": true, "type": "string", "format": "uuid"},
+					{"in": "formData", "name": "note", "type": "string", "default": "gift"}
+				],
+				"responses": {"200": {"description": "ok"}}
+			}
+		},
+		"/carts": {
+			"get": {"tags": ["carts"], "operationId": "CartController.List", "responses": {"200": {"description": "ok"}}}
+		},
+		"/customers": {
+			"get": {"tags": ["customers"], "operationId": "CustomerController.List", "responses": {"200": {"description": "ok"}}}
+		}
+	},
+	"definitions": {
+		"models.Order": {
+			"type": "object",
+			"properties": {
+				"id": {"type": "integer", "format": "int64"},
+				"total": {"type": "number", "minimum": 1.5},
+				"status": {"type": "string", "enum": ["open", "closed"]},
+				"parent": {"$ref": "#/definitions/models.Order"},
+				"lines": {"type": "array", "items": {"$ref": "#/definitions/models.Line"}}
+			}
+		},
+		"models.Line": {
+			"type": "object",
+			"properties": {
+				"sku": {"type": "string", "example": "SKU-1"},
+				"created": {"type": "string", "format": "date-time"}
+			},
+			"required": ["sku"]
+		}
+	}
+}`
+
+func postmanTestCollection(t *testing.T, opts postmanOptions) *postman.Collection {
+	spec := &docgen.Spec{}
+	require.NoError(t, json.Unmarshal([]byte(postmanTestSpec), &spec.Swagger))
+	require.NoError(t, json.Unmarshal([]byte(postmanTestSpec), &spec.Document))
+	return postmanCollection(spec, opts)
+}
+
+func TestPostmanCollection(t *testing.T) {
+	p := postmanTestCollection(t, postmanOptions{})
+	assert.Equal(t, "shop", p.Info.Name)
+
+	var names []string
+	for _, item := range p.Items {
+		names = append(names, item.Name)
+	}
+	assert.Equal(t, []string{"carts", "customers", "orders", "GET /v1/health"}, names)
+
+	get := p.Items[2].Items[0].Request
+	assert.Equal(t, []string{"{{BASE_URL}}"}, get.URL.Host)
+	assert.Equal(t, []*postman.Header{{Key: "Accept", Value: "application/json"}}, get.Header)
+}
+
+func TestPostmanItemValues(t *testing.T) {
+	p := postmanTestCollection(t, postmanOptions{})
+
+	put := p.Items[2].Items[1].Request
+	assert.Equal(t, postman.Put, put.Method)
+	assert.Equal(t, "{{BASE_URL}}/v1/orders/:id?status=open&ids=1%2C2", put.URL.Raw)
+	assert.Equal(t, []string{"v1", "orders", ":id"}, put.URL.Path)
+	assert.Equal(t, "42", put.URL.Variables[0].Value)
+	assert.Equal(t, "status", put.URL.Query[0].Key)
+	assert.Equal(t, "open", put.URL.Query[0].Value)
+	assert.Equal(t, "1%2C2", put.URL.Query[1].Value)
+	assert.Equal(t, &postman.Header{Key: "Content-Type", Value: "application/vnd.shop+json"}, put.Header[1])
+	assert.Equal(t, "raw", put.Body.Mode)
+	assert.Equal(t, postman.JSON, put.Body.Options.Raw.Language)
+	assert.JSONEq(t, `{
+		"id": 0,
+		"total": 1.5,
+		"status": "open",
+		"parent": null,
+		"lines": [{"sku": "SKU-1", "created": "2006-01-02T15:04:05Z"}]
+	}`, put.Body.Raw)
+
+	note := p.Items[0].Items[1].Request
+	assert.Equal(t, "00000000-0000-0000-0000-000000000000", note.URL.Variables[0].Value)
+	assert.Equal(t, "urlencoded", note.Body.Mode)
+	assert.Equal(t, []*postman.Variable{{Key: "note", Type: "text", Value: "gift"}}, note.Body.URLEncoded)
+	assert.Equal(t, &postman.Header{Key: "Content-Type", Value: "application/x-www-form-urlencoded"}, note.Header[1])
+}
+
+func TestPostmanTestEvent(t *testing.T) {
+	p := postmanTestCollection(t, postmanOptions{})
+
+	events := p.Items[2].Items[1].Events
+	require.Len(t, events, 1)
+	assert.Equal(t, postman.Test, events[0].Listen)
+	script := strings.Join(events[0].Script.Exec, "\n")
+	assert.Contains(t, script, `pm.expect(pm.response.code).to.be.oneOf([200,404]);`)
+	assert.Contains(t, script, `ajv.validate(schema, pm.response.json())`)
+
+	var schemas map[string]interface{}
+	line := events[0].Script.Exec[4]
+	require.True(t, strings.HasPrefix(line, "var schemas = "))
+	require.NoError(t, json.Unmarshal([]byte(strings.TrimSuffix(strings.TrimPrefix(line, "var schemas = "), ";")), &schemas))
+	assert.JSONEq(t, `{"200": {
+		"$ref": "#/definitions/models.Order",
+		"definitions": {
+			"models.Order": {
+				"type": "object",
+				"properties": {
+					"id": {"anyOf": [{"type": "integer", "format": "int64"}, {"type": "null"}]},
+					"total": {"anyOf": [{"type": "number", "minimum": 1.5}, {"type": "null"}]},
+					"status": {"anyOf": [{"type": "string", "enum": ["open", "closed"]}, {"type": "null"}]},
+					"parent": {"anyOf": [{"$ref": "#/definitions/models.Order"}, {"type": "null"}]},
+					"lines": {"anyOf": [{"type": "array", "items": {"$ref": "#/definitions/models.Line"}}, {"type": "null"}]}
+				}
+			},
+			"models.Line": {
+				"type": "object",
+				"properties": {
+					"sku": {"type": "string", "example": "SKU-1"},
+					"created": {"anyOf": [{"type": "string", "format": "date-time"}, {"type": "null"}]}
+				},
+				"required": ["sku"]
+			}
+		}
+	}}`, mustJSON(t, schemas))
+
+	// Without a response schema only the status is checked.
+	events = p.Items[2].Items[0].Events
+	require.Len(t, events, 1)
+	assert.Equal(t, postmanTestMarker, events[0].Script.Exec[0])
+	assert.Len(t, events[0].Script.Exec, 4)
+}
+
+func mustJSON(t *testing.T, v interface{}) string {
+	b, err := json.Marshal(v)
+	require.NoError(t, err)
+	return string(b)
+}
+
+func TestPostmanCollectionOptions(t *testing.T) {
+	opts := postmanOptions{
+		Name:        "Doraemon",
+		Description: "# DORAEMON POSTMAN COLLECTION\n\nSee the \"Usage\" section.",
+		Prefix:      "DOR",
+		Headers: []postmanHeader{
+			{Key: "Accept", Value: "application/json"},
+			{Key: "Content-Language", Value: "{{CONTENT_LANGUAGE}}"},
+		},
+		Folders: []string{"Customers", "orders"},
+	}
+	p := postmanTestCollection(t, opts)
+	assert.Equal(t, "Doraemon", p.Info.Name)
+	b, err := json.Marshal(p)
+	require.NoError(t, err)
+	assert.Contains(t, string(b), `"content":"# DORAEMON POSTMAN COLLECTION\n\nSee the \"Usage\" section."`)
+
+	var names []string
+	for _, item := range p.Items {
+		names = append(names, item.Name)
+	}
+	assert.Equal(t, []string{"customers", "orders", "carts", "GET /v1/health"}, names)
+
+	get := p.Items[1].Items[0].Request
+	assert.Equal(t, []string{"{{DOR_BASE_URL}}"}, get.URL.Host)
+	assert.Equal(t, "{{DOR_CONTENT_LANGUAGE}}", get.Header[1].Value)
+}
+
+func TestPostmanFolders(t *testing.T) {
+	spec := &docgen.Spec{}
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"basePath": "/api",
+		"paths": {
+			"/v1/catalog/products/{id}": {
+				"get": {"tags": ["products", "catalog"], "operationId": "ProductController.Get", "responses": {}}
+			},
+			"/v1/catalog/products": {
+				"get": {"tags": ["products"], "operationId": "ProductController.List", "responses": {}}
+			},
+			"/v1/catalog": {
+				"get": {"tags": ["catalog"], "operationId": "catalog.Index", "responses": {}}
+			},
+			"/health": {
+				"get": {"responses": {}}
+			}
+		}
+	}`), &spec.Document))
+	require.NoError(t, json.Unmarshal([]byte(mustJSON(t, spec.Document)), &spec.Swagger))
+
+	tree := func(items []*postman.Items) []string {
+		var lines []string
+		var walk func(items []*postman.Items, indent string)
+		walk = func(items []*postman.Items, indent string) {
+			for _, item := range items {
+				lines = append(lines, indent+item.Name)
+				walk(item.Items, indent+"  ")
+			}
+		}
+		walk(items, "")
+		return lines
+	}
+
+	for _, tc := range []struct {
+		opts postmanOptions
+		tree []string
+	}{
+		{postmanOptions{}, []string{
+			"catalog",
+			"  GET /api/v1/catalog",
+			"products",
+			"  GET /api/v1/catalog/products",
+			"  GET /api/v1/catalog/products/:id",
+			"GET /api/health",
+		}},
+		{postmanOptions{MultiTag: "all"}, []string{
+			"catalog",
+			"  GET /api/v1/catalog",
+			"  GET /api/v1/catalog/products/:id",
+			"products",
+			"  GET /api/v1/catalog/products",
+			"  GET /api/v1/catalog/products/:id",
+			"GET /api/health",
+		}},
+		{postmanOptions{FolderBy: "path"}, []string{
+			"catalog",
+			"  products",
+			"    GET /api/v1/catalog/products",
+			"    GET /api/v1/catalog/products/:id",
+			"  GET /api/v1/catalog",
+			"health",
+			"  GET /api/health",
+		}},
+		{postmanOptions{FolderBy: "controller", Folders: []string{"ProductController"}}, []string{
+			"ProductController",
+			"  GET /api/v1/catalog/products",
+			"  GET /api/v1/catalog/products/:id",
+			"catalog",
+			"  GET /api/v1/catalog",
+			"GET /api/health",
+		}},
+	} {
+		p := postmanCollection(spec, tc.opts)
+		assert.Equal(t, tc.tree, tree(p.Items), "%+v", tc.opts)
+	}
+
+	// Copies of an operation in several folders have their own ID.
+	p := postmanCollection(spec, postmanOptions{MultiTag: "all"})
+	assert.Equal(t, "ProductController.Get", p.Items[1].Items[1].ID)
+	assert.Equal(t, postmanID("ProductController.Get", "catalog"), p.Items[0].Items[1].ID)
+}
+
+func TestPostmanEnvironmentData(t *testing.T) {
+	opts := postmanOptions{Prefix: "DOR"}
+	env := postmanEnvironment{
+		Name:      "staging",
+		BaseURL:   "https://staging.example.com",
+		Variables: map[string]string{"USER_AGENT": "bee", "CONTENT_LANGUAGE": "en"},
+	}
+
+	data := postmanEnvironmentData("shop", env, opts)
+	assert.Equal(t, "shop (staging)", data.Name)
+	assert.Equal(t, "environment", data.Scope)
+	assert.Equal(t, postmanID("shop", "staging"), data.ID)
+	assert.Len(t, data.ID, 36)
+	assert.Equal(t, []postmanEnvironmentValue{
+		{Key: "DOR_BASE_URL", Value: "https://staging.example.com", Type: "default", Enabled: true},
+		{Key: "DOR_CONTENT_LANGUAGE", Value: "en", Type: "default", Enabled: true},
+		{Key: "DOR_USER_AGENT", Value: "bee", Type: "default", Enabled: true},
+	}, data.Values)
+}
+
+func TestDeprecationNote(t *testing.T) {
+	assert.Equal(t, "", deprecationNote(map[string]interface{}{}))
+	assert.Equal(t, "**Deprecated**.", deprecationNote(map[string]interface{}{"deprecated": true}))
+	assert.Equal(t,
+		"**Deprecated** since 2026-01-01. It will be removed on 2999-06-30. Use `/v2/orders` instead.",
+		deprecationNote(map[string]interface{}{
+			"deprecated":         true,
+			"x-deprecated-since": "2026-01-01",
+			"x-sunset":           "2999-06-30",
+			"x-replaced-by":      "/v2/orders",
+		}))
+	assert.Equal(t,
+		"**Deprecated** since 2019-01-01. It was due for removal on 2020-06-30.",
+		deprecationNote(map[string]interface{}{
+			"deprecated":         true,
+			"x-deprecated-since": "2019-01-01",
+			"x-sunset":           "2020-06-30",
+		}))
+}
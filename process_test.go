@@ -0,0 +1,60 @@
+//go:build !windows
+// +build !windows
+
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStopProcess(t *testing.T) {
+	tests := []struct {
+		name     string
+		script   string
+		graceful bool
+	}{
+		{"exits on SIGTERM", `trap 'echo stopped > "$1"; exit 0' TERM`, true},
+		{"ignores SIGTERM", `trap '' TERM`, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			pidFile := filepath.Join(dir, "child")
+			// The child outlives the app unless its group is killed.
+			c := exec.Command("sh", "-c", tt.script+`; sleep 60 & echo $! > "$2"; while :; do sleep 0.01; done`,
+				"sh", filepath.Join(dir, "stopped"), pidFile)
+			setProcessGroup(c)
+			require.NoError(t, c.Start())
+			done := make(chan struct{})
+			go func() {
+				c.Wait()
+				close(done)
+			}()
+
+			var child int
+			require.Eventually(t, func() bool {
+				b, err := os.ReadFile(pidFile)
+				child, err = strconv.Atoi(strings.TrimSpace(string(b)))
+				return err == nil
+			}, 5*time.Second, 10*time.Millisecond)
+
+			assert.Equal(t, tt.graceful, stopProcess(c.Process, done, syscall.SIGTERM, 300*time.Millisecond))
+			_, err := os.Stat(filepath.Join(dir, "stopped"))
+			assert.Equal(t, tt.graceful, err == nil)
+			assert.Eventually(t, func() bool {
+				return syscall.Kill(child, 0) == syscall.ESRCH
+			}, 5*time.Second, 10*time.Millisecond)
+		})
+	}
+}
@@ -0,0 +1,390 @@
+// Copyright 2013 bee authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	path "path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// processConfig is an entry of the processes section of the Beefile, or a
+// line of the Procfile.
+type processConfig struct {
+	Name string
+	// Main package or files built for the process, e.g. ./cmd/worker.
+	Main string
+	// Args of the built binary.
+	Args []string
+	// Command run by the shell instead of the built binary. Without Main,
+	// it is restarted on every change.
+	Command string
+}
+
+var (
+	procfileLineRegexp = regexp.MustCompile(`^([A-Za-z0-9_-]+):\s*(.+)$`)
+	processColors      = []uint8{Cyan, Yellow, Green, Magenta, Red, Blue}
+	// Processes run by bee run, stopped when it exits.
+	runningProcesses []*process
+	// Temporary directory the binaries of the processes are built into,
+	// removed when bee run exits.
+	processBinDir string
+)
+
+// loadProcesses returns the processes of the Beefile, or of the Procfile
+// in dir.
+func loadProcesses(dir string) ([]processConfig, error) {
+	configs := conf.Processes
+	if len(configs) == 0 {
+		f, err := os.Open(path.Join(dir, "Procfile"))
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		ColorLog("[INFO] Detected Procfile\n")
+		if configs, err = parseProcfile(dir, f); err != nil {
+			return nil, err
+		}
+	}
+
+	names := make(map[string]bool)
+	for _, c := range configs {
+		switch {
+		case c.Name == "":
+			return nil, fmt.Errorf("a process has no name")
+		case names[c.Name]:
+			return nil, fmt.Errorf("process %s is declared twice", c.Name)
+		case c.Main == "" && c.Command == "":
+			return nil, fmt.Errorf("process %s has no main package nor command", c.Name)
+		}
+		names[c.Name] = true
+	}
+	return configs, nil
+}
+
+// parseProcfile parses the `name: command` lines of a Procfile. Commands
+// starting with a main package, or Go files, build it and run the binary
+// with the rest of the line.
+func parseProcfile(dir string, r io.Reader) ([]processConfig, error) {
+	var configs []processConfig
+	s := bufio.NewScanner(r)
+	for n := 1; s.Scan(); n++ {
+		line := strings.TrimSpace(s.Text())
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		m := procfileLineRegexp.FindStringSubmatch(line)
+		if m == nil {
+			return nil, fmt.Errorf("Procfile:%d: expected name: command", n)
+		}
+
+		c := processConfig{Name: m[1]}
+		fields := strings.Fields(m[2])
+		if isMainPackage(dir, fields[0]) {
+			c.Main, c.Args = fields[0], fields[1:]
+		} else {
+			c.Command = m[2]
+		}
+		configs = append(configs, c)
+	}
+	return configs, s.Err()
+}
+
+// isMainPackage reports whether arg is a Go file or a directory of Go
+// files.
+func isMainPackage(dir, arg string) bool {
+	if strings.HasSuffix(arg, ".go") {
+		return true
+	}
+	if !strings.HasPrefix(arg, ".") {
+		// A command of the PATH.
+		return false
+	}
+	fileInfos, err := ioutil.ReadDir(path.Join(dir, arg))
+	if err != nil {
+		return false
+	}
+	for _, fileInfo := range fileInfos {
+		if !fileInfo.IsDir() && path.Ext(fileInfo.Name()) == ".go" {
+			return true
+		}
+	}
+	return false
+}
+
+// process is a process run by bee run.
+type process struct {
+	processConfig
+	dir string
+	bin string
+	out *prefixWriter
+	// deps are the directories of the packages the process is built from.
+	deps map[string]bool
+
+	cmd  *exec.Cmd
+	done <-chan struct{}
+}
+
+// newProcesses returns the processes of configs run in dir, with their
+// binaries built into binDir.
+func newProcesses(dir, binDir string, configs []processConfig) []*process {
+	width := 0
+	for _, c := range configs {
+		if len(c.Name) > width {
+			width = len(c.Name)
+		}
+	}
+
+	var procs []*process
+	for i, c := range configs {
+		bin := path.Join(binDir, c.Name)
+		if runtime.GOOS == "windows" {
+			bin += ".exe"
+		}
+		prefix := fmt.Sprintf("%-*s | ", width, c.Name)
+		if runtime.GOOS != "windows" {
+			prefix = fmt.Sprintf("\033[%dm%s\033[0m", processColors[i%len(processColors)], prefix)
+		}
+		procs = append(procs, &process{
+			processConfig: c,
+			dir:           dir,
+			bin:           bin,
+			out:           &prefixWriter{w: os.Stdout, prefix: prefix},
+		})
+	}
+	return procs
+}
+
+// runProcesses builds and starts the processes, and restarts those whose
+// packages change under paths.
+func runProcesses(dir string, configs []processConfig, paths []string) {
+	binDir, err := ioutil.TempDir("", "bee-processes-")
+	if err != nil {
+		ColorLog("[ERRO] Fail to create the directory of the binaries[ %s ]\n", err)
+		os.Exit(2)
+	}
+
+	state.Lock()
+	processBinDir = binDir
+	runningProcesses = newProcesses(dir, binDir, configs)
+	for _, p := range runningProcesses {
+		p.restart()
+	}
+	state.Unlock()
+
+	watchPaths(paths, func(changed []string) {
+		state.Lock()
+		defer state.Unlock()
+		for _, p := range runningProcesses {
+			if p.dependsOn(changed) {
+				p.restart()
+			}
+		}
+	})
+}
+
+// stopProcesses stops the running processes at once and removes their
+// binaries.
+func stopProcesses() {
+	var wg sync.WaitGroup
+	for _, p := range runningProcesses {
+		wg.Add(1)
+		go func(p *process) {
+			defer wg.Done()
+			p.stop()
+		}(p)
+	}
+	wg.Wait()
+	if processBinDir != "" {
+		os.RemoveAll(processBinDir)
+	}
+}
+
+// dependsOn reports whether p is rebuilt when the files changed.
+func (p *process) dependsOn(changed []string) bool {
+	if p.Main == "" || p.deps == nil {
+		// Unknown packages, without a successful build.
+		return true
+	}
+	for _, name := range changed {
+		if p.deps[name] || p.deps[path.Dir(name)] {
+			return true
+		}
+	}
+	return false
+}
+
+// restart rebuilds p, and restarts it when the build succeeds.
+func (p *process) restart() {
+	if p.Main != "" {
+		ColorLog("[INFO] Building %s...\n", p.Name)
+		if err := p.build(); err != nil {
+			ColorLog("[ERRO] Fail to build %s[ %s ]\n", p.Name, err)
+			return
+		}
+	}
+	p.stop()
+	ColorLog("[INFO] Starting %s...\n", p.Name)
+	p.start()
+}
+
+func (p *process) build() error {
+	main := strings.Fields(p.Main)
+	args := []string{"build", "-o", p.bin}
+	if buildTags != "" {
+		args = append(args, "-tags", buildTags)
+	}
+	c := exec.Command("go", append(args, main...)...)
+	c.Dir = p.dir
+	c.Env = append(os.Environ(), "GOGC=off")
+	c.Stdout = p.out
+	c.Stderr = p.out
+	if err := c.Run(); err != nil {
+		return err
+	}
+
+	deps, err := packageDirs(p.dir, main)
+	if err != nil {
+		return err
+	}
+	p.deps = deps
+	return nil
+}
+
+// packageDirs returns the directories of the packages of main and of those
+// it imports, outside the standard library.
+func packageDirs(dir string, main []string) (map[string]bool, error) {
+	args := []string{"list", "-deps", "-f", "{{if not .Standard}}{{.Dir}}{{end}}"}
+	if buildTags != "" {
+		args = append(args, "-tags", buildTags)
+	}
+	c := exec.Command("go", append(args, main...)...)
+	c.Dir = dir
+	c.Stderr = os.Stderr
+	out, err := c.Output()
+	if err != nil {
+		return nil, err
+	}
+
+	dirs := make(map[string]bool)
+	for _, d := range strings.Split(string(out), "\n") {
+		if d = strings.TrimSpace(d); d != "" {
+			dirs[d] = true
+		}
+	}
+	return dirs, nil
+}
+
+func (p *process) start() {
+	var c *exec.Cmd
+	switch {
+	case p.Command == "":
+		c = exec.Command(p.bin, p.Args...)
+	case runtime.GOOS == "windows":
+		c = exec.Command("cmd", "/C", p.Command)
+	default:
+		c = exec.Command("sh", "-c", p.Command)
+	}
+	out, closeOutput, err := outputPipe(p.out)
+	if err != nil {
+		ColorLog("[ERRO] Fail to start %s[ %s ]\n", p.Name, err)
+		return
+	}
+	c.Dir = p.dir
+	c.Stdout = out
+	c.Stderr = out
+	c.Env = append(os.Environ(), conf.Envs...)
+	setProcessGroup(c)
+
+	err = c.Start()
+	out.Close()
+	if err != nil {
+		closeOutput()
+		ColorLog("[ERRO] Fail to start %s[ %s ]\n", p.Name, err)
+		return
+	}
+	done := make(chan struct{})
+	go func() {
+		c.Wait()
+		closeOutput()
+		p.out.Flush()
+		fmt.Fprintf(p.out, "%s\n", c.ProcessState)
+		close(done)
+	}()
+	p.cmd, p.done = c, done
+}
+
+func (p *process) stop() {
+	if p.cmd == nil {
+		return
+	}
+	sig, grace, _ := shutdownOptions()
+	if !stopProcess(p.cmd.Process, p.done, sig, grace) {
+		ColorLog("[WARN] %s did not exit within %s, killed it\n", p.Name, grace)
+	}
+	p.cmd = nil
+}
+
+// prefixWriter writes lines prefixed with the name of their process.
+type prefixWriter struct {
+	w      io.Writer
+	prefix string
+
+	mu  sync.Mutex
+	buf []byte
+}
+
+func (w *prefixWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			break
+		}
+		w.writeLine(w.buf[:i])
+		w.buf = w.buf[i+1:]
+	}
+	return len(p), nil
+}
+
+// Flush writes the unterminated line.
+func (w *prefixWriter) Flush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if len(w.buf) > 0 {
+		w.writeLine(w.buf)
+		w.buf = nil
+	}
+}
+
+func (w *prefixWriter) writeLine(line []byte) {
+	fmt.Fprintf(w.w, "%s%s\n", w.prefix, bytes.TrimSuffix(line, []byte("\r")))
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseProcfile(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "cmd", "worker"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "cmd", "worker", "main.go"), []byte("package main\n"), 0644))
+
+	configs, err := parseProcfile(dir, strings.NewReader(`# Processes of bee run
+web: main.go -port=8080
+worker: ./cmd/worker -queue=emails
+
+assets: npm run watch -- --color
+`))
+	require.NoError(t, err)
+	assert.Equal(t, []processConfig{
+		{Name: "web", Main: "main.go", Args: []string{"-port=8080"}},
+		{Name: "worker", Main: "./cmd/worker", Args: []string{"-queue=emails"}},
+		{Name: "assets", Command: "npm run watch -- --color"},
+	}, configs)
+
+	_, err = parseProcfile(dir, strings.NewReader("web ./web\n"))
+	assert.EqualError(t, err, "Procfile:1: expected name: command")
+}
+
+func TestLoadProcesses(t *testing.T) {
+	defer func(processes []processConfig) { conf.Processes = processes }(conf.Processes)
+	dir := t.TempDir()
+
+	conf.Processes = nil
+	configs, err := loadProcesses(dir)
+	assert.NoError(t, err)
+	assert.Nil(t, configs)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "Procfile"), []byte("assets: npm run watch\n"), 0644))
+	configs, err = loadProcesses(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, []processConfig{{Name: "assets", Command: "npm run watch"}}, configs)
+
+	tests := []struct {
+		processes []processConfig
+		err       string
+	}{
+		{[]processConfig{{Main: "."}}, "a process has no name"},
+		{[]processConfig{{Name: "web", Main: "."}, {Name: "web", Command: "./web"}}, "process web is declared twice"},
+		{[]processConfig{{Name: "web"}}, "process web has no main package nor command"},
+	}
+	for _, tt := range tests {
+		conf.Processes = tt.processes
+		_, err := loadProcesses(dir)
+		assert.EqualError(t, err, tt.err)
+	}
+}
+
+func TestPrefixWriter(t *testing.T) {
+	var b bytes.Buffer
+	w := &prefixWriter{w: &b, prefix: "web    | "}
+	w.Write([]byte("listening on :8080\nGET /orders"))
+	w.Write([]byte(" 200\r\npartial"))
+	assert.Equal(t, "web    | listening on :8080\nweb    | GET /orders 200\n", b.String())
+	w.Flush()
+	assert.Equal(t, "web    | listening on :8080\nweb    | GET /orders 200\nweb    | partial\n", b.String())
+}
+
+// syncBuffer is a bytes.Buffer written by several goroutines.
+type syncBuffer struct {
+	mu sync.Mutex
+	b  bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.b.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.b.String()
+}
+
+func TestProcessRestart(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, src string) {
+		require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(src), 0644))
+	}
+	write("go.mod", "module example.com/shop\n\ngo 1.21\n")
+	write("greet/greet.go", "package greet\n\nconst Hello = \"hello\"\n")
+	write("cmd/web/main.go", `package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"example.com/shop/greet"
+)
+
+func main() {
+	fmt.Println(greet.Hello, os.Args[1])
+	time.Sleep(time.Minute)
+}
+`)
+	write("cmd/worker/main.go", "package main\n\nfunc main() { select {} }\n")
+	binDir := t.TempDir()
+
+	procs := newProcesses(dir, binDir, []processConfig{
+		{Name: "web", Main: "./cmd/web", Args: []string{"world"}},
+		{Name: "worker", Main: "./cmd/worker"},
+		{Name: "assets", Command: "echo built"},
+	})
+	var out syncBuffer
+	for _, p := range procs {
+		p.out.w = &out
+		defer p.stop()
+	}
+	assert.Contains(t, procs[0].out.prefix, "web    | ")
+	assert.NotEqual(t, procs[0].out.prefix, procs[1].out.prefix)
+
+	web := procs[0]
+	web.restart()
+	require.Eventually(t, func() bool {
+		return strings.Contains(out.String(), procs[0].out.prefix+"hello world\n")
+	}, 10*time.Second, 10*time.Millisecond, out.String())
+	assert.True(t, web.deps[filepath.Join(dir, "greet")])
+	// Built out of the project.
+	assert.Equal(t, binDir, filepath.Dir(web.bin))
+	assert.FileExists(t, web.bin)
+	assert.False(t, web.deps[filepath.Join(dir, "cmd", "worker")])
+
+	procs[1].restart()
+	changed := []string{filepath.Join(dir, "greet", "greet.go")}
+	assert.True(t, web.dependsOn(changed))
+	assert.False(t, procs[1].dependsOn(changed))
+	assert.True(t, procs[2].dependsOn(changed))
+
+	// The process is restarted with the new build, but kept when it fails.
+	write("greet/greet.go", "package greet\n\nconst Hello = \"hi\"\n")
+	web.restart()
+	require.Eventually(t, func() bool {
+		return strings.Contains(out.String(), "hi world\n")
+	}, 10*time.Second, 10*time.Millisecond, out.String())
+
+	write("greet/greet.go", "package greet\n\nconst Hello = undefined\n")
+	running := web.cmd
+	web.restart()
+	assert.Contains(t, out.String(), "undefined: undefined")
+	assert.Equal(t, running, web.cmd)
+}
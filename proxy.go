@@ -0,0 +1,327 @@
+// Copyright 2013 bee authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"html/template"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"os"
+	path "path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	liveReloadPath = "/__bee/livereload"
+	// proxyHoldTimeout is how long requests are held during a build.
+	proxyHoldTimeout = 2 * time.Minute
+)
+
+var liveReloadScript = []byte(`<script>(function () {
+	var source = new EventSource("` + liveReloadPath + `");
+	source.onmessage = function () { source.close(); location.reload(); };
+})();</script>`)
+
+var proxyErrorTpl = template.Must(template.New("error").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { margin: 0; font-family: -apple-system, BlinkMacSystemFont, sans-serif; background: #fafafa; color: #222; }
+h1 { margin: 0; padding: 16px 24px; font-size: 20px; color: #fff; background: #c0392b; }
+pre { margin: 24px; padding: 16px; overflow: auto; font: 13px/1.5 Menlo, Consolas, monospace; background: #fff; border: 1px solid #ddd; }
+p { margin: 24px; color: #666; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+{{if .Output}}<pre>{{.Output}}</pre>{{end}}
+<p>The page reloads when the app is running again.</p>
+{{.Script}}
+</body>
+</html>
+`))
+
+type proxyState int
+
+const (
+	proxyBuilding proxyState = iota
+	proxyReady
+	proxyFailed
+)
+
+// devProxy forwards the requests to the app run by bee run. It holds them
+// during builds, shows the errors when the app can't run, and reloads the
+// HTML pages it served after each restart.
+type devProxy struct {
+	target *url.URL
+	proxy  *httputil.ReverseProxy
+
+	mu      sync.Mutex
+	state   proxyState
+	title   string
+	output  string
+	changed chan struct{} // closed when the state changes
+	reloads map[chan struct{}]bool
+}
+
+// runProxy is the proxy of bee run -proxy, nil without it.
+var runProxy *devProxy
+
+func newDevProxy(target *url.URL) *devProxy {
+	p := &devProxy{
+		target:  target,
+		changed: make(chan struct{}),
+		reloads: make(map[chan struct{}]bool),
+	}
+	p.proxy = httputil.NewSingleHostReverseProxy(target)
+	director := p.proxy.Director
+	p.proxy.Director = func(r *http.Request) {
+		director(r)
+		// The transport then decompresses the responses, so that HTML
+		// pages can be rewritten.
+		r.Header.Del("Accept-Encoding")
+	}
+	p.proxy.ModifyResponse = injectLiveReload
+	p.proxy.ErrorHandler = func(w http.ResponseWriter, r *http.Request, err error) {
+		p.errorPage(w, http.StatusBadGateway, "The app is not answering", err.Error())
+	}
+	return p
+}
+
+// building holds the requests until the app is ready or failed.
+func (p *devProxy) building() {
+	p.setState(proxyBuilding, "", "")
+}
+
+// ready forwards the requests to the app, and reloads the pages.
+func (p *devProxy) ready() {
+	p.setState(proxyReady, "", "")
+}
+
+// failed answers the requests with an error page, and reloads the pages.
+func (p *devProxy) failed(title, output string) {
+	p.setState(proxyFailed, title, output)
+}
+
+func (p *devProxy) setState(state proxyState, title, output string) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.state, p.title, p.output = state, title, output
+	close(p.changed)
+	p.changed = make(chan struct{})
+	if state != proxyBuilding {
+		for c := range p.reloads {
+			close(c)
+		}
+		p.reloads = make(map[chan struct{}]bool)
+	}
+}
+
+func (p *devProxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == liveReloadPath {
+		p.liveReload(w, r)
+		return
+	}
+
+	hold := time.NewTimer(proxyHoldTimeout)
+	defer hold.Stop()
+	for {
+		p.mu.Lock()
+		state, title, output, changed := p.state, p.title, p.output, p.changed
+		p.mu.Unlock()
+
+		switch state {
+		case proxyReady:
+			p.proxy.ServeHTTP(w, r)
+			return
+		case proxyFailed:
+			p.errorPage(w, http.StatusInternalServerError, title, output)
+			return
+		}
+
+		select {
+		case <-changed:
+		case <-hold.C:
+			p.errorPage(w, http.StatusGatewayTimeout, "The app is still building", "")
+			return
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+// liveReload sends an event to the page when the app restarts or fails.
+func (p *devProxy) liveReload(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+
+	reload := make(chan struct{})
+	p.mu.Lock()
+	p.reloads[reload] = true
+	p.mu.Unlock()
+	defer func() {
+		p.mu.Lock()
+		delete(p.reloads, reload)
+		p.mu.Unlock()
+	}()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	fmt.Fprint(w, ": connected\n\n")
+	flusher.Flush()
+	select {
+	case <-reload:
+		fmt.Fprint(w, "data: reload\n\n")
+		flusher.Flush()
+	case <-r.Context().Done():
+	}
+}
+
+func (p *devProxy) errorPage(w http.ResponseWriter, status int, title, output string) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	proxyErrorTpl.Execute(w, map[string]interface{}{
+		"Title":  title,
+		"Output": output,
+		"Script": template.HTML(liveReloadScript),
+	})
+}
+
+// injectLiveReload adds the live reload script to the HTML responses.
+// Responses that have no body, to HEAD requests or with a 1xx, 204 or 304
+// status, are left alone.
+func injectLiveReload(resp *http.Response) error {
+	if resp.Request != nil && resp.Request.Method == http.MethodHead {
+		return nil
+	}
+	if resp.StatusCode < 200 || resp.StatusCode == http.StatusNoContent || resp.StatusCode == http.StatusNotModified {
+		return nil
+	}
+	if !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/html") || resp.Header.Get("Content-Encoding") != "" {
+		return nil
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return err
+	}
+
+	body = injectScript(body)
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	resp.ContentLength = int64(len(body))
+	resp.Header.Set("Content-Length", strconv.Itoa(len(body)))
+	return nil
+}
+
+// injectScript inserts the live reload script before the end of the body
+// of page.
+func injectScript(page []byte) []byte {
+	// Lower ASCII only, to keep the offsets of page.
+	lower := make([]byte, len(page))
+	for i, c := range page {
+		if 'A' <= c && c <= 'Z' {
+			c += 'a' - 'A'
+		}
+		lower[i] = c
+	}
+	i := bytes.LastIndex(lower, []byte("</body>"))
+	if i < 0 {
+		return append(page, liveReloadScript...)
+	}
+	out := make([]byte, 0, len(page)+len(liveReloadScript))
+	out = append(out, page[:i]...)
+	out = append(out, liveReloadScript...)
+	return append(out, page[i:]...)
+}
+
+var httpPortRegexp = regexp.MustCompile(`^\s*httpport\s*=\s*"?(\d+)"?`)
+
+// proxyTarget returns the URL of the app in dir: the one of its health
+// check, or localhost on the httpport of its conf/app.conf, 8080 by default.
+func proxyTarget(dir string) (*url.URL, error) {
+	h := conf.Run.Health
+	if h.URL != "" {
+		u, err := url.Parse(h.URL)
+		if err != nil {
+			return nil, err
+		}
+		target := &url.URL{Scheme: u.Scheme, Host: u.Host}
+		if target.Port() == "" {
+			port := "80"
+			if u.Scheme == "https" {
+				port = "443"
+			}
+			target.Host = net.JoinHostPort(u.Hostname(), port)
+		}
+		return target, nil
+	}
+
+	port := strconv.Itoa(h.Port)
+	if h.Port == 0 {
+		port = "8080"
+		if f, err := os.Open(path.Join(dir, "conf", "app.conf")); err == nil {
+			defer f.Close()
+			s := bufio.NewScanner(f)
+			for s.Scan() {
+				if m := httpPortRegexp.FindStringSubmatch(s.Text()); m != nil {
+					port = m[1]
+					break
+				}
+			}
+		}
+	}
+	return &url.URL{Scheme: "http", Host: net.JoinHostPort("localhost", port)}, nil
+}
+
+// startProxy serves the proxy of the app in dir on addr.
+func startProxy(addr, dir string) error {
+	target, err := proxyTarget(dir)
+	if err != nil {
+		return err
+	}
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	if _, port, _ := net.SplitHostPort(l.Addr().String()); port == target.Port() {
+		l.Close()
+		return fmt.Errorf("the proxy can't listen on the port of the app, %s", target.Port())
+	}
+
+	runProxy = newDevProxy(target)
+	go http.Serve(l, runProxy)
+	ColorLog("[INFO] Proxying %s to %s\n", addr, target)
+	return nil
+}
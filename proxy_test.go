@@ -0,0 +1,168 @@
+package main
+
+import (
+	"bufio"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInjectScript(t *testing.T) {
+	script := string(liveReloadScript)
+	tests := []struct {
+		page     string
+		expected string
+	}{
+		{"<html><body><p>hi</p></body></html>", "<html><body><p>hi</p>" + script + "</body></html>"},
+		{"<HTML><BODY>hi</BODY></HTML>", "<HTML><BODY>hi" + script + "</BODY></HTML>"},
+		{"<p>fragment</p>", "<p>fragment</p>" + script},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.page, func(t *testing.T) {
+			assert.Equal(t, tt.expected, string(injectScript([]byte(tt.page))))
+		})
+	}
+}
+
+func TestInjectLiveReload(t *testing.T) {
+	tests := []struct {
+		name     string
+		method   string
+		status   int
+		injected bool
+	}{
+		{"get", http.MethodGet, http.StatusOK, true},
+		{"not found", http.MethodGet, http.StatusNotFound, true},
+		{"head", http.MethodHead, http.StatusOK, false},
+		{"no content", http.MethodGet, http.StatusNoContent, false},
+		{"not modified", http.MethodGet, http.StatusNotModified, false},
+		{"early hints", http.MethodGet, http.StatusEarlyHints, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &http.Response{
+				StatusCode: tt.status,
+				Header:     http.Header{"Content-Type": {"text/html"}},
+				Body:       io.NopCloser(strings.NewReader("<p>hi</p>")),
+				Request:    httptest.NewRequest(tt.method, "/", nil),
+			}
+			require.NoError(t, injectLiveReload(resp))
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			assert.Equal(t, tt.injected, strings.Contains(string(body), string(liveReloadScript)))
+		})
+	}
+}
+
+func TestDevProxy(t *testing.T) {
+	app := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api" {
+			w.Header().Set("Content-Type", "application/json")
+			io.WriteString(w, `{"ok": true}`)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		io.WriteString(w, "<html><body>home</body></html>")
+	}))
+	defer app.Close()
+	target, err := url.Parse(app.URL)
+	require.NoError(t, err)
+	p := newDevProxy(target)
+	srv := httptest.NewServer(p)
+	defer srv.Close()
+	get := func(path string) (*http.Response, string) {
+		resp, err := http.Get(srv.URL + path)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		b, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp, string(b)
+	}
+
+	// Requests are held until the app is ready.
+	done := make(chan string)
+	go func() {
+		_, body := get("/")
+		done <- body
+	}()
+	select {
+	case <-done:
+		t.Fatal("request not held during the build")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	events, err := http.Get(srv.URL + liveReloadPath)
+	require.NoError(t, err)
+	defer events.Body.Close()
+	reader := bufio.NewReader(events.Body)
+	line, err := reader.ReadString('\n')
+	require.NoError(t, err)
+	assert.Equal(t, ": connected\n", line)
+
+	p.ready()
+	body := <-done
+	assert.Contains(t, body, "home"+string(liveReloadScript)+"</body>")
+	reader.ReadString('\n')
+	line, err = reader.ReadString('\n')
+	require.NoError(t, err)
+	assert.Equal(t, "data: reload\n", line)
+
+	resp, body := get("/api")
+	assert.Equal(t, `{"ok": true}`, body)
+	assert.Equal(t, "12", resp.Header.Get("Content-Length"))
+
+	p.building()
+	p.failed("Build failed", "./main.go:12:2: undefined: <x>")
+	resp, body = get("/api")
+	assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+	assert.Contains(t, body, "<h1>Build failed</h1>")
+	assert.Contains(t, body, "<pre>./main.go:12:2: undefined: &lt;x&gt;</pre>")
+	assert.Contains(t, body, string(liveReloadScript))
+
+	app.Close()
+	p.ready()
+	resp, _ = get("/")
+	assert.Equal(t, http.StatusBadGateway, resp.StatusCode)
+}
+
+func TestProxyTarget(t *testing.T) {
+	health := conf.Run.Health
+	defer func() { conf.Run.Health = health }()
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "conf"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "conf", "app.conf"), []byte("appname = shop\nhttpport = 8081\n"), 0644))
+
+	tests := []struct {
+		health   healthCheck
+		expected string
+	}{
+		{healthCheck{}, "http://localhost:8081"},
+		{healthCheck{Port: 9000}, "http://localhost:9000"},
+		{healthCheck{URL: "http://127.0.0.1:9001/health"}, "http://127.0.0.1:9001"},
+		{healthCheck{URL: "https://shop.test/health"}, "https://shop.test:443"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expected, func(t *testing.T) {
+			conf.Run.Health = tt.health
+			target, err := proxyTarget(dir)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, target.String())
+		})
+	}
+
+	conf.Run.Health = healthCheck{}
+	assert.Error(t, startProxy(":8081", dir))
+	assert.Nil(t, runProxy)
+}
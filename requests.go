@@ -0,0 +1,227 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/zalora/bee/docgen"
+)
+
+// requestExporter writes the operations of a spec as the requests of an
+// HTTP client.
+type requestExporter interface {
+	// Export writes the requests to dir.
+	Export(spec *docgen.Spec, dir string) error
+}
+
+// requestFormats are the formats of bee generate requests.
+var requestFormats = []string{"postman", "http", "insomnia", "bruno"}
+
+func newRequestExporter(format string, opts postmanOptions, sync bool) (requestExporter, error) {
+	switch format {
+	case "postman":
+		return postmanExporter{opts: opts, sync: sync}, nil
+	case "http":
+		return httpExporter{opts: opts}, nil
+	case "insomnia":
+		return insomniaExporter{opts: opts}, nil
+	case "bruno":
+		return brunoExporter{opts: opts}, nil
+	}
+	return nil, fmt.Errorf("unknown format %q, use one of %s", format, strings.Join(requestFormats, ", "))
+}
+
+// generateRequests writes the requests of swagger/swagger.json in format.
+func generateRequests(curpath, format string, opts postmanOptions, sync bool) error {
+	exporter, err := newRequestExporter(format, opts, sync)
+	if err != nil {
+		return err
+	}
+	spec, err := docgen.LoadSpec(path.Join(curpath, "swagger", "swagger.json"))
+	if err != nil {
+		return err
+	}
+	return exporter.Export(spec, path.Join(curpath, "swagger"))
+}
+
+// exportRequest is an operation with sample values, shared by the exporters
+// other than Postman.
+type exportRequest struct {
+	ID          string
+	Name        string
+	Description string
+	Method      string
+	// Path is the path template, e.g. /v1/orders/{id}.
+	Path string
+	// PathParams have the sample values of the path parameters.
+	PathParams  []exportParam
+	Query       []exportParam
+	Headers     []exportParam
+	ContentType string
+	// Body is the JSON body of the request, Form its form parameters.
+	Body string
+	Form []exportParam
+}
+
+type exportParam struct {
+	Name  string
+	Value string
+	File  bool
+}
+
+// exportFolder is a folder of requests. Path has the names of the folder and
+// its parents, the folder of the requests at the root has none.
+type exportFolder struct {
+	Path     []string
+	Requests []exportRequest
+}
+
+// FileName returns the path of the folder usable as a file name, or name
+// for the root.
+func (f exportFolder) FileName(name string) string {
+	if len(f.Path) > 0 {
+		name = strings.Join(f.Path, "-")
+	}
+	return strings.Trim(fileNameRegexp.ReplaceAllString(name, "-"), "-")
+}
+
+// PathWith returns the path of r with its parameters written by variable,
+// e.g. :id for {id}.
+func (r exportRequest) PathWith(variable func(name string) string) string {
+	return docgen.PathParamRegexp.ReplaceAllStringFunc(r.Path, func(param string) string {
+		return variable(strings.Trim(param, "{}"))
+	})
+}
+
+// URL returns the URL of r with the base URL variable, the path parameters
+// written by variable and the escaped query.
+func (r exportRequest) URL(base string, variable func(name string) string) string {
+	u := base + r.PathWith(variable)
+	for i, q := range r.Query {
+		sep := "&"
+		if i == 0 {
+			sep = "?"
+		}
+		u += sep + url.QueryEscape(q.Name) + "=" + url.QueryEscape(q.Value)
+	}
+	return u
+}
+
+// templateVariable writes the path parameters as {{name}} variables.
+func templateVariable(name string) string {
+	return "{{" + name + "}}"
+}
+
+// FileName returns a name of r usable as a file name.
+func (r exportRequest) FileName() string {
+	name := r.ID
+	if name == "" {
+		name = r.Method + " " + r.Path
+	}
+	return strings.Trim(fileNameRegexp.ReplaceAllString(name, "-"), "-")
+}
+
+var fileNameRegexp = regexp.MustCompile(`[^A-Za-z0-9._]+`)
+
+// exportRequests returns the requests of the operations of spec, grouped by
+// the folders of the options in their order. The requests at the root come
+// last.
+func exportRequests(spec *docgen.Spec, opts postmanOptions) []exportFolder {
+	var folders []exportFolder
+	index := make(map[string]int)
+	for _, op := range spec.Operations() {
+		r := exportOperation(spec, op, opts)
+		for _, folder := range opts.folders(op) {
+			key := strings.Join(folder, "/")
+			i, ok := index[key]
+			if !ok {
+				i = len(folders)
+				index[key] = i
+				folders = append(folders, exportFolder{Path: folder})
+			}
+			folders[i].Requests = append(folders[i].Requests, r)
+		}
+	}
+
+	sort.SliceStable(folders, func(i, j int) bool {
+		a, b := folders[i].Path, folders[j].Path
+		if (len(a) == 0) != (len(b) == 0) {
+			return len(b) == 0
+		}
+		return opts.pathLess(a, b)
+	})
+	return folders
+}
+
+func exportOperation(spec *docgen.Spec, op docgen.Operation, opts postmanOptions) exportRequest {
+	r := exportRequest{
+		Name:   op.Method + " " + spec.BasePath() + op.Path,
+		Method: op.Method,
+		Path:   spec.BasePath() + op.Path,
+	}
+	r.ID, _ = op.Op["operationId"].(string)
+	r.Description, _ = op.Op["description"].(string)
+	if note := deprecationNote(op.Op); note != "" {
+		r.Name = "[DEPRECATED] " + r.Name
+		if r.Description != "" {
+			r.Description += "\n\n"
+		}
+		r.Description += note
+	}
+
+	for _, h := range opts.headers() {
+		r.Headers = append(r.Headers, exportParam{Name: h.Key, Value: h.Value})
+	}
+
+	hasFile := false
+	for _, param := range op.Parameters() {
+		name, _ := param["name"].(string)
+		value := postmanValue(spec.Example(param))
+		switch param["in"] {
+		case "path":
+			r.PathParams = append(r.PathParams, exportParam{Name: name, Value: value})
+		case "query":
+			r.Query = append(r.Query, exportParam{Name: name, Value: value})
+		case "header":
+			r.Headers = append(r.Headers, exportParam{Name: name, Value: value})
+		case "formData":
+			p := exportParam{Name: name, Value: value}
+			if param["type"] == "file" {
+				p = exportParam{Name: name, File: true}
+				hasFile = true
+			}
+			r.Form = append(r.Form, p)
+		case "body":
+			schema, _ := param["schema"].(map[string]interface{})
+			b, _ := json.MarshalIndent(spec.Example(schema), "", "  ")
+			r.Body = string(b)
+			r.ContentType = postmanContentType(spec.Consumes(op), "json", "application/json")
+		}
+	}
+	if len(r.Form) > 0 {
+		r.ContentType = postmanContentType(spec.Consumes(op), "form", "multipart/form-data")
+		if hasFile {
+			r.ContentType = "multipart/form-data"
+		}
+	}
+	if r.ContentType != "" {
+		r.Headers = append(r.Headers, exportParam{Name: "Content-Type", Value: r.ContentType})
+	}
+	return r
+}
+
+// postmanExporter writes swagger/postman-collection.json.
+type postmanExporter struct {
+	opts postmanOptions
+	// sync updates the existing collection instead of replacing it.
+	sync bool
+}
+
+func (e postmanExporter) Export(spec *docgen.Spec, dir string) error {
+	return writePostman(spec, dir, e.opts, e.sync)
+}
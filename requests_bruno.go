@@ -0,0 +1,177 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/zalora/bee/docgen"
+)
+
+// brunoExporter writes a Bruno collection to swagger/bruno: a folder per tag
+// with a .bru file per request, and the environments in
+// swagger/bruno/environments.
+type brunoExporter struct {
+	opts postmanOptions
+}
+
+func (e brunoExporter) Export(spec *docgen.Spec, dir string) error {
+	dir = path.Join(dir, "bruno")
+	name := e.opts.Name
+	if name == "" {
+		name = spec.Swagger.Infos.Title
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	b, err := json.MarshalIndent(map[string]string{"version": "1", "name": name, "type": "collection"}, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path.Join(dir, "bruno.json"), append(b, '\n'), 0644); err != nil {
+		return err
+	}
+
+	for _, folder := range exportRequests(spec, e.opts) {
+		fdir := dir
+		for _, name := range folder.Path {
+			fdir = path.Join(fdir, strings.Trim(fileNameRegexp.ReplaceAllString(name, "-"), "-"))
+		}
+		if err := os.MkdirAll(fdir, 0755); err != nil {
+			return err
+		}
+		for i, r := range folder.Requests {
+			fpath := path.Join(fdir, r.FileName()+".bru")
+			if err := os.WriteFile(fpath, []byte(e.request(r, i+1)), 0644); err != nil {
+				return err
+			}
+		}
+	}
+
+	if len(e.opts.Environments) == 0 {
+		return nil
+	}
+	if err := os.MkdirAll(path.Join(dir, "environments"), 0755); err != nil {
+		return err
+	}
+	for _, env := range e.opts.Environments {
+		vars := map[string]string{e.opts.variable("BASE_URL"): env.BaseURL}
+		for k, v := range env.Variables {
+			vars[e.opts.variable(k)] = v
+		}
+		var b strings.Builder
+		brunoBlock(&b, "vars", brunoPairs(vars))
+		fpath := path.Join(dir, "environments", env.Name+".bru")
+		if err := os.WriteFile(fpath, []byte(b.String()), 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// request returns the .bru file of r, seq is its position in the folder.
+func (e brunoExporter) request(r exportRequest, seq int) string {
+	var b strings.Builder
+	brunoBlock(&b, "meta", []string{
+		"name: " + r.Name,
+		"type: http",
+		fmt.Sprintf("seq: %d", seq),
+	})
+
+	body := "none"
+	switch {
+	case r.Body != "":
+		body = "json"
+	case len(r.Form) > 0 && strings.HasPrefix(r.ContentType, "multipart/"):
+		body = "multipartForm"
+	case len(r.Form) > 0:
+		body = "formUrlEncoded"
+	}
+	brunoBlock(&b, strings.ToLower(r.Method), []string{
+		"url: " + r.URL("{{"+e.opts.variable("BASE_URL")+"}}", func(name string) string { return ":" + name }),
+		"body: " + body,
+		"auth: none",
+	})
+
+	if len(r.PathParams) > 0 {
+		var lines []string
+		for _, p := range r.PathParams {
+			lines = append(lines, p.Name+": "+p.Value)
+		}
+		brunoBlock(&b, "params:path", lines)
+	}
+	if len(r.Query) > 0 {
+		var lines []string
+		for _, q := range r.Query {
+			// As in the URL, which Bruno keeps in sync with them.
+			lines = append(lines, url.QueryEscape(q.Name)+": "+url.QueryEscape(q.Value))
+		}
+		brunoBlock(&b, "params:query", lines)
+	}
+	if len(r.Headers) > 0 {
+		var lines []string
+		for _, h := range r.Headers {
+			lines = append(lines, h.Name+": "+h.Value)
+		}
+		brunoBlock(&b, "headers", lines)
+	}
+
+	switch body {
+	case "json":
+		brunoBlock(&b, "body:json", strings.Split(r.Body, "\n"))
+	case "multipartForm", "formUrlEncoded":
+		var lines []string
+		for _, p := range r.Form {
+			if p.File {
+				lines = append(lines, p.Name+": @file()")
+				continue
+			}
+			lines = append(lines, p.Name+": "+p.Value)
+		}
+		if body == "multipartForm" {
+			brunoBlock(&b, "body:multipart-form", lines)
+		} else {
+			brunoBlock(&b, "body:form-urlencoded", lines)
+		}
+	}
+
+	if r.Description != "" {
+		brunoBlock(&b, "docs", strings.Split(r.Description, "\n"))
+	}
+	return b.String()
+}
+
+// brunoBlock writes a block of lines indented by two spaces.
+func brunoBlock(b *strings.Builder, name string, lines []string) {
+	if b.Len() > 0 {
+		b.WriteString("\n")
+	}
+	b.WriteString(name + " {\n")
+	for _, line := range lines {
+		if line == "" {
+			b.WriteString("\n")
+			continue
+		}
+		b.WriteString("  " + line + "\n")
+	}
+	b.WriteString("}\n")
+}
+
+// brunoPairs returns the key: value lines of m sorted by key.
+func brunoPairs(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	lines := make([]string, 0, len(keys))
+	for _, k := range keys {
+		lines = append(lines, k+": "+m[k])
+	}
+	return lines
+}
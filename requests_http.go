@@ -0,0 +1,121 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/zalora/bee/docgen"
+)
+
+// httpExporter writes a request file per tag for the HTTP clients of the
+// JetBrains IDEs and VS Code to swagger/requests, with the environments in
+// http-client.env.json.
+type httpExporter struct {
+	opts postmanOptions
+}
+
+func (e httpExporter) Export(spec *docgen.Spec, dir string) error {
+	dir = path.Join(dir, "requests")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	for _, folder := range exportRequests(spec, e.opts) {
+		fpath := path.Join(dir, folder.FileName("requests")+".http")
+		if err := os.WriteFile(fpath, []byte(e.file(folder)), 0644); err != nil {
+			return err
+		}
+	}
+
+	if len(e.opts.Environments) == 0 {
+		return nil
+	}
+	envs := make(map[string]map[string]string)
+	for _, env := range e.opts.Environments {
+		vars := map[string]string{e.opts.variable("BASE_URL"): env.BaseURL}
+		for k, v := range env.Variables {
+			vars[e.opts.variable(k)] = v
+		}
+		envs[env.Name] = vars
+	}
+	b, err := json.MarshalIndent(envs, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path.Join(dir, "http-client.env.json"), append(b, '\n'), 0644)
+}
+
+// file returns the request file of folder. The path parameters are
+// declared in the file with a sample value, and so is the base URL without
+// environments.
+func (e httpExporter) file(folder exportFolder) string {
+	var b strings.Builder
+	base := e.opts.variable("BASE_URL")
+	if len(e.opts.Environments) == 0 {
+		fmt.Fprintf(&b, "@%s = http://localhost:8080\n", base)
+	}
+	declared := make(map[string]bool)
+	for _, r := range folder.Requests {
+		for _, p := range r.PathParams {
+			if !declared[p.Name] {
+				declared[p.Name] = true
+				fmt.Fprintf(&b, "@%s = %s\n", p.Name, p.Value)
+			}
+		}
+	}
+	if b.Len() > 0 {
+		b.WriteString("\n")
+	}
+
+	for i, r := range folder.Requests {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "### %s\n", r.Name)
+		if r.ID != "" {
+			fmt.Fprintf(&b, "# @name %s\n", r.FileName())
+		}
+		for _, line := range strings.Split(r.Description, "\n") {
+			if line != "" {
+				fmt.Fprintf(&b, "# %s\n", line)
+			}
+		}
+		fmt.Fprintf(&b, "%s %s\n", r.Method, r.URL("{{"+base+"}}", templateVariable))
+		for _, h := range r.Headers {
+			value := h.Value
+			if h.Name == "Content-Type" && strings.HasPrefix(value, "multipart/") {
+				value += "; boundary=" + httpBoundary
+			}
+			fmt.Fprintf(&b, "%s: %s\n", h.Name, value)
+		}
+
+		switch {
+		case r.Body != "":
+			fmt.Fprintf(&b, "\n%s\n", r.Body)
+		case len(r.Form) > 0 && strings.HasPrefix(r.ContentType, "multipart/"):
+			b.WriteString("\n")
+			for _, p := range r.Form {
+				fmt.Fprintf(&b, "--%s\n", httpBoundary)
+				if p.File {
+					fmt.Fprintf(&b, "Content-Disposition: form-data; name=%q; filename=%q\n\n< ./%s\n", p.Name, p.Name, p.Name)
+					continue
+				}
+				fmt.Fprintf(&b, "Content-Disposition: form-data; name=%q\n\n%s\n", p.Name, p.Value)
+			}
+			fmt.Fprintf(&b, "--%s--\n", httpBoundary)
+		case len(r.Form) > 0:
+			values := make([]string, 0, len(r.Form))
+			for _, p := range r.Form {
+				values = append(values, p.Name+"="+p.Value)
+			}
+			fmt.Fprintf(&b, "\n%s\n", strings.Join(values, "&"))
+		}
+	}
+	return b.String()
+}
+
+// httpBoundary separates the parts of multipart bodies.
+const httpBoundary = "BeeFormBoundary"
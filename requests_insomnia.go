@@ -0,0 +1,170 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/zalora/bee/docgen"
+)
+
+// insomniaExporter writes swagger/insomnia.json in the Insomnia v4 export
+// format.
+type insomniaExporter struct {
+	opts postmanOptions
+}
+
+type insomniaExport struct {
+	Type      string             `json:"_type"`
+	Format    int                `json:"__export_format"`
+	Source    string             `json:"__export_source"`
+	Resources []insomniaResource `json:"resources"`
+}
+
+// insomniaResource is a workspace, environment, folder or request.
+type insomniaResource struct {
+	ID          string            `json:"_id"`
+	Type        string            `json:"_type"`
+	ParentID    *string           `json:"parentId"`
+	Name        string            `json:"name"`
+	Description string            `json:"description,omitempty"`
+	Scope       string            `json:"scope,omitempty"`
+	Data        map[string]string `json:"data,omitempty"`
+	Method      string            `json:"method,omitempty"`
+	URL         string            `json:"url,omitempty"`
+	Body        *insomniaBody     `json:"body,omitempty"`
+	Parameters  []insomniaParam   `json:"parameters,omitempty"`
+	Headers     []insomniaParam   `json:"headers,omitempty"`
+	SortKey     int               `json:"metaSortKey,omitempty"`
+}
+
+type insomniaBody struct {
+	MimeType string          `json:"mimeType"`
+	Text     string          `json:"text,omitempty"`
+	Params   []insomniaParam `json:"params,omitempty"`
+}
+
+type insomniaParam struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+	Type  string `json:"type,omitempty"`
+}
+
+func (e insomniaExporter) Export(spec *docgen.Spec, dir string) error {
+	b, err := json.MarshalIndent(e.export(spec), "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path.Join(dir, "insomnia.json"), append(b, '\n'), 0644)
+}
+
+func (e insomniaExporter) export(spec *docgen.Spec) insomniaExport {
+	name := e.opts.Name
+	if name == "" {
+		name = spec.Swagger.Infos.Title
+	}
+	id := func(prefix string, parts ...string) string {
+		return prefix + "_" + strings.ReplaceAll(postmanID(append([]string{name}, parts...)...), "-", "")
+	}
+	parent := func(id string) *string { return &id }
+
+	workspace := id("wrk")
+	baseEnv := id("env")
+	baseData := make(map[string]string)
+	out := insomniaExport{
+		Type:   "export",
+		Format: 4,
+		Source: "bee",
+		Resources: []insomniaResource{
+			{ID: workspace, Type: "workspace", Name: name, Scope: "collection"},
+			{ID: baseEnv, Type: "environment", ParentID: parent(workspace), Name: "Base Environment", Data: baseData},
+		},
+	}
+	for _, env := range e.opts.Environments {
+		data := map[string]string{e.opts.variable("BASE_URL"): env.BaseURL}
+		for k, v := range env.Variables {
+			data[e.opts.variable(k)] = v
+		}
+		out.Resources = append(out.Resources, insomniaResource{
+			ID:       id("env", env.Name),
+			Type:     "environment",
+			ParentID: parent(baseEnv),
+			Name:     env.Name,
+			Data:     data,
+		})
+	}
+
+	base := insomniaVariables("{{" + e.opts.variable("BASE_URL") + "}}")
+	groups := make(map[string]bool)
+	for i, folder := range exportRequests(spec, e.opts) {
+		// Request groups are created with their parents.
+		parentID := workspace
+		for depth := range folder.Path {
+			key := strings.Join(folder.Path[:depth+1], "/")
+			groupID := id("fld", key)
+			if !groups[key] {
+				groups[key] = true
+				out.Resources = append(out.Resources, insomniaResource{
+					ID:       groupID,
+					Type:     "request_group",
+					ParentID: parent(parentID),
+					Name:     folder.Path[depth],
+					SortKey:  i,
+				})
+			}
+			parentID = groupID
+		}
+
+		for j, r := range folder.Requests {
+			res := insomniaResource{
+				ID:          id("req", parentID, r.Method, r.Path),
+				Type:        "request",
+				ParentID:    parent(parentID),
+				Name:        r.Name,
+				Description: r.Description,
+				Method:      r.Method,
+				URL:         base + r.PathWith(insomniaVariable),
+				SortKey:     j,
+			}
+			for _, p := range r.PathParams {
+				if _, ok := baseData[p.Name]; !ok {
+					baseData[p.Name] = p.Value
+				}
+			}
+			for _, q := range r.Query {
+				res.Parameters = append(res.Parameters, insomniaParam{Name: q.Name, Value: q.Value})
+			}
+			for _, h := range r.Headers {
+				res.Headers = append(res.Headers, insomniaParam{Name: h.Name, Value: insomniaVariables(h.Value)})
+			}
+			switch {
+			case r.Body != "":
+				res.Body = &insomniaBody{MimeType: r.ContentType, Text: r.Body}
+			case len(r.Form) > 0:
+				res.Body = &insomniaBody{MimeType: r.ContentType}
+				for _, p := range r.Form {
+					param := insomniaParam{Name: p.Name, Value: p.Value}
+					if p.File {
+						param.Type = "file"
+					}
+					res.Body.Params = append(res.Body.Params, param)
+				}
+			}
+			out.Resources = append(out.Resources, res)
+		}
+	}
+	return out
+}
+
+// insomniaVariable writes the path parameters as variables, given a sample
+// value in the base environment.
+func insomniaVariable(name string) string {
+	return "{{ _." + name + " }}"
+}
+
+// insomniaVariables rewrites the {{NAME}} variables to the {{ _.NAME }}
+// form of Insomnia.
+func insomniaVariables(s string) string {
+	return postmanVariableRegexp.ReplaceAllString(s, "{{ _.$1 }}")
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zalora/bee/docgen"
+)
+
+func requestsTestSpec(t *testing.T) *docgen.Spec {
+	spec := &docgen.Spec{}
+	require.NoError(t, json.Unmarshal([]byte(postmanTestSpec), &spec.Swagger))
+	require.NoError(t, json.Unmarshal([]byte(postmanTestSpec), &spec.Document))
+	return spec
+}
+
+func TestExportRequests(t *testing.T) {
+	folders := exportRequests(requestsTestSpec(t), postmanOptions{Folders: []string{"orders"}})
+
+	var paths [][]string
+	for _, f := range folders {
+		paths = append(paths, f.Path)
+	}
+	assert.Equal(t, [][]string{{"orders"}, {"carts"}, {"customers"}, nil}, paths)
+	assert.Equal(t, "orders", folders[0].FileName("requests"))
+	assert.Equal(t, "requests", folders[3].FileName("requests"))
+
+	put := folders[0].Requests[1]
+	assert.Equal(t, "OrderController.Put", put.ID)
+	assert.Equal(t, "PUT /v1/orders/{id}", put.Name)
+	assert.Equal(t, "/v1/orders/{id}", put.Path)
+	assert.Equal(t, []exportParam{{Name: "id", Value: "42"}}, put.PathParams)
+	assert.Equal(t, "{{BASE_URL}}/v1/orders/{{id}}?status=open&ids=1%2C2", put.URL("{{BASE_URL}}", templateVariable))
+	put.Query = []exportParam{{Name: "q", Value: "a b&c#d"}}
+	assert.Equal(t, "/v1/orders/:id?q=a+b%26c%23d", put.URL("", func(name string) string { return ":" + name }))
+	assert.Equal(t, []exportParam{
+		{Name: "Accept", Value: "application/json"},
+		{Name: "Content-Type", Value: "application/vnd.shop+json"},
+	}, put.Headers)
+	assert.Contains(t, put.Body, `"total": 1.5`)
+
+	note := folders[1].Requests[1]
+	assert.Equal(t, "application/x-www-form-urlencoded", note.ContentType)
+	assert.Equal(t, []exportParam{{Name: "note", Value: "gift"}}, note.Form)
+
+	folders = exportRequests(requestsTestSpec(t), postmanOptions{FolderBy: "path"})
+	paths = nil
+	for _, f := range folders {
+		paths = append(paths, f.Path)
+	}
+	assert.Equal(t, [][]string{{"carts"}, {"carts", "note"}, {"customers"}, {"health"}, {"orders"}}, paths)
+	assert.Equal(t, "carts-note", folders[1].FileName("requests"))
+
+	health := folders[3].Requests[0]
+	assert.Equal(t, "HealthController.Get", health.FileName())
+	assert.Equal(t, "GET-v1-health", exportRequest{Method: "GET", Path: "/v1/health"}.FileName())
+}
+
+func TestRequestExporters(t *testing.T) {
+	opts := postmanOptions{
+		Prefix:  "DOR",
+		Headers: []postmanHeader{{Key: "Content-Language", Value: "{{CONTENT_LANGUAGE}}"}},
+		Environments: []postmanEnvironment{
+			{Name: "local", BaseURL: "http://localhost:8080", Variables: map[string]string{"CONTENT_LANGUAGE": "en"}},
+		},
+	}
+	spec := requestsTestSpec(t)
+	read := func(dir string, elem ...string) string {
+		b, err := os.ReadFile(filepath.Join(append([]string{dir}, elem...)...))
+		require.NoError(t, err)
+		return string(b)
+	}
+
+	for _, format := range requestFormats {
+		exporter, err := newRequestExporter(format, opts, false)
+		require.NoError(t, err)
+		dir := t.TempDir()
+		require.NoError(t, exporter.Export(spec, dir), format)
+
+		switch format {
+		case "postman":
+			assert.Contains(t, read(dir, "postman-collection.json"), `"{{DOR_BASE_URL}}"`)
+			assert.Contains(t, read(dir, "local.postman_environment.json"), `"DOR_CONTENT_LANGUAGE"`)
+		case "http":
+			orders := read(dir, "requests", "orders.http")
+			assert.True(t, strings.HasPrefix(orders, "@id = 42\n\n###"), orders)
+			assert.Contains(t, orders, "### PUT /v1/orders/{id}\n# @name OrderController.Put\n"+
+				"PUT {{DOR_BASE_URL}}/v1/orders/{{id}}?status=open&ids=1%2C2\n"+
+				"Content-Language: {{DOR_CONTENT_LANGUAGE}}\n"+
+				"Content-Type: application/vnd.shop+json\n\n{\n")
+			assert.Contains(t, read(dir, "requests", "carts.http"), "Content-Type: application/x-www-form-urlencoded\n\nnote=gift\n")
+			assert.Contains(t, read(dir, "requests", "requests.http"), "GET {{DOR_BASE_URL}}/v1/health\n")
+			assert.JSONEq(t, `{"local": {"DOR_BASE_URL": "http://localhost:8080", "DOR_CONTENT_LANGUAGE": "en"}}`,
+				read(dir, "requests", "http-client.env.json"))
+		case "insomnia":
+			var export insomniaExport
+			require.NoError(t, json.Unmarshal([]byte(read(dir, "insomnia.json")), &export))
+			assert.Equal(t, 4, export.Format)
+			types := make(map[string]int)
+			for _, r := range export.Resources {
+				types[r.Type]++
+				if r.Name == "PUT /v1/orders/{id}" {
+					assert.Equal(t, "{{ _.DOR_BASE_URL }}/v1/orders/{{ _.id }}", r.URL)
+					assert.Equal(t, "{{ _.DOR_CONTENT_LANGUAGE }}", r.Headers[0].Value)
+					assert.Equal(t, []insomniaParam{{Name: "status", Value: "open"}, {Name: "ids", Value: "1,2"}}, r.Parameters)
+					assert.Equal(t, "application/vnd.shop+json", r.Body.MimeType)
+				}
+			}
+			assert.Contains(t, export.Resources[1].Data, "id")
+			assert.Equal(t, map[string]int{"workspace": 1, "environment": 2, "request_group": 3, "request": 6}, types)
+		case "bruno":
+			assert.Contains(t, read(dir, "bruno", "bruno.json"), `"type": "collection"`)
+			put := read(dir, "bruno", "orders", "OrderController.Put.bru")
+			assert.Contains(t, put, "meta {\n  name: PUT /v1/orders/{id}\n  type: http\n  seq: 2\n}\n")
+			assert.Contains(t, put, "put {\n  url: {{DOR_BASE_URL}}/v1/orders/:id?status=open&ids=1%2C2\n  body: json\n  auth: none\n}\n")
+			assert.Contains(t, put, "params:path {\n  id: 42\n}\n")
+			assert.Contains(t, put, "params:query {\n  status: open\n  ids: 1%2C2\n}\n")
+			assert.Contains(t, put, "body:json {\n  {\n")
+			assert.Contains(t, read(dir, "bruno", "carts", "CartController.Note.bru"), "body:form-urlencoded {\n  note: gift\n}\n")
+			assert.Equal(t, "vars {\n  DOR_BASE_URL: http://localhost:8080\n  DOR_CONTENT_LANGUAGE: en\n}\n",
+				read(dir, "bruno", "environments", "local.bru"))
+		}
+	}
+
+	_, err := newRequestExporter("soap", opts, false)
+	assert.Error(t, err)
+}
@@ -15,7 +15,6 @@
 package main
 
 import (
-	"io/ioutil"
 	"os"
 	path "path/filepath"
 	"runtime"
@@ -23,12 +22,19 @@ import (
 )
 
 var cmdRun = &Command{
-	UsageLine: "run [appname] [watchall] [-main=*.go] [-downdoc=true]  [-gendoc=true] [-vendor=true] [-e=folderToExclude]  [-tags=goBuildTags] [-runmode=BEEGO_RUNMODE]",
+	UsageLine: "run [appname] [watchall] [-main=*.go] [-downdoc=true]  [-gendoc=true] [-vendor=true] [-e=folderToExclude]  [-tags=goBuildTags] [-runmode=BEEGO_RUNMODE] [-proxy=:8080]",
 	Short:     "run the app and start a Web server for development",
 	Long: `
 Run command will supervise the file system of the beego project using inotify,
 it will recompile and restart the app after any modifications.
 
+With a processes section in the Beefile, or a Procfile, the processes are run
+instead, each rebuilt and restarted when the packages it is built from change.
+
+With -proxy=:8080, requests to that address are forwarded to the app and held
+while it is rebuilt. Build errors are shown as an HTML page, and HTML pages
+reload after each restart.
+
 `,
 }
 
@@ -50,6 +56,8 @@ var (
 	vendorWatch bool
 	// Current runmode
 	runmode string
+	// Address of the dev proxy in front of the app
+	proxyAddr string
 )
 
 func init() {
@@ -61,6 +69,7 @@ func init() {
 	cmdRun.Flag.BoolVar(&vendorWatch, "vendor", false, "Watch vendor folder")
 	cmdRun.Flag.StringVar(&buildTags, "tags", "", "Build tags (https://golang.org/pkg/go/build/)")
 	cmdRun.Flag.StringVar(&runmode, "runmode", "", "Set BEEGO_RUNMODE env variable.")
+	cmdRun.Flag.StringVar(&proxyAddr, "proxy", "", "Serve a proxy holding requests during builds and reloading pages, e.g. :8080")
 	exit = make(chan bool)
 }
 
@@ -96,11 +105,7 @@ func runApp(cmd *Command, args []string) int {
 		ColorLog("[ERRO] Fail to parse bee.json[ %s ]\n", err)
 	}
 
-	var paths []string
-	readAppDirectories(currpath, &paths)
-
-	// Because monitor files has some issues, we watch current directory
-	// and ignore non-go files.
+	paths := []string{currpath}
 	for _, p := range conf.DirStruct.Others {
 		paths = append(paths, strings.Replace(p, "$GOPATH", currpath, -1))
 	}
@@ -119,12 +124,32 @@ func runApp(cmd *Command, args []string) int {
 			}
 		}
 	}
-	if gendoc == "true" {
-		NewWatcher(paths, files, true)
-		Autobuild(files, true)
+	processes, err := loadProcesses(currpath)
+	if err != nil {
+		ColorLog("[ERRO] Fail to load the processes[ %s ]\n", err)
+		os.Exit(2)
+	}
+	if len(processes) > 0 {
+		if proxyAddr != "" || gendoc == "true" {
+			ColorLog("[WARN] -proxy and -gendoc are ignored with processes\n")
+		}
+		killOnExit()
+		runProcesses(currpath, processes, paths)
 	} else {
-		NewWatcher(paths, files, false)
-		Autobuild(files, false)
+		if proxyAddr != "" {
+			if err := startProxy(proxyAddr, currpath); err != nil {
+				ColorLog("[ERRO] Fail to start the proxy[ %s ]\n", err)
+				os.Exit(2)
+			}
+		}
+		killOnExit()
+		if gendoc == "true" {
+			NewWatcher(paths, files, true)
+			Autobuild(files, true)
+		} else {
+			NewWatcher(paths, files, false)
+			Autobuild(files, false)
+		}
 	}
 
 	for {
@@ -135,46 +160,6 @@ func runApp(cmd *Command, args []string) int {
 	}
 }
 
-func readAppDirectories(directory string, paths *[]string) {
-	fileInfos, err := ioutil.ReadDir(directory)
-	if err != nil {
-		return
-	}
-
-	useDirectory := false
-	for _, fileInfo := range fileInfos {
-		if strings.HasSuffix(fileInfo.Name(), "docs") {
-			continue
-		}
-		if strings.HasSuffix(fileInfo.Name(), "swagger") {
-			continue
-		}
-
-		if !vendorWatch && strings.HasSuffix(fileInfo.Name(), "vendor") {
-			continue
-		}
-
-		if isExcluded(path.Join(directory, fileInfo.Name())) {
-			continue
-		}
-
-		if fileInfo.IsDir() == true && fileInfo.Name()[0] != '.' {
-			readAppDirectories(directory+"/"+fileInfo.Name(), paths)
-			continue
-		}
-
-		if useDirectory == true {
-			continue
-		}
-
-		if path.Ext(fileInfo.Name()) == ".go" {
-			*paths = append(*paths, directory)
-			useDirectory = true
-		}
-	}
-	return
-}
-
 // If a file is excluded
 func isExcluded(filePath string) bool {
 	for _, p := range excludedPaths {
@@ -0,0 +1,242 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/zalora/bee/docgen"
+)
+
+// codeSample is an entry of the x-codeSamples of an operation.
+type codeSample struct {
+	Lang   string `json:"lang"`
+	Label  string `json:"label"`
+	Source string `json:"source"`
+}
+
+// snippetLanguages are the languages of the code samples, with the fence
+// of their markdown code blocks.
+var snippetLanguages = []struct {
+	lang, label, fence string
+	snippet            func(r exportRequest, url string) string
+}{
+	{"Shell", "cURL", "shell", curlSnippet},
+	{"Go", "Go", "go", goSnippet},
+	{"JavaScript", "JavaScript", "javascript", fetchSnippet},
+}
+
+// sampleRequest returns the request of op and its URL, with the base URL,
+// the header values and the path parameters of the first environment. The
+// variables are kept without one, so that the path parameters stay visible
+// placeholders like {{id}}.
+func sampleRequest(spec *docgen.Spec, op docgen.Operation, opts postmanOptions) (exportRequest, string) {
+	r := exportOperation(spec, op, opts)
+	base := "{{" + opts.variable("BASE_URL") + "}}"
+	if len(opts.Environments) == 0 {
+		return r, r.URL(base, templateVariable)
+	}
+
+	env := opts.Environments[0]
+	values := map[string]string{opts.variable("BASE_URL"): env.BaseURL}
+	for k, v := range env.Variables {
+		values[opts.variable(k)] = v
+	}
+	resolve := func(s string) string {
+		return postmanVariableRegexp.ReplaceAllStringFunc(s, func(v string) string {
+			if value, ok := values[strings.Trim(v, "{}")]; ok {
+				return value
+			}
+			return v
+		})
+	}
+	headers := make([]exportParam, len(r.Headers))
+	for i, h := range r.Headers {
+		headers[i] = exportParam{Name: h.Name, Value: resolve(h.Value)}
+	}
+	r.Headers = headers
+	// Path parameters are collection variables, without the prefix.
+	return r, r.URL(resolve(base), func(name string) string {
+		if value, ok := env.Variables[name]; ok {
+			return url.PathEscape(value)
+		}
+		return templateVariable(name)
+	})
+}
+
+// codeSamples returns the snippets calling op.
+func codeSamples(spec *docgen.Spec, op docgen.Operation, opts postmanOptions) []codeSample {
+	r, url := sampleRequest(spec, op, opts)
+	samples := make([]codeSample, 0, len(snippetLanguages))
+	for _, l := range snippetLanguages {
+		samples = append(samples, codeSample{Lang: l.lang, Label: l.label, Source: l.snippet(r, url)})
+	}
+	return samples
+}
+
+// addCodeSamples sets the x-codeSamples of the operations of spec.
+func addCodeSamples(spec *docgen.Spec, opts postmanOptions) {
+	for _, op := range spec.Operations() {
+		var samples []interface{}
+		for _, s := range codeSamples(spec, op, opts) {
+			samples = append(samples, map[string]interface{}{"lang": s.Lang, "label": s.Label, "source": s.Source})
+		}
+		op.Op["x-codeSamples"] = samples
+	}
+}
+
+// codeSamplesMarkdown returns the snippets of op as markdown code blocks.
+func codeSamplesMarkdown(spec *docgen.Spec, op docgen.Operation, opts postmanOptions) string {
+	var b strings.Builder
+	b.WriteString("## Examples\n")
+	for i, s := range codeSamples(spec, op, opts) {
+		fmt.Fprintf(&b, "\n### %s\n\n```%s\n%s\n```\n", s.Label, snippetLanguages[i].fence, s.Source)
+	}
+	return b.String()
+}
+
+// shellQuote quotes s for a POSIX shell.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+func curlSnippet(r exportRequest, url string) string {
+	lines := []string{"curl"}
+	if r.Method != "GET" {
+		lines[0] += " -X " + r.Method
+	}
+	lines[0] += " " + shellQuote(url)
+
+	multipart := strings.HasPrefix(r.ContentType, "multipart/")
+	for _, h := range r.Headers {
+		if multipart && h.Name == "Content-Type" {
+			// curl sets it with the boundary.
+			continue
+		}
+		lines = append(lines, "-H "+shellQuote(h.Name+": "+h.Value))
+	}
+	switch {
+	case r.Body != "":
+		lines = append(lines, "-d "+shellQuote(r.Body))
+	case multipart:
+		for _, p := range r.Form {
+			value := p.Value
+			if p.File {
+				value = "@" + p.Name
+			}
+			lines = append(lines, "-F "+shellQuote(p.Name+"="+value))
+		}
+	default:
+		for _, p := range r.Form {
+			lines = append(lines, "--data-urlencode "+shellQuote(p.Name+"="+p.Value))
+		}
+	}
+	return strings.Join(lines, " \\\n  ")
+}
+
+func goSnippet(r exportRequest, url string) string {
+	var b strings.Builder
+	multipart := strings.HasPrefix(r.ContentType, "multipart/")
+	body := "nil"
+	switch {
+	case r.Body != "":
+		fmt.Fprintf(&b, "body := strings.NewReader(%s)\n", goString(r.Body))
+		body = "body"
+	case multipart:
+		b.WriteString("var body bytes.Buffer\nw := multipart.NewWriter(&body)\n")
+		for _, p := range r.Form {
+			if p.File {
+				fmt.Fprintf(&b, "data, err := os.ReadFile(%q)\nif err != nil {\n\tlog.Fatal(err)\n}\n", p.Name)
+				fmt.Fprintf(&b, "part, err := w.CreateFormFile(%q, %q)\nif err != nil {\n\tlog.Fatal(err)\n}\npart.Write(data)\n", p.Name, p.Name)
+				continue
+			}
+			fmt.Fprintf(&b, "w.WriteField(%q, %q)\n", p.Name, p.Value)
+		}
+		b.WriteString("w.Close()\n")
+		body = "&body"
+	case len(r.Form) > 0:
+		b.WriteString("form := url.Values{}\n")
+		for _, p := range r.Form {
+			fmt.Fprintf(&b, "form.Set(%q, %q)\n", p.Name, p.Value)
+		}
+		b.WriteString("body := strings.NewReader(form.Encode())\n")
+		body = "body"
+	}
+	if b.Len() > 0 {
+		b.WriteString("\n")
+	}
+
+	fmt.Fprintf(&b, "req, err := http.NewRequest(%q, %q, %s)\nif err != nil {\n\tlog.Fatal(err)\n}\n", r.Method, url, body)
+	for _, h := range r.Headers {
+		if multipart && h.Name == "Content-Type" {
+			b.WriteString("req.Header.Set(\"Content-Type\", w.FormDataContentType())\n")
+			continue
+		}
+		fmt.Fprintf(&b, "req.Header.Set(%q, %q)\n", h.Name, h.Value)
+	}
+	b.WriteString("\nresp, err := http.DefaultClient.Do(req)\nif err != nil {\n\tlog.Fatal(err)\n}\ndefer resp.Body.Close()")
+	return b.String()
+}
+
+func fetchSnippet(r exportRequest, url string) string {
+	var b strings.Builder
+	multipart := strings.HasPrefix(r.ContentType, "multipart/")
+	if multipart {
+		b.WriteString("const form = new FormData();\n")
+		for _, p := range r.Form {
+			if p.File {
+				fmt.Fprintf(&b, "form.append(%s, fileInput.files[0]);\n", jsString(p.Name))
+				continue
+			}
+			fmt.Fprintf(&b, "form.append(%s, %s);\n", jsString(p.Name), jsString(p.Value))
+		}
+		b.WriteString("\n")
+	}
+
+	fmt.Fprintf(&b, "const response = await fetch(%s, {\n  method: %s,\n", jsString(url), jsString(r.Method))
+	var headers []string
+	for _, h := range r.Headers {
+		if multipart && h.Name == "Content-Type" {
+			// fetch sets it with the boundary.
+			continue
+		}
+		headers = append(headers, "    "+jsString(h.Name)+": "+jsString(h.Value))
+	}
+	if len(headers) > 0 {
+		b.WriteString("  headers: {\n" + strings.Join(headers, ",\n") + "\n  },\n")
+	}
+	switch {
+	case r.Body != "":
+		b.WriteString("  body: JSON.stringify(" + strings.ReplaceAll(r.Body, "\n", "\n  ") + "),\n")
+	case multipart:
+		b.WriteString("  body: form,\n")
+	case len(r.Form) > 0:
+		var fields []string
+		for _, p := range r.Form {
+			fields = append(fields, "    "+jsString(p.Name)+": "+jsString(p.Value))
+		}
+		b.WriteString("  body: new URLSearchParams({\n" + strings.Join(fields, ",\n") + "\n  }),\n")
+	}
+	b.WriteString("});")
+
+	for _, h := range r.Headers {
+		if h.Name == "Accept" && strings.Contains(h.Value, "json") {
+			b.WriteString("\nconst data = await response.json();")
+		}
+	}
+	return b.String()
+}
+
+// jsString quotes s as a JavaScript string literal.
+func jsString(s string) string {
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(s); err != nil {
+		return strconv.Quote(s)
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zalora/bee/docgen"
+)
+
+func TestCodeSamples(t *testing.T) {
+	opts := postmanOptions{
+		Prefix:  "DOR",
+		Headers: []postmanHeader{{Key: "Content-Language", Value: "{{CONTENT_LANGUAGE}}"}},
+		Environments: []postmanEnvironment{
+			{Name: "local", BaseURL: "http://localhost:8080", Variables: map[string]string{"CONTENT_LANGUAGE": "en", "id": "42"}},
+		},
+	}
+	spec := requestsTestSpec(t)
+	var put, note docgen.Operation
+	for _, op := range spec.Operations() {
+		switch op.Method + " " + op.Path {
+		case "PUT /orders/{id}":
+			put = op
+		case "POST /carts/{id}/note":
+			note = op
+		}
+	}
+
+	samples := codeSamples(spec, put, opts)
+	require.Len(t, samples, 3)
+	assert.Equal(t, "Shell", samples[0].Lang)
+	assert.Contains(t, samples[0].Source, "curl -X PUT 'http://localhost:8080/v1/orders/42?status=open&ids=1%2C2' \\\n  -H 'Content-Language: en' \\\n")
+	assert.Contains(t, samples[0].Source, "-d '{\n  \"id\": 0,")
+	assert.Contains(t, samples[1].Source, "body := strings.NewReader(`{\n")
+	assert.Contains(t, samples[1].Source, "req, err := http.NewRequest(\"PUT\", \"http://localhost:8080/v1/orders/42?status=open&ids=1%2C2\", body)\n")
+	assert.Contains(t, samples[1].Source, "req.Header.Set(\"Content-Type\", \"application/vnd.shop+json\")\n")
+	assert.Contains(t, samples[2].Source, "await fetch(\"http://localhost:8080/v1/orders/42?status=open&ids=1%2C2\", {\n  method: \"PUT\",\n")
+	assert.Contains(t, samples[2].Source, "  body: JSON.stringify({\n    \"id\": 0,")
+
+	// Path parameters missing from the environment stay placeholders.
+	delete(opts.Environments[0].Variables, "id")
+	samples = codeSamples(spec, put, opts)
+	assert.Contains(t, samples[0].Source, "curl -X PUT 'http://localhost:8080/v1/orders/{{id}}?status=open&ids=1%2C2'")
+
+	samples = codeSamples(spec, note, opts)
+	assert.Contains(t, samples[0].Source, "--data-urlencode 'note=gift'")
+	assert.Contains(t, samples[1].Source, "form.Set(\"note\", \"gift\")\n")
+	assert.Contains(t, samples[2].Source, "body: new URLSearchParams({\n    \"note\": \"gift\"\n  }),")
+
+	// The variables are kept without an environment.
+	samples = codeSamples(spec, note, postmanOptions{Headers: opts.Headers})
+	assert.Contains(t, samples[0].Source, "curl -X POST '{{BASE_URL}}/v1/carts/{{id}}/note'")
+	assert.Contains(t, samples[0].Source, "-H 'Content-Language: {{CONTENT_LANGUAGE}}'")
+}
+
+func TestCodeSamplesMultipart(t *testing.T) {
+	spec := &docgen.Spec{Document: map[string]interface{}{"basePath": "/v1"}}
+	op := docgen.Operation{Method: "POST", Path: "/avatars", Op: map[string]interface{}{
+		"consumes": []interface{}{"multipart/form-data"},
+		"parameters": []interface{}{
+			map[string]interface{}{"name": "file", "in": "formData", "type": "file"},
+			map[string]interface{}{"name": "name", "in": "formData", "type": "string", "default": "me"},
+		},
+	}}
+
+	samples := codeSamples(spec, op, postmanOptions{})
+	assert.Equal(t, "curl -X POST '{{BASE_URL}}/v1/avatars' \\\n  -H 'Accept: application/json' \\\n  -F 'file=@file' \\\n  -F 'name=me'", samples[0].Source)
+	assert.Contains(t, samples[1].Source, "w := multipart.NewWriter(&body)\n")
+	assert.Contains(t, samples[1].Source, "req.Header.Set(\"Content-Type\", w.FormDataContentType())\n")
+	assert.Contains(t, samples[2].Source, "form.append(\"file\", fileInput.files[0]);\n")
+	assert.NotContains(t, samples[2].Source, "Content-Type")
+	assert.Contains(t, samples[2].Source, "const data = await response.json();")
+}
+
+func TestAddCodeSamples(t *testing.T) {
+	spec := requestsTestSpec(t)
+	addCodeSamples(spec, postmanOptions{})
+	for _, op := range spec.Operations() {
+		samples, ok := op.Op["x-codeSamples"].([]interface{})
+		require.True(t, ok, op.Path)
+		assert.Len(t, samples, 3)
+		assert.Equal(t, "cURL", samples[0].(map[string]interface{})["label"])
+	}
+
+	c := postmanTestCollection(t, postmanOptions{CodeSamples: true})
+	assert.Contains(t, c.Items[0].Items[0].Description, "## Examples\n\n### cURL\n\n```shell\ncurl ")
+}
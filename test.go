@@ -62,10 +62,8 @@ func testApp(cmd *Command, args []string) int {
 	if err != nil {
 		ColorLog("[ERRO] Fail to parse bee.json[ %s ]\n", err)
 	}
-	var paths []string
-	readAppDirectories(crupath, &paths)
-
-	NewWatcher(paths, nil, false)
+	killOnExit()
+	NewWatcher([]string{crupath}, nil, false)
 	appname = args[0]
 	for {
 		select {
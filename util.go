@@ -55,6 +55,7 @@ const (
 	Yellow
 	Blue
 	Magenta
+	Cyan
 	//NRed      = uint8(31) // Normal
 	EndColor = "\033[0m"
 
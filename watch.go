@@ -17,15 +17,19 @@ package main
 import (
 	"bytes"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
+	"os/signal"
+	path "path/filepath"
 	"regexp"
 	"runtime"
 	"strings"
 	"sync"
+	"syscall"
 	"time"
 
-	"github.com/howeyc/fsnotify"
+	"github.com/fsnotify/fsnotify"
 )
 
 const (
@@ -33,23 +37,65 @@ const (
 )
 
 var (
-	cmd          *exec.Cmd
-	state        sync.Mutex
-	eventTime    = make(map[string]int64)
-	scheduleTime time.Time
+	cmd       *exec.Cmd
+	cmdDone   <-chan struct{} // closed when cmd exits
+	state     sync.Mutex
+	eventTime = make(map[string]int64)
 )
 
+// NewWatcher watches the directories under paths and rebuilds the app when
+// its files change. Directories created while it runs are watched too.
 func NewWatcher(paths []string, files []string, isgenerate bool) {
-	watcher, err := fsnotify.NewWatcher()
+	watchPaths(paths, func([]string) {
+		Autobuild(files, isgenerate)
+	})
+}
+
+// watchPaths watches the directories under paths and calls build with the
+// files and directories changed, once they stop changing for a second.
+func watchPaths(paths []string, build func(changed []string)) {
+	watcher, err := newAppWatcher()
 	if err != nil {
 		ColorLog("[ERRO] Fail to create new Watcher[ %s ]\n", err)
 		os.Exit(2)
 	}
 
+	var (
+		mu      sync.Mutex
+		changed []string
+		timer   *time.Timer
+	)
+	schedule := func(name string) {
+		mu.Lock()
+		defer mu.Unlock()
+		changed = append(changed, name)
+		// Wait 1s before autobuild util there is no file change.
+		if timer != nil {
+			timer.Stop()
+		}
+		timer = time.AfterFunc(time.Second, func() {
+			mu.Lock()
+			names := changed
+			changed = nil
+			mu.Unlock()
+			if len(names) > 0 {
+				build(names)
+			}
+		})
+	}
+
 	go func() {
 		for {
 			select {
-			case e := <-watcher.Event:
+			case e := <-watcher.Events:
+				if dir, changed := watcher.update(e); dir {
+					if changed {
+						ColorLog("[EVEN] %s\n", e)
+						schedule(e.Name)
+					}
+					continue
+				}
+
 				isbuild := true
 
 				// Skip ignored files
@@ -70,21 +116,9 @@ func NewWatcher(paths []string, files []string, isgenerate bool) {
 
 				if isbuild {
 					ColorLog("[EVEN] %s\n", e)
-					go func() {
-						// Wait 1s before autobuild util there is no file change.
-						scheduleTime = time.Now().Add(1 * time.Second)
-						for {
-							time.Sleep(scheduleTime.Sub(time.Now()))
-							if time.Now().After(scheduleTime) {
-								break
-							}
-							return
-						}
-
-						Autobuild(files, isgenerate)
-					}()
+					schedule(e.Name)
 				}
-			case err := <-watcher.Error:
+			case err := <-watcher.Errors:
 				ColorLog("[WARN] %s\n", err.Error()) // No need to exit here
 			}
 		}
@@ -92,14 +126,146 @@ func NewWatcher(paths []string, files []string, isgenerate bool) {
 
 	ColorLog("[INFO] Initializing watcher...\n")
 	for _, path := range paths {
-		ColorLog("[TRAC] Directory( %s )\n", path)
-		err = watcher.Watch(path)
-		if err != nil {
+		if _, err := watcher.add(path); err != nil {
 			ColorLog("[ERRO] Fail to watch directory[ %s ]\n", err)
 			os.Exit(2)
 		}
 	}
+}
+
+// appWatcher watches directory trees, following the directories created
+// and deleted in them.
+type appWatcher struct {
+	*fsnotify.Watcher
+
+	mu   sync.Mutex
+	dirs map[string]bool
+}
+
+func newAppWatcher() (*appWatcher, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	return &appWatcher{Watcher: watcher, dirs: make(map[string]bool)}, nil
+}
+
+// add watches root and the directories under it that are not excluded, even
+// those without files of a watched extension yet, so that the packages
+// created in them are seen. It reports whether there are such files.
+func (w *appWatcher) add(root string) (bool, error) {
+	root = path.Clean(root)
+	var dirs []string
+	found := false
+	err := path.Walk(root, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			if p == root {
+				return err
+			}
+			// Removed while walking.
+			return nil
+		}
+		if info.IsDir() {
+			if p != root && !isWatchedDir(p) {
+				return path.SkipDir
+			}
+			dirs = append(dirs, p)
+			return nil
+		}
+		if checkIfWatchExt(p) && !shouldIgnoreFile(p) {
+			found = true
+		}
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for _, d := range dirs {
+		if w.dirs[d] {
+			continue
+		}
+		if err := w.Add(d); err != nil {
+			if d == root {
+				return found, err
+			}
+			ColorLog("[WARN] Fail to watch directory[ %s ]\n", err)
+			continue
+		}
+		w.dirs[d] = true
+		Debugf("Watching directory %s", d)
+	}
+	return found, nil
+}
+
+// remove stops watching dir and the directories under it, and reports
+// whether it was watched.
+func (w *appWatcher) remove(dir string) bool {
+	dir = path.Clean(dir)
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	found := false
+	for d := range w.dirs {
+		if d == dir || strings.HasPrefix(d, dir+string(path.Separator)) {
+			// The watch of a deleted directory is already gone.
+			w.Remove(d)
+			delete(w.dirs, d)
+			found = true
+		}
+	}
+	return found
+}
+
+// update adds or removes the watches of the directory e is about. It
+// reports whether e is about a directory and whether the files of the app
+// changed with it.
+func (w *appWatcher) update(e fsnotify.Event) (dir, changed bool) {
+	if e.Op&(fsnotify.Remove|fsnotify.Rename) != 0 {
+		removed := w.remove(e.Name)
+		return removed, removed
+	}
+	if e.Op&fsnotify.Create == 0 {
+		return false, false
+	}
+
+	info, err := os.Stat(e.Name)
+	if err != nil || !info.IsDir() {
+		return false, false
+	}
+	if !isWatchedDir(e.Name) {
+		return true, false
+	}
+	// Files may have been written before the watch was added, and are
+	// likely to be in the directories of a new package.
+	found, err := w.add(e.Name)
+	if err != nil {
+		ColorLog("[WARN] Fail to watch directory[ %s ]\n", err)
+	}
+	return true, found
+}
 
+// dependencyDirs are the directories of the dependency trees of the
+// frontend tools, never watched.
+var dependencyDirs = []string{"node_modules", "bower_components", "jspm_packages"}
+
+// isWatchedDir reports whether the directory p is watched: hidden, docs,
+// swagger and dependency directories are not, nor those excluded with -e
+// and, unless -vendor is set, vendor.
+func isWatchedDir(p string) bool {
+	name := path.Base(p)
+	if name[0] == '.' || strings.HasSuffix(name, "docs") || strings.HasSuffix(name, "swagger") {
+		return false
+	}
+	if containsString(dependencyDirs, name) {
+		return false
+	}
+	if !vendorWatch && strings.HasSuffix(name, "vendor") {
+		return false
+	}
+	return !isExcluded(p)
 }
 
 // getFileModTime retuens unix timestamp of `os.File.ModTime` by given path.
@@ -126,6 +292,9 @@ func Autobuild(files []string, isgenerate bool) {
 	defer state.Unlock()
 
 	ColorLog("[INFO] Start building...\n")
+	runProxy.building()
+	// Shown by the proxy when the build fails.
+	var output bytes.Buffer
 
 	os.Chdir(currpath)
 
@@ -151,7 +320,7 @@ func Autobuild(files []string, isgenerate bool) {
 				}
 				icmd = exec.Command(cmdName, "install", pkg)
 				icmd.Stdout = os.Stdout
-				icmd.Stderr = os.Stderr
+				icmd.Stderr = io.MultiWriter(os.Stderr, &output)
 				icmd.Env = append(os.Environ(), "GOGC=off")
 				err = icmd.Run()
 				if err != nil {
@@ -186,30 +355,120 @@ func Autobuild(files []string, isgenerate bool) {
 		bcmd := exec.Command(cmdName, args...)
 		bcmd.Env = append(os.Environ(), "GOGC=off")
 		bcmd.Stdout = os.Stdout
-		bcmd.Stderr = os.Stderr
+		bcmd.Stderr = io.MultiWriter(os.Stderr, &output)
 		err = bcmd.Run()
 	}
 
 	if err != nil {
 		ColorLog("[ERRO] ============== Build failed ===================\n")
+		runProxy.failed("Build failed", output.String())
 		return
 	}
 	ColorLog("[SUCC] Build was successful\n")
 	Restart(appname)
 }
 
+// Kill stops the running app: it is sent the shutdown signal and given the
+// grace period to exit before its process group is killed.
 func Kill() {
 	defer func() {
 		if e := recover(); e != nil {
 			fmt.Println("Kill.recover -> ", e)
 		}
 	}()
-	if cmd != nil && cmd.Process != nil {
-		err := cmd.Process.Kill()
+	if cmd == nil || cmd.Process == nil {
+		return
+	}
+
+	sig, grace, err := shutdownOptions()
+	if err != nil {
+		ColorLog("[WARN] %s\n", err)
+	}
+	if !stopProcess(cmd.Process, cmdDone, sig, grace) {
+		ColorLog("[WARN] %s did not exit within %s, killed it\n", appname, grace)
+	}
+	cmd = nil
+}
+
+// stopProcess sends sig to p and waits for done to be closed, killing the
+// process group of p after grace. The processes p leaves behind are killed
+// either way. It reports whether p exited within grace.
+func stopProcess(p *os.Process, done <-chan struct{}, sig os.Signal, grace time.Duration) bool {
+	exited := true
+	if err := signalProcess(p, sig); err != nil {
+		Debugf("signal %s: %s", sig, err)
+	}
+	select {
+	case <-done:
+	case <-time.After(grace):
+		exited = false
+	}
+
+	if err := killProcessGroup(p); err != nil {
+		Debugf("kill: %s", err)
+	}
+	<-done
+	return exited
+}
+
+// shutdownOptions returns the signal stopping the app and its grace
+// period, the defaults when they are not valid.
+func shutdownOptions() (os.Signal, time.Duration, error) {
+	sig, grace := os.Signal(syscall.SIGTERM), 5*time.Second
+	switch strings.ToUpper(conf.Run.ShutdownSignal) {
+	case "", "SIGTERM", "TERM":
+	case "SIGINT", "INT":
+		sig = os.Interrupt
+	default:
+		return sig, grace, fmt.Errorf("unknown shutdown signal %q, using SIGTERM", conf.Run.ShutdownSignal)
+	}
+	if conf.Run.GracePeriod != "" {
+		d, err := time.ParseDuration(conf.Run.GracePeriod)
 		if err != nil {
-			fmt.Println("Kill -> ", err)
+			return sig, grace, fmt.Errorf("invalid grace period %q, using %s", conf.Run.GracePeriod, grace)
 		}
+		grace = d
 	}
+	return sig, grace, nil
+}
+
+// outputPipe returns the write end of a pipe copied to w, to be given as the
+// output of a command and closed once it is started. exec.Cmd.Wait doesn't
+// wait for the pipe as it does for the copy of other writers, which the
+// processes left behind by the command would hold up. closeOutput waits a
+// second at most for the rest of the output after the command exited.
+func outputPipe(w io.Writer) (pipe *os.File, closeOutput func(), err error) {
+	r, pipe, err := os.Pipe()
+	if err != nil {
+		return nil, nil, err
+	}
+	copied := make(chan struct{})
+	go func() {
+		io.Copy(w, r)
+		close(copied)
+	}()
+	return pipe, func() {
+		select {
+		case <-copied:
+		case <-time.After(time.Second):
+		}
+		r.Close()
+		<-copied
+	}, nil
+}
+
+// killOnExit stops the app when bee is interrupted, as it doesn't get the
+// signals of the terminal in its own process group.
+func killOnExit() {
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-c
+		state.Lock()
+		Kill()
+		stopProcesses()
+		os.Exit(0)
+	}()
 }
 
 func Restart(appname string) {
@@ -226,14 +485,51 @@ func Start(appname string) {
 
 	formatSourceCode(routersPackage)
 
-	cmd = exec.Command(appname)
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	cmd.Args = append([]string{appname}, conf.CmdArgs...)
-	cmd.Env = append(os.Environ(), conf.Envs...)
+	c := exec.Command(appname)
+	c.Stdout = os.Stdout
+	c.Stderr = os.Stderr
+	stderr := &tailWriter{max: 10}
+	var pipe *os.File
+	closeStderr := func() {}
+	if appHealth().enabled() {
+		var err error
+		if pipe, closeStderr, err = outputPipe(io.MultiWriter(os.Stderr, stderr)); err != nil {
+			ColorLog("[ERRO] Fail to start %s[ %s ]\n", appname, err)
+			runProxy.failed("The app failed to start", err.Error())
+			return
+		}
+		c.Stderr = pipe
+	}
+	c.Args = append([]string{appname}, conf.CmdArgs...)
+	c.Env = append(os.Environ(), conf.Envs...)
+	setProcessGroup(c)
+
+	// Not while bee exits, see killOnExit.
+	state.Lock()
+	err := c.Start()
+	if pipe != nil {
+		pipe.Close()
+	}
+	if err != nil {
+		state.Unlock()
+		closeStderr()
+		ColorLog("[ERRO] Fail to start %s[ %s ]\n", appname, err)
+		runProxy.failed("The app failed to start", err.Error())
+		return
+	}
+	done := make(chan struct{})
+	go func() {
+		c.Wait()
+		closeStderr()
+		close(done)
+	}()
+	cmd, cmdDone = c, done
+	state.Unlock()
 
-	go cmd.Run()
 	ColorLog("[INFO] %s is running...\n", appname)
+	if appHealth().enabled() {
+		checkHealth(appname, c, done, stderr)
+	}
 	started <- true
 }
 
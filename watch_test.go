@@ -0,0 +1,145 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAppWatcher(t *testing.T) {
+	root := t.TempDir()
+	for _, dir := range []string{"controllers", "docs", "swagger", "vendor/github.com/acme", ".git", "static/js", "node_modules/acme", "models/orders"} {
+		require.NoError(t, os.MkdirAll(filepath.Join(root, dir), 0755))
+	}
+	for _, file := range []string{"controllers/orders.go", "models/orders/order.go", "static/js/app.js", "node_modules/acme/acme.go"} {
+		require.NoError(t, os.WriteFile(filepath.Join(root, file), []byte("package acme\n"), 0644))
+	}
+
+	w, err := newAppWatcher()
+	require.NoError(t, err)
+	defer w.Close()
+	found, err := w.add(root)
+	require.NoError(t, err)
+	assert.True(t, found)
+
+	watched := func() []string {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		var dirs []string
+		for d := range w.dirs {
+			rel, _ := filepath.Rel(root, d)
+			dirs = append(dirs, filepath.ToSlash(rel))
+		}
+		return dirs
+	}
+	// Directories without Go files are watched too, the excluded ones are not.
+	assert.ElementsMatch(t, []string{".", "controllers", "models", "models/orders", "static", "static/js"}, watched())
+
+	go func() {
+		for e := range w.Events {
+			w.update(e)
+		}
+	}()
+	isWatched := func(dir string) func() bool {
+		return func() bool {
+			return containsString(watched(), dir)
+		}
+	}
+
+	// New packages are watched, with the files written before their watch.
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "services", "billing"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "services", "billing", "billing.go"), []byte("package billing\n"), 0644))
+	require.Eventually(t, isWatched("services/billing"), 5*time.Second, 10*time.Millisecond)
+	assert.Contains(t, watched(), "services")
+
+	// So are the packages created in existing directories without Go files.
+	require.NoError(t, os.Mkdir(filepath.Join(root, "static", "js", "lib"), 0755))
+	require.Eventually(t, isWatched("static/js/lib"), 5*time.Second, 10*time.Millisecond)
+
+	// Excluded directories are not.
+	require.NoError(t, os.Mkdir(filepath.Join(root, "docs", "v2"), 0755))
+	require.NoError(t, os.Mkdir(filepath.Join(root, "node_docs"), 0755))
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "jobs", "node_modules"), 0755))
+	require.Eventually(t, isWatched("jobs"), 5*time.Second, 10*time.Millisecond)
+	assert.NotContains(t, watched(), "node_docs")
+	assert.NotContains(t, watched(), "docs/v2")
+	assert.NotContains(t, watched(), "jobs/node_modules")
+
+	// Deleted directories are dropped and watched again when re-created.
+	require.NoError(t, os.RemoveAll(filepath.Join(root, "controllers")))
+	require.Eventually(t, func() bool {
+		return !isWatched("controllers")()
+	}, 5*time.Second, 10*time.Millisecond)
+	require.NoError(t, os.Mkdir(filepath.Join(root, "controllers"), 0755))
+	require.Eventually(t, isWatched("controllers"), 5*time.Second, 10*time.Millisecond)
+}
+
+func TestIsWatchedDir(t *testing.T) {
+	defer func(p strFlags, v bool) { excludedPaths, vendorWatch = p, v }(excludedPaths, vendorWatch)
+	excludedPaths = strFlags{"/app/tmp"}
+
+	assert.True(t, isWatchedDir("/app/controllers"))
+	assert.False(t, isWatchedDir("/app/.git"))
+	assert.False(t, isWatchedDir("/app/docs"))
+	assert.False(t, isWatchedDir("/app/swagger"))
+	assert.False(t, isWatchedDir("/app/vendor"))
+	assert.False(t, isWatchedDir("/app/web/node_modules"))
+	assert.False(t, isWatchedDir("/app/tmp/cache"))
+
+	vendorWatch = true
+	assert.True(t, isWatchedDir("/app/vendor"))
+}
+
+func TestShutdownOptions(t *testing.T) {
+	run := conf.Run
+	defer func() { conf.Run = run }()
+
+	tests := []struct {
+		signal   string
+		grace    string
+		expected os.Signal
+		duration time.Duration
+		err      bool
+	}{
+		{"", "", syscall.SIGTERM, 5 * time.Second, false},
+		{"SIGINT", "10s", os.Interrupt, 10 * time.Second, false},
+		{"term", "500ms", syscall.SIGTERM, 500 * time.Millisecond, false},
+		{"SIGHUP", "", syscall.SIGTERM, 5 * time.Second, true},
+		{"", "soon", syscall.SIGTERM, 5 * time.Second, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.signal+tt.grace, func(t *testing.T) {
+			conf.Run.ShutdownSignal, conf.Run.GracePeriod = tt.signal, tt.grace
+			sig, grace, err := shutdownOptions()
+			assert.Equal(t, tt.expected, sig)
+			assert.Equal(t, tt.duration, grace)
+			assert.Equal(t, tt.err, err != nil)
+		})
+	}
+}
+
+func TestOutputPipe(t *testing.T) {
+	var out bytes.Buffer
+	pipe, closeOutput, err := outputPipe(&out)
+	require.NoError(t, err)
+
+	// The sleep left behind keeps the pipe open.
+	c := exec.Command("sh", "-c", "echo started >&2; sleep 10 &")
+	c.Stderr = pipe
+	require.NoError(t, c.Start())
+	pipe.Close()
+
+	start := time.Now()
+	require.NoError(t, c.Wait())
+	closeOutput()
+	assert.Less(t, time.Since(start), 5*time.Second)
+	assert.Equal(t, "started\n", out.String())
+}